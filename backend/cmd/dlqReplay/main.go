@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"wbL0/internal/config"
+	"wbL0/internal/kafka/dlq"
+	"wbL0/internal/lib/logger"
+)
+
+func main() {
+	idleTimeout := flag.Duration("idle-timeout", 10*time.Second, "stop when no dead-letter message arrives within this period")
+	limit := flag.Int("limit", 0, "maximum number of messages to replay, 0 means all")
+
+	cfg := config.MustLoad()
+	log := logger.SetupLogger(cfg.App.Level)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	log.Info("replaying dead-letter topic", "from", cfg.Kafka.DeadLetterTopic, "to", cfg.Kafka.Topic)
+	replayed, err := dlq.Replay(ctx, cfg, log, *idleTimeout, *limit)
+	if err != nil {
+		log.Error("dead-letter replay failed", "replayed", replayed, "err", err)
+		os.Exit(1)
+	}
+	log.Info("dead-letter replay finished", "replayed", replayed)
+}
@@ -6,8 +6,10 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/segmentio/kafka-go"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,53 +17,136 @@ import (
 	"syscall"
 	"time"
 	_ "wbL0/docs"
+	"wbL0/internal/auth"
 	"wbL0/internal/config"
 	"wbL0/internal/db/postgres"
 	redisClient "wbL0/internal/db/redis"
+	"wbL0/internal/encryption"
+	"wbL0/internal/health"
+	"wbL0/internal/http/handler/healthHandler"
 	"wbL0/internal/http/handler/orderHandler"
 	"wbL0/internal/http/middleware"
 	"wbL0/internal/http/routes"
 	"wbL0/internal/kafka/consumer"
+	"wbL0/internal/kafka/outbox"
+	"wbL0/internal/lib/breaker"
 	"wbL0/internal/lib/logger"
 	"wbL0/internal/metrics"
+	"wbL0/internal/redact"
 	orderRepoPostgres2 "wbL0/internal/repository/postgres/orderRepoPostgres"
 	orderRepoRedis2 "wbL0/internal/repository/redis/orderRepoRedis"
+	"wbL0/internal/retention"
 	"wbL0/internal/service/orderService"
+	"wbL0/internal/tracing"
 )
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT as "Bearer <token>"
 func main() {
 	cfg := config.MustLoad()
 
 	initContext := context.Background()
 
-	dbPool := postgres.MustLoad(initContext, cfg)
-	rdb := redisClient.NewRedisClient(initContext, cfg)
-
 	log := logger.SetupLogger(cfg.App.Level)
 
-	orderRepoPostgres := orderRepoPostgres2.NewPostgresRepository(dbPool, log)
-	orderRepoRedis := orderRepoRedis2.NewRedisRepo(rdb, log)
+	dbPool := postgres.MustLoad(initContext, cfg, log)
+	rdb, err := redisClient.Connect(initContext, cfg, log)
+	if err != nil {
+		if cfg.Redis.Required {
+			log.Error("Failed to connect to Redis", "error", err)
+			os.Exit(1)
+		}
+		log.Warn("Redis is unavailable, serving orders from Postgres until it is back", "error", err)
+	}
 
-	orderService := orderService.NewOrderService(orderRepoPostgres, orderRepoRedis, log, cfg.Redis.TTL)
+	redaction, err := redact.NewPolicy(cfg.Redaction)
+	if err != nil {
+		log.Error("Failed to configure redaction", "error", err)
+		os.Exit(1)
+	}
+	log = tracing.Logger(redaction.Logger(log))
 
-	orderHandler := orderHandler.NewOrderHandler(orderService, log)
+	shutdownTracing, err := tracing.Init(initContext, cfg.Tracing)
+	if err != nil {
+		log.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	keys, err := encryption.New(cfg.Encryption)
+	if err != nil {
+		log.Error("Failed to load encryption keys", "error", err)
+		os.Exit(1)
+	}
+	if keys == nil {
+		log.Warn("PII encryption at rest is disabled")
+	}
+
+	orderRepoPostgres := orderRepoPostgres2.NewPostgresRepository(dbPool, log).WithEncryption(keys)
+	pgBreaker := newBreaker("postgres", cfg.Database.Breaker, log)
+	redisBreaker := newBreaker("redis", cfg.Redis.Breaker, log)
+	orderRepoRedis := orderRepoRedis2.WithBreaker(orderRepoRedis2.NewRedisRepo(rdb, log).WithEncryption(keys), redisBreaker)
+	archiveRepoPostgres := orderRepoPostgres2.WithArchiveBreaker(orderRepoPostgres, pgBreaker)
+
+	warmupOpts := orderService.WarmupOptions{
+		MaxOrders: cfg.Cache.WarmupOrders,
+		MaxAge:    cfg.Cache.WarmupMaxAge,
+		BatchSize: cfg.Cache.WarmupBatchSize,
+	}
+	orderService := orderService.NewOrderService(orderRepoPostgres2.WithBreaker(orderRepoPostgres, pgBreaker), orderRepoRedis, log, cfg.Redis.TTL).
+		WithLocalCache(cfg.Cache.LocalSize, cfg.Cache.LocalTTL).
+		WithNegativeCache(cfg.Redis.NotFoundTTL).
+		WithArchive(archiveRepoPostgres, cfg.Retention.ArchiveFallback)
+	if cfg.Outbox.Topic != "" {
+		orderService.WithOutbox()
+	}
+
+	orderHandler := orderHandler.NewOrderHandler(orderService, log).WithRedaction(redaction)
+
+	authn, err := auth.New(cfg.Auth)
+	if err != nil {
+		log.Error("Failed to configure auth", "error", err)
+		os.Exit(1)
+	}
+	if authn == nil {
+		log.Warn("HTTP API authentication is disabled")
+	}
+
+	kafkaClient := &kafka.Client{Addr: kafka.TCP(cfg.Kafka.Brokers...), Timeout: cfg.Health.CheckTimeout}
+	readiness := health.New(cfg.Health.CheckTimeout).
+		WithCheck("postgres", health.Postgres(dbPool)).
+		WithCheck("kafka", health.Kafka(kafkaClient, cfg.Kafka.Topic, cfg.Kafka.GroupID, cfg.Health.KafkaMaxLag))
+	if cfg.Redis.Required {
+		readiness.WithCheck("redis", health.Redis(rdb))
+	} else {
+		readiness.WithOptionalCheck("redis", health.Redis(rdb))
+	}
+	healthHandler := healthHandler.NewHealthHandler(readiness, log)
 
 	metrics.Init()
 
 	r := gin.Default()
+	r.Use(middleware.TracingMiddleware())
 	r.Use(metrics.MetricsMiddleware())
 	r.Use(middleware.TimeoutMiddleware(cfg.Server.Timeout))
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3001"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", auth.APIKeyHeader},
 		AllowCredentials: true,
 	}))
 
-	routes.InitRoutes(r, *orderHandler)
+	routes.InitRoutes(r, *orderHandler, authn, log)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
@@ -82,6 +167,53 @@ func main() {
 		}
 	}()
 
+	if cfg.Kafka.StatusTopic != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := consumer.ConsumeStatusEvents(ctx, cfg, orderService, log); err != nil {
+				log.Error("Kafka status consumer failed", "error", err)
+			}
+		}()
+	}
+
+	if relay := outbox.NewRelay(cfg, orderRepoPostgres, log); relay != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			relay.Run(ctx)
+			if err := relay.Close(); err != nil {
+				log.Error("Failed to close outbox relay", "error", err)
+			}
+		}()
+	}
+
+	if archiver := retention.NewArchiver(cfg.Retention, archiveRepoPostgres, orderRepoRedis, log); archiver != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			archiver.Run(ctx)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		orderService.RunEvictions(ctx, cfg.Cache.EvictionInterval)
+	}()
+
+	if cfg.Cache.WarmupEnabled {
+		wg.Add(1)
+		warmupDone := readiness.WarmupStarted()
+		go func() {
+			defer wg.Done()
+			defer warmupDone()
+			if err := orderService.RestoreCacheFromDB(ctx, warmupOpts); err != nil {
+				log.Error("Cache warm-up failed", "error", err)
+			}
+		}()
+	}
+
 	go func() {
 		defer wg.Done()
 		log.Info("Listening and serving HTTP on ", "addr", srv.Addr)
@@ -96,20 +228,42 @@ func main() {
 	<-quit
 	log.Info("Shutdown Server ...")
 
-	cancel()
-	wg.Wait()
+	readiness.Drain()
+	time.Sleep(cfg.Health.DrainDelay)
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
 
+	// the HTTP goroutine is in wg and returns only once the server is shut down
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Error("Server forced to shutdown", "error", err)
 	} else {
 		log.Info("Server exiting with graceful shutdown")
 	}
 
+	cancel()
+	wg.Wait()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error("Failed to flush traces", "error", err)
+	}
+
 	dbPool.Close()
 	if err := rdb.Close(); err != nil {
 		log.Error("Failed to close Redis connection", "error", err)
 	}
 }
+
+// newBreaker creates a circuit breaker that logs its state changes and exports them to Prometheus.
+func newBreaker(name string, cfg config.BreakerConfig, log *slog.Logger) *breaker.Breaker {
+	metrics.BreakerState.WithLabelValues(name).Set(float64(breaker.StateClosed))
+	return breaker.New(name, breaker.Settings{
+		FailureThreshold: cfg.FailureThreshold,
+		OpenTimeout:      cfg.OpenTimeout,
+		HalfOpenCalls:    cfg.HalfOpenCalls,
+	}).OnStateChange(func(name string, from, to breaker.State) {
+		log.Warn("Circuit breaker state changed", "breaker", name, "from", from.String(), "to", to.String())
+		metrics.BreakerState.WithLabelValues(name).Set(float64(to))
+		metrics.BreakerTransitions.WithLabelValues(name, to.String()).Inc()
+	})
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"wbL0/internal/config"
+	"wbL0/internal/kafka/producer"
+	"wbL0/internal/lib/logger"
+	"wbL0/internal/lib/ordergen"
+	"wbL0/internal/tracing"
+)
+
+const duplicatesWindow = 1000
+
+type options struct {
+	fixtures     string
+	rate         float64
+	count        int
+	duration     time.Duration
+	invalidShare float64
+	dupShare     float64
+	report       time.Duration
+	seed         int64
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.fixtures, "fixtures", "", "file with orders to replay: JSON array, single object or one JSON per line")
+	flag.Float64Var(&opts.rate, "rate", 100, "target messages per second for generated orders, 0 means unlimited")
+	flag.IntVar(&opts.count, "count", 1000, "number of generated messages, 0 means until -duration or interrupt")
+	flag.DurationVar(&opts.duration, "duration", 0, "stop generating after this period, 0 means no limit")
+	flag.Float64Var(&opts.invalidShare, "invalid", 0, "share of invalid messages, from 0 to 1")
+	flag.Float64Var(&opts.dupShare, "duplicate", 0, "share of duplicated messages, from 0 to 1")
+	flag.DurationVar(&opts.report, "report", 5*time.Second, "throughput report interval")
+	flag.Int64Var(&opts.seed, "seed", time.Now().UnixNano(), "random seed")
+
+	cfg := config.MustLoad()
+	log := logger.SetupLogger(cfg.App.Level)
+
+	// flags are parsed by config.MustLoad
+	if opts.report <= 0 {
+		log.Error("-report must be positive", "report", opts.report.String())
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		log.Error("failed to set up tracing", "err", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Warn("failed to flush traces", "err", err)
+		}
+	}()
+
+	p := producer.New(cfg, log)
+	start := time.Now()
+
+	stopReport := make(chan struct{})
+	go reportLoop(log, p, start, opts.report, stopReport)
+
+	if opts.fixtures != "" {
+		err = replayFixtures(ctx, p, opts.fixtures)
+	} else {
+		err = generate(ctx, p, opts)
+	}
+	if closeErr := p.Close(); closeErr != nil {
+		log.Warn("failed to flush producer", "err", closeErr)
+	}
+	close(stopReport)
+
+	sent, failed := p.Stats()
+	elapsed := time.Since(start)
+	log.Info("producer finished", "sent", sent, "failed", failed, "elapsed", elapsed.String(),
+		"rate_per_sec", fmt.Sprintf("%.1f", float64(sent)/elapsed.Seconds()))
+	if err != nil && ctx.Err() == nil {
+		log.Error("producer failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func generate(ctx context.Context, p *producer.Producer, opts options) error {
+	gen := ordergen.New(opts.seed)
+	rng := rand.New(rand.NewSource(opts.seed))
+	recent := make([][]byte, 0, duplicatesWindow)
+	recentKeys := make([][]byte, 0, duplicatesWindow)
+
+	if opts.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.duration)
+		defer cancel()
+	}
+
+	var interval time.Duration
+	if opts.rate > 0 {
+		interval = time.Duration(float64(time.Second) / opts.rate)
+	}
+	next := time.Now()
+
+	for i := 0; opts.count == 0 || i < opts.count; i++ {
+		if interval > 0 {
+			next = next.Add(interval)
+			if wait := time.Until(next); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		var key, value []byte
+		roll := rng.Float64()
+		switch {
+		case roll < opts.dupShare && len(recent) > 0:
+			j := rng.Intn(len(recent))
+			key, value = recentKeys[j], recent[j]
+		case roll < opts.dupShare+opts.invalidShare:
+			key, value = invalidPayload(gen, rng)
+		default:
+			fo := gen.Valid()
+			data, err := json.Marshal(fo)
+			if err != nil {
+				return err
+			}
+			key, value = []byte(fo.Order.OrderUID), data
+			if len(recent) < duplicatesWindow {
+				recent = append(recent, value)
+				recentKeys = append(recentKeys, key)
+			} else {
+				j := rng.Intn(duplicatesWindow)
+				recent[j], recentKeys[j] = value, key
+			}
+		}
+
+		if err := p.Send(ctx, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// invalidPayload returns either a payload that fails validation or one that is not valid JSON at all.
+func invalidPayload(gen *ordergen.Generator, rng *rand.Rand) ([]byte, []byte) {
+	fo := gen.Invalid()
+	data, _ := json.Marshal(fo)
+	if rng.Intn(3) == 0 {
+		data = data[:len(data)/2]
+	}
+	return []byte(fo.Order.OrderUID), data
+}
+
+func replayFixtures(ctx context.Context, p *producer.Producer, path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var payloads []json.RawMessage
+	trimmed := bytes.TrimSpace(raw)
+	switch {
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		if err := json.Unmarshal(trimmed, &payloads); err != nil {
+			return fmt.Errorf("failed to parse fixtures array: %w", err)
+		}
+	default:
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) > 0 {
+				payloads = append(payloads, append(json.RawMessage(nil), line...))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		if len(payloads) > 1 && !json.Valid(payloads[0]) {
+			payloads = []json.RawMessage{trimmed}
+		}
+	}
+
+	for _, payload := range payloads {
+		var keyed struct {
+			Order struct {
+				OrderUID string `json:"order_uid"`
+			} `json:"order"`
+		}
+		_ = json.Unmarshal(payload, &keyed)
+		if err := p.Send(ctx, []byte(keyed.Order.OrderUID), payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reportLoop(log *slog.Logger, p *producer.Producer, start time.Time, every time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	var lastSent int64
+	last := start
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			sent, failed := p.Stats()
+			rate := float64(sent-lastSent) / now.Sub(last).Seconds()
+			log.Info("publish throughput", "sent", sent, "failed", failed, "rate_per_sec", fmt.Sprintf("%.1f", rate))
+			lastSent, last = sent, now
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"wbL0/internal/config"
+	"wbL0/internal/db/postgres"
+	"wbL0/internal/encryption"
+	"wbL0/internal/lib/logger"
+	"wbL0/internal/repository/postgres/orderRepoPostgres"
+)
+
+// piiReencrypt moves delivery rows to the active encryption key after a rotation and encrypts rows written
+// before encryption was enabled. It is safe to run while the service is writing and to run again after a failure.
+// With -decrypt it writes the rows back as plaintext instead, which the delivery encryption migration requires
+// before it can be rolled back; the keys are loaded even with encryption disabled in the config.
+func main() {
+	batchSize := flag.Int("batch-size", 0, "rows per transaction, 0 uses encryption.reencrypt_batch_size")
+	decrypt := flag.Bool("decrypt", false, "decrypt the rows instead of re-encrypting them")
+
+	cfg := config.MustLoad()
+	log := logger.SetupLogger(cfg.App.Level)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	encryptionCfg := cfg.Encryption
+	if *decrypt {
+		encryptionCfg.Enabled = true
+	}
+	keys, err := encryption.New(encryptionCfg)
+	if err != nil {
+		log.Error("failed to load encryption keys", "err", err)
+		os.Exit(1)
+	}
+	if keys == nil {
+		log.Error("encryption is disabled, nothing to re-encrypt")
+		os.Exit(1)
+	}
+
+	size := *batchSize
+	if size <= 0 {
+		size = cfg.Encryption.ReencryptBatchSize
+	}
+	if size <= 0 {
+		size = 500
+	}
+
+	pool := postgres.MustLoad(ctx, cfg, log)
+	defer pool.Close()
+	repo := orderRepoPostgres.NewPostgresRepository(pool, log).WithEncryption(keys)
+
+	if *decrypt {
+		log.Info("decrypting deliveries", "batchSize", size)
+		n, err := repo.DecryptDeliveries(ctx, size)
+		if err != nil {
+			log.Error("decryption failed", "decrypted", n, "err", err)
+			os.Exit(1)
+		}
+		log.Info("decryption finished", "decrypted", n)
+		return
+	}
+
+	log.Info("re-encrypting deliveries", "keyID", keys.ActiveKeyID(), "batchSize", size)
+	n, err := repo.ReencryptDeliveries(ctx, size)
+	if err != nil {
+		log.Error("re-encryption failed", "reencrypted", n, "err", err)
+		os.Exit(1)
+	}
+	log.Info("re-encryption finished", "reencrypted", n)
+}
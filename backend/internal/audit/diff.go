@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"wbL0/internal/models"
+)
+
+// Diff lists the fields that differ between two JSON snapshots. Paths look like "delivery.phone" or "items[1].price".
+// An empty snapshot stands for a missing order, so every field of the other one is reported.
+func Diff(from, to json.RawMessage) ([]models.FieldChange, error) {
+	a, err := decode(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := decode(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []models.FieldChange{}
+	walk("", a, b, &changes)
+	return changes, nil
+}
+
+func decode(snapshot json.RawMessage) (any, error) {
+	if len(snapshot) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(snapshot))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func walk(path string, a, b any, changes *[]models.FieldChange) {
+	am, aIsMap := a.(map[string]any)
+	bm, bIsMap := b.(map[string]any)
+	if (aIsMap || a == nil) && (bIsMap || b == nil) && (aIsMap || bIsMap) {
+		keys := make([]string, 0, len(am)+len(bm))
+		for k := range am {
+			keys = append(keys, k)
+		}
+		for k := range bm {
+			if _, ok := am[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walk(join(path, k), am[k], bm[k], changes)
+		}
+		return
+	}
+
+	as, aIsSlice := a.([]any)
+	bs, bIsSlice := b.([]any)
+	if (aIsSlice || a == nil) && (bIsSlice || b == nil) && (aIsSlice || bIsSlice) {
+		for i := 0; i < max(len(as), len(bs)); i++ {
+			var av, bv any
+			if i < len(as) {
+				av = as[i]
+			}
+			if i < len(bs) {
+				bv = bs[i]
+			}
+			walk(fmt.Sprintf("%s[%d]", path, i), av, bv, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, models.FieldChange{Path: path, Old: a, New: b})
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,66 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wbL0/internal/audit"
+	"wbL0/internal/models"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		expected []models.FieldChange
+	}{
+		{
+			name:     "equal snapshots",
+			from:     `{"order":{"order_uid":"o1"},"items":[{"price":1}]}`,
+			to:       `{"items":[{"price":1}],"order":{"order_uid":"o1"}}`,
+			expected: []models.FieldChange{},
+		},
+		{
+			name: "changed nested fields",
+			from: `{"delivery":{"phone":"+1","city":"A"},"items":[{"price":100}]}`,
+			to:   `{"delivery":{"phone":"+2","city":"A"},"items":[{"price":150}]}`,
+			expected: []models.FieldChange{
+				{Path: "delivery.phone", Old: "+1", New: "+2"},
+				{Path: "items[0].price", Old: json.Number("100"), New: json.Number("150")},
+			},
+		},
+		{
+			name: "added and removed items",
+			from: `{"items":[{"rid":"a"}]}`,
+			to:   `{"items":[{"rid":"b"},{"rid":"c"}]}`,
+			expected: []models.FieldChange{
+				{Path: "items[0].rid", Old: "a", New: "b"},
+				{Path: "items[1].rid", Old: nil, New: "c"},
+			},
+		},
+		{
+			name: "deleted order",
+			from: `{"order":{"order_uid":"o1"}}`,
+			to:   ``,
+			expected: []models.FieldChange{
+				{Path: "order.order_uid", Old: "o1", New: nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := audit.Diff(json.RawMessage(tt.from), json.RawMessage(tt.to))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, changes)
+		})
+	}
+}
+
+func TestDiff_InvalidSnapshot(t *testing.T) {
+	_, err := audit.Diff(json.RawMessage(`{`), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,44 @@
+// Package audit carries the origin of order writes and compares order snapshots of the audit trail.
+package audit
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	Unknown = "unknown"
+	// Retention is the source of the versions recorded when orders are archived.
+	Retention = "retention"
+)
+
+type sourceKey struct{}
+
+type orderSourcesKey struct{}
+
+// WithSource marks every write made with ctx as coming from source, e.g. "http:<user>" or a Kafka position.
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// WithOrderSources sets a source per order UID for writes of several orders at once, like a Kafka batch.
+func WithOrderSources(ctx context.Context, sources map[string]string) context.Context {
+	return context.WithValue(ctx, orderSourcesKey{}, sources)
+}
+
+// SourceFor returns the source of a write of the order, falling back to Unknown.
+func SourceFor(ctx context.Context, orderUID string) string {
+	if sources, ok := ctx.Value(orderSourcesKey{}).(map[string]string); ok {
+		if source, ok := sources[orderUID]; ok {
+			return source
+		}
+	}
+	if source, ok := ctx.Value(sourceKey{}).(string); ok && source != "" {
+		return source
+	}
+	return Unknown
+}
+
+func KafkaSource(topic string, partition int, offset int64) string {
+	return fmt.Sprintf("kafka:%s/%d@%d", topic, partition, offset)
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"wbL0/internal/config"
+	"wbL0/internal/models"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+type apiKey struct {
+	key       []byte
+	principal Principal
+}
+
+// APIKeyAuthenticator checks the X-API-Key header against the configured keys.
+type APIKeyAuthenticator struct {
+	keys []apiKey
+}
+
+// NewAPIKeyAuthenticator checks that every key has a name, a key and a known role. It fails when a key
+// is taken from an environment variable that is not set.
+func NewAPIKeyAuthenticator(cfg []config.APIKeyConfig) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{}
+	for _, k := range cfg {
+		if k.Key == "" && k.KeyEnv != "" {
+			value, ok := os.LookupEnv(k.KeyEnv)
+			if !ok || value == "" {
+				return nil, fmt.Errorf("api key %q: %s is not set", k.Name, k.KeyEnv)
+			}
+			k.Key = value
+		}
+		if k.Key == "" || k.Name == "" {
+			return nil, fmt.Errorf("api key %q: name and key are required", k.Name)
+		}
+		role, err := ParseRole(k.Role)
+		if err != nil {
+			return nil, fmt.Errorf("api key %q: %w", k.Name, err)
+		}
+		a.keys = append(a.keys, apiKey{key: []byte(k.Key), principal: Principal{Subject: k.Name, Role: role, Method: "api_key"}})
+	}
+	return a, nil
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	presented := r.Header.Get(APIKeyHeader)
+	if presented == "" {
+		return nil, errNoCredentials
+	}
+
+	// Every key is compared so the response time does not depend on which key matched.
+	var found *Principal
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(a.keys[i].key, []byte(presented)) == 1 {
+			p := a.keys[i].principal
+			found = &p
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: unknown api key", models.ErrUnauthorized)
+	}
+	return found, nil
+}
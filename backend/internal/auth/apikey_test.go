@@ -0,0 +1,46 @@
+package auth_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wbL0/internal/auth"
+	"wbL0/internal/config"
+	"wbL0/internal/models"
+)
+
+func withAPIKey(key string) *http.Request {
+	r, _ := http.NewRequest(http.MethodGet, "/order/1", nil)
+	r.Header.Set(auth.APIKeyHeader, key)
+	return r
+}
+
+func TestNewAPIKeyAuthenticator_KeyEnv(t *testing.T) {
+	t.Run("key is read from the environment", func(t *testing.T) {
+		t.Setenv("TEST_API_KEY", "secret-key")
+		a, err := auth.NewAPIKeyAuthenticator([]config.APIKeyConfig{{Name: "frontend", KeyEnv: "TEST_API_KEY", Role: "support"}})
+		require.NoError(t, err)
+
+		p, err := a.Authenticate(withAPIKey("secret-key"))
+		require.NoError(t, err)
+		assert.Equal(t, "frontend", p.Subject)
+		assert.Equal(t, auth.RoleSupport, p.Role)
+
+		_, err = a.Authenticate(withAPIKey("other"))
+		assert.ErrorIs(t, err, models.ErrUnauthorized)
+	})
+
+	t.Run("missing variable fails", func(t *testing.T) {
+		t.Setenv("TEST_API_KEY", "")
+		_, err := auth.NewAPIKeyAuthenticator([]config.APIKeyConfig{{Name: "frontend", KeyEnv: "TEST_API_KEY", Role: "support"}})
+		assert.ErrorContains(t, err, "TEST_API_KEY is not set")
+	})
+
+	t.Run("auth enabled without keys fails", func(t *testing.T) {
+		_, err := auth.New(config.AuthConfig{Enabled: true})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,96 @@
+// Package auth authenticates HTTP API callers with static API keys or JWTs and carries the caller in the context.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"wbL0/internal/config"
+	"wbL0/internal/models"
+)
+
+type Role string
+
+const (
+	RoleSupport   Role = "support"
+	RoleAnalytics Role = "analytics"
+	RoleAdmin     Role = "admin"
+)
+
+func ParseRole(s string) (Role, error) {
+	switch role := Role(s); role {
+	case RoleSupport, RoleAnalytics, RoleAdmin:
+		return role, nil
+	}
+	return "", fmt.Errorf("unknown role %q", s)
+}
+
+// Principal is an authenticated caller. Subject is the API key name or the "sub" claim of the token.
+type Principal struct {
+	Subject string
+	Role    Role
+	Method  string
+}
+
+// Authenticator identifies the caller of a request. It returns an error wrapping models.ErrUnauthorized
+// when the credentials are missing or invalid.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// errNoCredentials means the request has no credentials of the kind the authenticator handles.
+var errNoCredentials = fmt.Errorf("%w: no credentials", models.ErrUnauthorized)
+
+// Chain tries the authenticators in order. The first one that finds its kind of credentials decides.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range c {
+		p, err := a.Authenticate(r)
+		if errors.Is(err, errNoCredentials) {
+			continue
+		}
+		return p, err
+	}
+	return nil, errNoCredentials
+}
+
+// New builds the authenticator configured in cfg. It returns nil when authentication is disabled.
+func New(cfg config.AuthConfig) (Authenticator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	var chain Chain
+	if len(cfg.APIKeys) > 0 {
+		keys, err := NewAPIKeyAuthenticator(cfg.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, keys)
+	}
+	if cfg.JWTSecret != "" || cfg.JWKSFile != "" {
+		jwt, err := NewJWTAuthenticator(cfg)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, jwt)
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("auth is enabled but neither api keys nor jwt keys are configured")
+	}
+	return chain, nil
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the caller stored in ctx, or nil when the request was not authenticated.
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
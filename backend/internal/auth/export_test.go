@@ -0,0 +1,7 @@
+package auth
+
+import "time"
+
+func SetNow(a *JWTAuthenticator, now func() time.Time) {
+	a.now = now
+}
@@ -0,0 +1,172 @@
+package auth_test
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wbL0/internal/auth"
+	"wbL0/internal/config"
+	"wbL0/internal/models"
+)
+
+var testNow = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func segment(t *testing.T, v any) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hsToken(t *testing.T, secret string, header, claims map[string]any) string {
+	signed := segment(t, header) + "." + segment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func rsToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	signed := segment(t, map[string]any{"alg": "RS256", "kid": kid}) + "." + segment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func bearer(token string) *http.Request {
+	r, _ := http.NewRequest(http.MethodGet, "/order/1", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestJWTAuthenticator_HS256(t *testing.T) {
+	a, err := auth.NewJWTAuthenticator(config.AuthConfig{JWTSecret: "secret", Issuer: "wb", Audience: "orders"})
+	require.NoError(t, err)
+	auth.SetNow(a, func() time.Time { return testNow })
+
+	hs := map[string]any{"alg": "HS256", "typ": "JWT"}
+	valid := func() map[string]any {
+		return map[string]any{"sub": "alice", "role": "support", "iss": "wb", "aud": []string{"orders"}, "exp": testNow.Add(time.Hour).Unix()}
+	}
+	with := func(key string, value any) map[string]any {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	p, err := a.Authenticate(bearer(hsToken(t, "secret", hs, valid())))
+	require.NoError(t, err)
+	assert.Equal(t, &auth.Principal{Subject: "alice", Role: auth.RoleSupport, Method: "jwt"}, p)
+
+	rejected := map[string]string{
+		"wrong secret":   hsToken(t, "other", hs, valid()),
+		"expired":        hsToken(t, "secret", hs, with("exp", testNow.Add(-time.Hour).Unix())),
+		"missing exp":    hsToken(t, "secret", hs, with("exp", nil)),
+		"not yet valid":  hsToken(t, "secret", hs, with("nbf", testNow.Add(time.Hour).Unix())),
+		"wrong issuer":   hsToken(t, "secret", hs, with("iss", "evil")),
+		"wrong audience": hsToken(t, "secret", hs, with("aud", "billing")),
+		"unknown role":   hsToken(t, "secret", hs, with("role", "root")),
+		"alg none":       hsToken(t, "secret", map[string]any{"alg": "none"}, valid()),
+		"malformed":      "abc.def",
+	}
+	for name, token := range rejected {
+		t.Run(name, func(t *testing.T) {
+			_, err := a.Authenticate(bearer(token))
+			assert.ErrorIs(t, err, models.ErrUnauthorized)
+		})
+	}
+}
+
+func TestJWTAuthenticator_RS256WithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "k1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	b, _ := json.Marshal(jwks)
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+
+	a, err := auth.NewJWTAuthenticator(config.AuthConfig{JWKSFile: path})
+	require.NoError(t, err)
+	auth.SetNow(a, func() time.Time { return testNow })
+	claims := map[string]any{"sub": "bi", "role": "analytics", "exp": testNow.Add(time.Hour).Unix()}
+
+	p, err := a.Authenticate(bearer(rsToken(t, key, "k1", claims)))
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleAnalytics, p.Role)
+
+	_, err = a.Authenticate(bearer(rsToken(t, other, "k1", claims)))
+	assert.ErrorIs(t, err, models.ErrUnauthorized)
+
+	_, err = a.Authenticate(bearer(rsToken(t, key, "k2", claims)))
+	assert.ErrorIs(t, err, models.ErrUnauthorized)
+
+	hsOnly := hsToken(t, "", map[string]any{"alg": "HS256"}, claims)
+	_, err = a.Authenticate(bearer(hsOnly))
+	assert.ErrorIs(t, err, models.ErrUnauthorized, "HS256 must not be accepted without a secret")
+}
+
+func TestChain(t *testing.T) {
+	authn, err := auth.New(config.AuthConfig{
+		Enabled:   true,
+		APIKeys:   []config.APIKeyConfig{{Name: "frontend", Key: "k-123", Role: "support"}},
+		JWTSecret: "secret",
+	})
+	require.NoError(t, err)
+
+	r, _ := http.NewRequest(http.MethodGet, "/orders", nil)
+	_, err = authn.Authenticate(r)
+	assert.ErrorIs(t, err, models.ErrUnauthorized, "no credentials")
+
+	r.Header.Set(auth.APIKeyHeader, "k-123")
+	p, err := authn.Authenticate(r)
+	require.NoError(t, err)
+	assert.Equal(t, &auth.Principal{Subject: "frontend", Role: auth.RoleSupport, Method: "api_key"}, p)
+
+	r.Header.Set(auth.APIKeyHeader, "k-12")
+	_, err = authn.Authenticate(r)
+	assert.ErrorIs(t, err, models.ErrUnauthorized)
+
+	r.Header.Del(auth.APIKeyHeader)
+	r.Header.Set("Authorization", "Bearer "+strings.Repeat("x", 10))
+	_, err = authn.Authenticate(r)
+	assert.ErrorIs(t, err, models.ErrUnauthorized)
+}
+
+func TestNew(t *testing.T) {
+	authn, err := auth.New(config.AuthConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, authn, "disabled auth")
+
+	_, err = auth.New(config.AuthConfig{Enabled: true})
+	assert.Error(t, err, "enabled without keys")
+
+	_, err = auth.New(config.AuthConfig{Enabled: true, APIKeys: []config.APIKeyConfig{{Name: "x", Key: "k", Role: "root"}}})
+	assert.Error(t, err, "unknown role")
+}
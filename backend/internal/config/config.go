@@ -8,11 +8,19 @@ import (
 )
 
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	Server   ServerConfig
-	Kafka    KafkaConfig
-	Redis    RedisConfig
+	App        AppConfig
+	Database   DatabaseConfig
+	Server     ServerConfig
+	Kafka      KafkaConfig
+	Redis      RedisConfig
+	Cache      CacheConfig
+	Outbox     OutboxConfig
+	Auth       AuthConfig
+	Redaction  RedactionConfig
+	Encryption EncryptionConfig
+	Retention  RetentionConfig
+	Health     HealthConfig
+	Tracing    TracingConfig
 }
 
 type AppConfig struct {
@@ -26,6 +34,9 @@ type DatabaseConfig struct {
 	Password string `yml:"password"`
 	DBName   string `yml:"db_name"`
 	SSLMode  string `yml:"ssl_mode"`
+	// ConnectTimeout is how long startup waits for Postgres to accept connections.
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+	Breaker        BreakerConfig `yml:"breaker"`
 }
 
 type ServerConfig struct {
@@ -34,10 +45,19 @@ type ServerConfig struct {
 }
 
 type KafkaConfig struct {
-	Brokers   []string `yml:"brokers"`
-	Topic     string   `yml:"topic"`
-	GroupID   string   `mapstructure:"group_id"`
-	Partition int      `yml:"partition"`
+	Brokers         []string `yml:"brokers"`
+	Topic           string   `yml:"topic"`
+	GroupID         string   `mapstructure:"group_id"`
+	Partition       int      `yml:"partition"`
+	DeadLetterTopic string   `mapstructure:"dead_letter_topic"`
+	// StatusTopic carries order status changes; empty disables the status consumer.
+	StatusTopic string `mapstructure:"status_topic"`
+	// Workers is the number of goroutines processing orders; QueueDepth is the buffer of each worker.
+	Workers    int `mapstructure:"workers"`
+	QueueDepth int `mapstructure:"queue_depth"`
+	// BatchSize above one switches ingestion to batches of up to BatchSize messages collected for at most BatchTimeout.
+	BatchSize    int           `mapstructure:"batch_size"`
+	BatchTimeout time.Duration `mapstructure:"batch_timeout"`
 }
 
 type RedisConfig struct {
@@ -46,6 +66,117 @@ type RedisConfig struct {
 	Password string        `yml:"password"`
 	DB       int           `yml:"db"`
 	TTL      time.Duration `yml:"ttl"`
+	// NotFoundTTL is how long unknown order UIDs are remembered; zero disables negative caching.
+	NotFoundTTL time.Duration `mapstructure:"not_found_ttl"`
+	// ConnectTimeout is how long startup waits for Redis. Unless Required is set, the service then starts
+	// without the cache and serves orders from Postgres until Redis is back.
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+	Required       bool          `yml:"required"`
+	Breaker        BreakerConfig `yml:"breaker"`
+}
+
+// BreakerConfig controls a circuit breaker: FailureThreshold consecutive failures open it for OpenTimeout,
+// after which HalfOpenCalls probe calls decide whether it closes again. Zero values use the defaults.
+type BreakerConfig struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	OpenTimeout      time.Duration `mapstructure:"open_timeout"`
+	HalfOpenCalls    int           `mapstructure:"half_open_calls"`
+}
+
+// CacheConfig controls warming Redis from Postgres on startup and the in-process cache tier.
+// WarmupOrders and WarmupMaxAge limit the warm-up window; zero means no limit. LocalSize of zero disables the local tier.
+type CacheConfig struct {
+	LocalSize        int           `mapstructure:"local_size"`
+	LocalTTL         time.Duration `mapstructure:"local_ttl"`
+	WarmupEnabled    bool          `mapstructure:"warmup_enabled"`
+	WarmupOrders     int           `mapstructure:"warmup_orders"`
+	WarmupMaxAge     time.Duration `mapstructure:"warmup_max_age"`
+	WarmupBatchSize  int           `mapstructure:"warmup_batch_size"`
+	EvictionInterval time.Duration `mapstructure:"eviction_interval"`
+}
+
+// OutboxConfig controls publishing of order events. An empty Topic disables the outbox.
+type OutboxConfig struct {
+	Topic        string        `mapstructure:"topic"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+}
+
+// AuthConfig controls authentication of the HTTP API. Callers use one of APIKeys or a JWT signed with
+// JWTSecret (HS256) or with a key from JWKSFile (RS256). Issuer and Audience are checked when set.
+type AuthConfig struct {
+	Enabled   bool           `yml:"enabled"`
+	APIKeys   []APIKeyConfig `mapstructure:"api_keys"`
+	JWTSecret string         `mapstructure:"jwt_secret"`
+	JWKSFile  string         `mapstructure:"jwks_file"`
+	Issuer    string         `yml:"issuer"`
+	Audience  string         `yml:"audience"`
+	RoleClaim string         `mapstructure:"role_claim"`
+}
+
+// APIKeyConfig is a static API key. The key is read from the KeyEnv environment variable when Key is empty,
+// so it does not have to be committed with the config.
+type APIKeyConfig struct {
+	Name   string `yml:"name"`
+	Key    string `yml:"key"`
+	KeyEnv string `mapstructure:"key_env"`
+	Role   string `yml:"role"`
+}
+
+// RedactionConfig lists order fields to mask or drop for each caller role. Default applies to callers
+// without a configured role and Logs to structured log attributes. Fields are FullOrder JSON paths like delivery.phone.
+type RedactionConfig struct {
+	Default RedactionRules            `yml:"default"`
+	Roles   map[string]RedactionRules `yml:"roles"`
+	Logs    RedactionRules            `yml:"logs"`
+}
+
+type RedactionRules struct {
+	Mask []string `yml:"mask"`
+	Drop []string `yml:"drop"`
+}
+
+// EncryptionConfig controls envelope encryption of delivery PII in Postgres and of orders cached in Redis.
+// The keyring is read from KeyFile or, when it is empty, from the environment variable named by KeyEnv.
+type EncryptionConfig struct {
+	Enabled            bool   `yml:"enabled"`
+	KeyFile            string `mapstructure:"key_file"`
+	KeyEnv             string `mapstructure:"key_env"`
+	ReencryptBatchSize int    `mapstructure:"reencrypt_batch_size"`
+}
+
+// RetentionConfig controls archiving of old orders. Every Interval orders created more than Days days ago are
+// moved to the archive, at most MaxBatches batches of BatchSize orders per run. ArchiveFallback makes GetOrder
+// read archived orders when they are not found in the hot tables; customer data export always includes them.
+type RetentionConfig struct {
+	Enabled         bool          `yml:"enabled"`
+	Days            int           `yml:"days"`
+	Interval        time.Duration `yml:"interval"`
+	BatchSize       int           `mapstructure:"batch_size"`
+	MaxBatches      int           `mapstructure:"max_batches"`
+	ArchiveFallback bool          `mapstructure:"archive_fallback"`
+}
+
+// HealthConfig controls the readiness probe. Each dependency check is limited by CheckTimeout, and a consumer
+// group lag above KafkaMaxLag makes the service not ready; zero disables the limit. On shutdown the service
+// reports not ready for DrainDelay before it stops serving.
+type HealthConfig struct {
+	CheckTimeout time.Duration `mapstructure:"check_timeout"`
+	KafkaMaxLag  int64         `mapstructure:"kafka_max_lag"`
+	DrainDelay   time.Duration `mapstructure:"drain_delay"`
+}
+
+// TracingConfig controls OpenTelemetry tracing. Exporter is "otlp" to send spans over OTLP/HTTP to Endpoint
+// (host:port, plain HTTP when Insecure), "stdout" to print them or "file" to append them to File as JSON lines.
+// SampleRatio is the share of new traces recorded; traces started upstream follow the caller's decision.
+type TracingConfig struct {
+	Enabled     bool    `yml:"enabled"`
+	ServiceName string  `mapstructure:"service_name"`
+	Exporter    string  `yml:"exporter"`
+	Endpoint    string  `yml:"endpoint"`
+	Insecure    bool    `yml:"insecure"`
+	File        string  `yml:"file"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
 }
 
 func MustLoad() *Config {
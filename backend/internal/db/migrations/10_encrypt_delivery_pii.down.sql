@@ -0,0 +1,20 @@
+-- dropping dek and key_id would leave ciphertext nobody can decrypt, so the rollback refuses to run until
+-- the rows are decrypted with piiReencrypt -decrypt while the service is stopped
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM delivery WHERE dek IS NOT NULL) THEN
+        RAISE EXCEPTION 'delivery rows are still encrypted, run piiReencrypt -decrypt first';
+    END IF;
+END
+$$;
+
+-- encrypted values do not fit the old column sizes, so the columns stay TEXT
+DROP INDEX IF EXISTS idx_delivery_key_id;
+DROP INDEX IF EXISTS idx_delivery_phone_bidx;
+DROP INDEX IF EXISTS idx_delivery_email_bidx;
+
+ALTER TABLE delivery
+    DROP COLUMN IF EXISTS phone_bidx,
+    DROP COLUMN IF EXISTS email_bidx,
+    DROP COLUMN IF EXISTS key_id,
+    DROP COLUMN IF EXISTS dek;
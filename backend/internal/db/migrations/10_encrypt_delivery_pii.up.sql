@@ -0,0 +1,12 @@
+ALTER TABLE delivery
+    ALTER COLUMN name TYPE TEXT,
+    ALTER COLUMN phone TYPE TEXT,
+    ALTER COLUMN email TYPE TEXT,
+    ADD COLUMN IF NOT EXISTS dek TEXT,
+    ADD COLUMN IF NOT EXISTS key_id VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS email_bidx VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS phone_bidx VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_email_bidx ON delivery (email_bidx);
+CREATE INDEX IF NOT EXISTS idx_delivery_phone_bidx ON delivery (phone_bidx);
+CREATE INDEX IF NOT EXISTS idx_delivery_key_id ON delivery (key_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS customer_erasures;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS erased_at;
@@ -0,0 +1,14 @@
+-- erased orders keep their financial data, re-ingesting them does not restore the erased fields
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS erased_at TIMESTAMPTZ;
+
+-- the customer is identified by a SHA-256 hash, so the audit log does not keep the erased identifier
+CREATE TABLE IF NOT EXISTS customer_erasures (
+    id BIGSERIAL PRIMARY KEY,
+    customer_hash VARCHAR(64) NOT NULL,
+    order_uids TEXT[] NOT NULL,
+    requested_by VARCHAR(255) NOT NULL,
+    reason TEXT,
+    erased_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_erasures_customer ON customer_erasures (customer_hash);
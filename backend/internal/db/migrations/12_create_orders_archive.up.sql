@@ -0,0 +1,16 @@
+-- orders older than the retention period are moved here with delivery, payment and items as one JSON document;
+-- delivery fields stay encrypted with the data key in dek
+CREATE TABLE IF NOT EXISTS orders_archive (
+    order_uid VARCHAR(255) PRIMARY KEY,
+    customer_id VARCHAR(255),
+    date_created TIMESTAMP,
+    status VARCHAR(32),
+    payload JSONB NOT NULL,
+    dek TEXT,
+    key_id VARCHAR(64),
+    erased_at TIMESTAMPTZ,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_archive_customer_id ON orders_archive (customer_id);
+CREATE INDEX IF NOT EXISTS idx_orders_archive_key_id ON orders_archive (key_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_customer_erasures_erased_at;
+DROP TABLE IF EXISTS cache_evictions;
@@ -0,0 +1,8 @@
+-- orders that must be evicted from Redis; a row is removed only after the eviction succeeded,
+-- so erased data is not served from the cache after a Redis outage
+CREATE TABLE IF NOT EXISTS cache_evictions (
+    order_uid VARCHAR(255) PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_erasures_erased_at ON customer_erasures (erased_at);
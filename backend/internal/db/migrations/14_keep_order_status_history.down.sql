@@ -0,0 +1,5 @@
+-- the foreign key cannot be restored while the history of deleted or archived orders exists, and that history is dropped
+DELETE FROM order_status_history h WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.order_uid = h.order_uid);
+
+ALTER TABLE order_status_history
+    ADD CONSTRAINT order_status_history_order_uid_fkey FOREIGN KEY (order_uid) REFERENCES orders(order_uid) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- the status history is an audit log like order_versions: it outlives DELETE /order and archiving
+ALTER TABLE order_status_history DROP CONSTRAINT IF EXISTS order_status_history_order_uid_fkey;
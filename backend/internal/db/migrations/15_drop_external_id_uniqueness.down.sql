@@ -0,0 +1,7 @@
+-- fails while duplicate values exist
+DROP INDEX IF EXISTS idx_payment_transaction;
+DROP INDEX IF EXISTS idx_orders_track_number;
+
+ALTER TABLE payment ADD CONSTRAINT payment_transaction_key UNIQUE (transaction);
+ALTER TABLE items ADD CONSTRAINT items_rid_key UNIQUE (rid);
+ALTER TABLE orders ADD CONSTRAINT orders_track_number_key UNIQUE (track_number);
@@ -0,0 +1,9 @@
+-- Only order_uid identifies an order. Track numbers, item rids and payment transactions come from upstream
+-- systems that do not promise to keep them unique across orders, and a duplicate would fail the whole order
+-- with a unique violation after all retries, so they are indexed for lookups but not unique.
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_track_number_key;
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_rid_key;
+ALTER TABLE payment DROP CONSTRAINT IF EXISTS payment_transaction_key;
+
+CREATE INDEX IF NOT EXISTS idx_orders_track_number ON orders (track_number);
+CREATE INDEX IF NOT EXISTS idx_payment_transaction ON payment (transaction);
@@ -0,0 +1 @@
+ALTER TABLE order_versions DROP COLUMN IF EXISTS status;
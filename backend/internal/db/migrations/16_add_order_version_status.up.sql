@@ -0,0 +1,3 @@
+-- status changes and archiving are recorded as versions too; the status is not part of the snapshot,
+-- so every version keeps the order status it left the order in
+ALTER TABLE order_versions ADD COLUMN IF NOT EXISTS status VARCHAR(32);
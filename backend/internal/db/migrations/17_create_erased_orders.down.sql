@@ -0,0 +1 @@
+DROP TABLE IF EXISTS erased_orders;
@@ -0,0 +1,17 @@
+-- tombstones of erased orders: unlike orders.erased_at they outlive DELETE /order and archiving, so a replayed
+-- order never brings the erased customer data back
+CREATE TABLE IF NOT EXISTS erased_orders (
+    order_uid VARCHAR(255) PRIMARY KEY,
+    erased_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO erased_orders (order_uid, erased_at)
+SELECT order_uid, MIN(erased_at) FROM (
+    SELECT unnest(order_uids) AS order_uid, erased_at FROM customer_erasures
+    UNION ALL
+    SELECT order_uid, erased_at FROM orders WHERE erased_at IS NOT NULL
+    UNION ALL
+    SELECT order_uid, erased_at FROM orders_archive WHERE erased_at IS NOT NULL
+) erased
+GROUP BY order_uid
+ON CONFLICT (order_uid) DO NOTHING;
@@ -0,0 +1,2 @@
+-- fails while orders with several items exist
+ALTER TABLE items ADD CONSTRAINT items_track_number_key UNIQUE (track_number);
@@ -0,0 +1,3 @@
+-- Validation requires every item to carry the track number of its order, so an order with several items
+-- could not be stored with items.track_number unique. A no-op where migration 6 already dropped it.
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_track_number_key;
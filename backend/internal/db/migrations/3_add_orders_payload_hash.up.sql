@@ -0,0 +1 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payload_hash VARCHAR(64);
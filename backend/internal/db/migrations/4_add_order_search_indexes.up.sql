@@ -0,0 +1,8 @@
+CREATE INDEX IF NOT EXISTS idx_orders_date_created_uid ON orders (date_created DESC, order_uid DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders (customer_id);
+CREATE INDEX IF NOT EXISTS idx_orders_delivery_service ON orders (delivery_service);
+CREATE INDEX IF NOT EXISTS idx_payment_currency ON payment (currency);
+CREATE INDEX IF NOT EXISTS idx_payment_provider ON payment (provider);
+CREATE INDEX IF NOT EXISTS idx_payment_bank ON payment (bank);
+CREATE INDEX IF NOT EXISTS idx_items_order_uid ON items (order_uid);
+CREATE INDEX IF NOT EXISTS idx_items_brand ON items (brand);
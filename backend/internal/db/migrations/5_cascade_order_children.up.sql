@@ -0,0 +1,8 @@
+ALTER TABLE delivery DROP CONSTRAINT IF EXISTS delivery_order_uid_fkey,
+    ADD CONSTRAINT delivery_order_uid_fkey FOREIGN KEY (order_uid) REFERENCES orders(order_uid) ON DELETE CASCADE;
+
+ALTER TABLE payment DROP CONSTRAINT IF EXISTS payment_order_uid_fkey,
+    ADD CONSTRAINT payment_order_uid_fkey FOREIGN KEY (order_uid) REFERENCES orders(order_uid) ON DELETE CASCADE;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_order_uid_fkey,
+    ADD CONSTRAINT items_order_uid_fkey FOREIGN KEY (order_uid) REFERENCES orders(order_uid) ON DELETE CASCADE;
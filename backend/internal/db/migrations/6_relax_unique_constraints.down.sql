@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_items_nm_id;
+
+ALTER TABLE items ADD CONSTRAINT items_nm_id_key UNIQUE (nm_id);
+ALTER TABLE items ADD CONSTRAINT items_chrt_id_key UNIQUE (chrt_id);
+ALTER TABLE delivery ADD CONSTRAINT delivery_email_key UNIQUE (email);
+ALTER TABLE delivery ADD CONSTRAINT delivery_phone_key UNIQUE (phone);
@@ -0,0 +1,9 @@
+-- The same customer places many orders and the same product appears in many orders,
+-- so these columns can not be unique. items.track_number is relaxed in migration 18 and identifiers that come
+-- from upstream systems in migration 15.
+ALTER TABLE delivery DROP CONSTRAINT IF EXISTS delivery_phone_key;
+ALTER TABLE delivery DROP CONSTRAINT IF EXISTS delivery_email_key;
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_chrt_id_key;
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_nm_id_key;
+
+CREATE INDEX IF NOT EXISTS idx_items_nm_id ON items (nm_id);
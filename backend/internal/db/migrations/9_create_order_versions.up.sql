@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS order_versions (
+    id BIGSERIAL PRIMARY KEY,
+    order_uid VARCHAR(255) NOT NULL,
+    version INT NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    previous JSONB,
+    current JSONB,
+    source VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (order_uid, version)
+);
@@ -3,31 +3,48 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"time"
 	"wbL0/internal/config"
+	"wbL0/internal/lib/backoff"
 
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func MustLoad(ctx context.Context, cfg *config.Config) *pgxpool.Pool {
+const (
+	connectBackoff    = 500 * time.Millisecond
+	maxConnectBackoff = 5 * time.Second
+)
+
+// MustLoad waits up to cfg.Database.ConnectTimeout for Postgres to accept connections, logging every failed
+// attempt, applies the migrations and returns the pool. It panics if Postgres stays unreachable.
+func MustLoad(ctx context.Context, cfg *config.Config, log *slog.Logger) *pgxpool.Pool {
+	const op = "postgres.MustLoad"
+
 	connStr := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
 		cfg.Database.User, cfg.Database.Password,
 		cfg.Database.Host, cfg.Database.Port,
 		cfg.Database.DBName, cfg.Database.SSLMode)
 
-	err := runMigrations(ctx, connStr)
-	if err != nil {
-		panic(fmt.Sprintf("failed to run migrations: %v", err))
-	}
-
 	pool, err := pgxpool.New(ctx, connStr)
 	if err != nil {
 		panic(fmt.Sprintf("failed to connect to database: %v", err))
 	}
 
-	if err := pool.Ping(ctx); err != nil {
+	err = backoff.Retry(ctx, cfg.Database.ConnectTimeout, connectBackoff, maxConnectBackoff, pool.Ping,
+		func(attempt int, err error, wait time.Duration) {
+			log.Warn("database is not reachable, retrying", "op", op, "attempt", attempt, "err", err, "wait", wait.Round(time.Millisecond))
+		})
+	if err != nil {
+		pool.Close()
 		panic(fmt.Sprintf("failed to ping database: %v", err))
 	}
 
+	if err := runMigrations(ctx, connStr); err != nil {
+		pool.Close()
+		panic(fmt.Sprintf("failed to run migrations: %v", err))
+	}
+
 	return pool
 }
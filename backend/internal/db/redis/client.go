@@ -4,10 +4,23 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-redis/redis/v8"
+	"log/slog"
+	"time"
 	"wbL0/internal/config"
+	"wbL0/internal/lib/backoff"
 )
 
-func NewRedisClient(ctx context.Context, cfg *config.Config) *redis.Client {
+const (
+	connectBackoff    = 500 * time.Millisecond
+	maxConnectBackoff = 5 * time.Second
+)
+
+// Connect creates the client and pings Redis with backoff for up to cfg.Redis.ConnectTimeout, logging every failed
+// attempt. The client is returned with the error even if Redis stays unreachable: it reconnects on its own,
+// so the caller may go on without the cache.
+func Connect(ctx context.Context, cfg *config.Config, log *slog.Logger) (*redis.Client, error) {
+	const op = "redis.Connect"
+
 	addr := fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port)
 
 	rdb := redis.NewClient(&redis.Options{
@@ -16,8 +29,21 @@ func NewRedisClient(ctx context.Context, cfg *config.Config) *redis.Client {
 		DB:       cfg.Redis.DB,
 	})
 
-	if err := rdb.Ping(ctx).Err(); err != nil {
-		panic(fmt.Errorf("failed to connect to redis: %w", err))
+	err := backoff.Retry(ctx, cfg.Redis.ConnectTimeout, connectBackoff, maxConnectBackoff,
+		func(ctx context.Context) error { return rdb.Ping(ctx).Err() },
+		func(attempt int, err error, wait time.Duration) {
+			log.Warn("redis is not reachable, retrying", "op", op, "attempt", attempt, "err", err, "wait", wait.Round(time.Millisecond))
+		})
+	if err != nil {
+		return rdb, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return rdb, nil
+}
+
+func NewRedisClient(ctx context.Context, cfg *config.Config, log *slog.Logger) *redis.Client {
+	rdb, err := Connect(ctx, cfg, log)
+	if err != nil {
+		panic(err)
 	}
 	return rdb
 }
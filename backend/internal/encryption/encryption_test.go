@@ -0,0 +1,182 @@
+package encryption_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wbL0/internal/config"
+	"wbL0/internal/encryption"
+)
+
+func key(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32)))
+}
+
+func keyring(t *testing.T, active string, keys map[string]string) *encryption.Keyring {
+	t.Helper()
+	var pairs []string
+	for id, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%q:%q", id, k))
+	}
+	raw := fmt.Sprintf(`{"active":%q,"keys":{%s},"index_key":%q}`, active, strings.Join(pairs, ","), key('i'))
+	k, err := encryption.ParseKeyring([]byte(raw))
+	require.NoError(t, err)
+	return k
+}
+
+func TestDataKey(t *testing.T) {
+	t.Parallel()
+
+	k := keyring(t, "k1", map[string]string{"k1": key('a')})
+	dk, err := k.NewDataKey()
+	require.NoError(t, err)
+	assert.Equal(t, "k1", dk.KeyID)
+
+	encrypted, err := dk.Encrypt("test@gmail.com", "delivery/u1/email")
+	require.NoError(t, err)
+	assert.True(t, encryption.IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "test@gmail.com")
+
+	again, err := dk.Encrypt("test@gmail.com", "delivery/u1/email")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "encryption must not be deterministic")
+
+	opened, err := k.OpenDataKey(dk.KeyID, dk.Wrapped)
+	require.NoError(t, err)
+	plaintext, err := opened.Decrypt(encrypted, "delivery/u1/email")
+	require.NoError(t, err)
+	assert.Equal(t, "test@gmail.com", plaintext)
+
+	_, err = opened.Decrypt(encrypted, "delivery/u2/email")
+	assert.Error(t, err, "associated data binds the ciphertext to its field")
+
+	legacy, err := opened.Decrypt("plain", "delivery/u1/email")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", legacy)
+
+	empty, err := dk.Encrypt("", "delivery/u1/email")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestRotation(t *testing.T) {
+	t.Parallel()
+
+	old := keyring(t, "k1", map[string]string{"k1": key('a')})
+	dk, err := old.NewDataKey()
+	require.NoError(t, err)
+	encrypted, err := dk.Encrypt("Test Testov", "aad")
+	require.NoError(t, err)
+	sealed, err := old.Seal([]byte(`{"a":1}`))
+	require.NoError(t, err)
+
+	rotated := keyring(t, "k2", map[string]string{"k1": key('a'), "k2": key('b')})
+	opened, err := rotated.OpenDataKey(dk.KeyID, dk.Wrapped)
+	require.NoError(t, err)
+	rewrapped, err := rotated.Rewrap(opened)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", rewrapped.KeyID)
+
+	payload, err := rotated.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(payload))
+
+	retired := keyring(t, "k2", map[string]string{"k2": key('b')})
+	_, err = retired.OpenDataKey(dk.KeyID, dk.Wrapped)
+	assert.ErrorIs(t, err, encryption.ErrUnknownKey)
+
+	reopened, err := retired.OpenDataKey(rewrapped.KeyID, rewrapped.Wrapped)
+	require.NoError(t, err)
+	plaintext, err := reopened.Decrypt(encrypted, "aad")
+	require.NoError(t, err)
+	assert.Equal(t, "Test Testov", plaintext, "rewrapping keeps existing ciphertext readable")
+}
+
+func TestSealOpen(t *testing.T) {
+	t.Parallel()
+
+	k := keyring(t, "k1", map[string]string{"k1": key('a')})
+	payload := []byte(`{"delivery":{"email":"test@gmail.com"}}`)
+
+	sealed, err := k.Seal(payload)
+	require.NoError(t, err)
+	assert.True(t, encryption.IsSealed(sealed))
+	assert.NotContains(t, string(sealed), "test@gmail.com")
+
+	opened, err := k.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, payload, opened)
+
+	legacy, err := k.Open(payload)
+	require.NoError(t, err)
+	assert.Equal(t, payload, legacy)
+
+	_, err = k.Open(append(sealed[:len(sealed)-4:len(sealed)-4], "AAA="...))
+	assert.Error(t, err)
+}
+
+func TestBlindIndex(t *testing.T) {
+	t.Parallel()
+
+	k := keyring(t, "k1", map[string]string{"k1": key('a')})
+
+	assert.Equal(t, k.BlindIndex(encryption.IndexEmail, "Test@Gmail.com "), k.BlindIndex(encryption.IndexEmail, "test@gmail.com"))
+	assert.Equal(t, k.BlindIndex(encryption.IndexPhone, "+7 (999) 123-45-67"), k.BlindIndex(encryption.IndexPhone, "79991234567"))
+	assert.NotEqual(t, k.BlindIndex(encryption.IndexEmail, "a@b.c"), k.BlindIndex(encryption.IndexEmail, "b@b.c"))
+	assert.NotEqual(t, k.BlindIndex(encryption.IndexEmail, "79991234567"), k.BlindIndex(encryption.IndexPhone, "79991234567"))
+	assert.Empty(t, k.BlindIndex(encryption.IndexPhone, "n/a"))
+	assert.Len(t, k.BlindIndex(encryption.IndexEmail, "a@b.c"), 64)
+
+	other, err := encryption.ParseKeyring([]byte(fmt.Sprintf(`{"active":"k1","keys":{"k1":%q},"index_key":%q}`, key('a'), key('j'))))
+	require.NoError(t, err)
+	assert.NotEqual(t, k.BlindIndex(encryption.IndexEmail, "a@b.c"), other.BlindIndex(encryption.IndexEmail, "a@b.c"))
+}
+
+func TestNew(t *testing.T) {
+	raw := fmt.Sprintf(`{"active":"k1","keys":{"k1":%q},"index_key":%q}`, key('a'), key('i'))
+
+	t.Run("disabled", func(t *testing.T) {
+		k, err := encryption.New(config.EncryptionConfig{})
+		assert.NoError(t, err)
+		assert.Nil(t, k)
+	})
+
+	t.Run("key file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.json")
+		require.NoError(t, os.WriteFile(path, []byte(raw), 0o600))
+		k, err := encryption.New(config.EncryptionConfig{Enabled: true, KeyFile: path})
+		require.NoError(t, err)
+		assert.Equal(t, "k1", k.ActiveKeyID())
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("TEST_PII_KEYS", raw)
+		k, err := encryption.New(config.EncryptionConfig{Enabled: true, KeyEnv: "TEST_PII_KEYS"})
+		require.NoError(t, err)
+		assert.Equal(t, "k1", k.ActiveKeyID())
+	})
+
+	t.Run("missing env", func(t *testing.T) {
+		_, err := encryption.New(config.EncryptionConfig{Enabled: true, KeyEnv: "TEST_PII_KEYS_MISSING"})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid keyring", func(t *testing.T) {
+		for _, bad := range []string{
+			`{"active":"k2","keys":{"k1":"` + key('a') + `"},"index_key":"` + key('i') + `"}`,
+			`{"active":"k1","keys":{"k1":"c2hvcnQ="},"index_key":"` + key('i') + `"}`,
+			`{"active":"k1","keys":{"k1":"` + key('a') + `"}}`,
+			`{"active":"k1","keys":{"k1":""},"index_key":""}`, // the unfilled .env.example
+		} {
+			_, err := encryption.ParseKeyring([]byte(bad))
+			assert.Error(t, err, bad)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// envelopePrefix starts every sealed payload. Payloads without it are plaintext written before encryption
+// was enabled, such as orders cached in Redis by an older instance.
+var envelopePrefix = []byte("wbl0enc1:")
+
+// Seal encrypts a whole payload with a fresh data key. The result is self-contained ASCII:
+// prefix, key id, wrapped data key and ciphertext separated by colons.
+func (k *Keyring) Seal(plaintext []byte) ([]byte, error) {
+	dk, err := k.NewDataKey()
+	if err != nil {
+		return nil, err
+	}
+	data, err := seal(dk.aead, plaintext, []byte(dk.KeyID))
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.Write(envelopePrefix)
+	b.WriteString(dk.KeyID)
+	b.WriteByte(':')
+	b.WriteString(dk.Wrapped)
+	b.WriteByte(':')
+	b.WriteString(base64.StdEncoding.EncodeToString(data))
+	return b.Bytes(), nil
+}
+
+// Open decrypts a payload produced by Seal and returns any other payload unchanged.
+func (k *Keyring) Open(payload []byte) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(payload, envelopePrefix)
+	if !ok {
+		return payload, nil
+	}
+	parts := bytes.SplitN(rest, []byte{':'}, 3)
+	if len(parts) != 3 {
+		return nil, errors.New("malformed sealed payload")
+	}
+	keyID := string(parts[0])
+	dk, err := k.OpenDataKey(keyID, string(parts[1]))
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(string(parts[2]))
+	if err != nil {
+		return nil, fmt.Errorf("decode sealed payload: %w", err)
+	}
+	plaintext, err := open(dk.aead, data, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("open sealed payload: %w", err)
+	}
+	return plaintext, nil
+}
+
+// IsSealed reports whether the payload was produced by Seal.
+func IsSealed(payload []byte) bool {
+	return bytes.HasPrefix(payload, envelopePrefix)
+}
@@ -0,0 +1,98 @@
+package encryption
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// fieldPrefix marks encrypted column values. Values without it are plaintext written before encryption was
+// enabled and are returned as they are.
+const fieldPrefix = "enc:v1:"
+
+// DataKey encrypts the fields of one record. KeyID and Wrapped are stored with the record.
+type DataKey struct {
+	KeyID   string
+	Wrapped string
+	key     []byte
+	aead    cipher.AEAD
+}
+
+// Encrypt encrypts value bound to aad, which should name the record and the field, so a ciphertext copied
+// into another row or column does not decrypt. Empty values stay empty.
+func (d *DataKey) Encrypt(value, aad string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	data, err := seal(d.aead, []byte(value), []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return fieldPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+func (d *DataKey) Decrypt(value, aad string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, fieldPrefix)
+	if !ok {
+		return value, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("decode field: %w", err)
+	}
+	plaintext, err := open(d.aead, data, []byte(aad))
+	if err != nil {
+		return "", fmt.Errorf("decrypt field: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether the column value was written by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, fieldPrefix)
+}
+
+type IndexKind string
+
+const (
+	IndexEmail IndexKind = "email"
+	IndexPhone IndexKind = "phone"
+)
+
+// BlindIndex returns a deterministic keyed hash of the normalized value, so equal emails or phones can be
+// looked up without decrypting. The kind is part of the hash, so an email never matches a phone.
+// Empty values have no index.
+func (k *Keyring) BlindIndex(kind IndexKind, value string) string {
+	normalized := Normalize(kind, value)
+	if normalized == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Normalize lowercases emails and keeps only the digits of phones, so "+7 (999) 123-45-67" and
+// "79991234567" share an index.
+func Normalize(kind IndexKind, value string) string {
+	switch kind {
+	case IndexEmail:
+		return strings.ToLower(strings.TrimSpace(value))
+	case IndexPhone:
+		return strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, value)
+	default:
+		return strings.TrimSpace(value)
+	}
+}
@@ -0,0 +1,193 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"wbL0/internal/config"
+)
+
+// DefaultKeyEnv is the environment variable holding the keyring when neither key_file nor key_env is configured.
+const DefaultKeyEnv = "WBL0_PII_KEYS"
+
+const keySize = 32
+
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// keyringFile is the keyring format of the key file and the environment variable. Keys are base64 encoded
+// 32-byte AES-256 keys; Active names the key encrypting new data keys, the others only decrypt.
+type keyringFile struct {
+	Active   string            `json:"active"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"index_key"`
+}
+
+// Keyring holds the key encryption keys and the blind index key. Every record is encrypted with its own
+// random data key, which is stored next to the record wrapped by the active key encryption key, so rotating
+// the active key only rewraps data keys.
+type Keyring struct {
+	active   string
+	keks     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// New loads the keyring described by cfg. It returns nil when encryption is disabled.
+func New(cfg config.EncryptionConfig) (*Keyring, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if cfg.KeyFile != "" {
+		data, err = os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read key file: %w", err)
+		}
+	} else {
+		env := cfg.KeyEnv
+		if env == "" {
+			env = DefaultKeyEnv
+		}
+		value, ok := os.LookupEnv(env)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("encryption is enabled but %s is not set", env)
+		}
+		data = []byte(value)
+	}
+	return ParseKeyring(data)
+}
+
+func ParseKeyring(data []byte) (*Keyring, error) {
+	var f keyringFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse keyring: %w", err)
+	}
+	if f.Active == "" {
+		return nil, errors.New("keyring has no active key")
+	}
+	if _, ok := f.Keys[f.Active]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the keyring", f.Active)
+	}
+
+	k := &Keyring{active: f.Active, keks: make(map[string]cipher.AEAD, len(f.Keys))}
+	for id, encoded := range f.Keys {
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		k.keks[id] = aead
+	}
+
+	indexKey, err := decodeKey(f.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("index key: %w", err)
+	}
+	k.indexKey = indexKey
+	return k, nil
+}
+
+// ActiveKeyID is the id of the key encryption key that wraps new data keys.
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// NewDataKey generates a random data key wrapped by the active key.
+func (k *Keyring) NewDataKey() (*DataKey, error) {
+	key := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return k.wrap(key)
+}
+
+// OpenDataKey unwraps a data key stored with a record.
+func (k *Keyring) OpenDataKey(keyID, wrapped string) (*DataKey, error) {
+	kek, ok := k.keks[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	raw, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("decode data key: %w", err)
+	}
+	key, err := open(kek, raw, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &DataKey{KeyID: keyID, Wrapped: wrapped, key: key, aead: aead}, nil
+}
+
+// Rewrap returns the same data key wrapped by the active key, so the record it encrypts stays as it is.
+func (k *Keyring) Rewrap(dk *DataKey) (*DataKey, error) {
+	return k.wrap(dk.key)
+}
+
+func (k *Keyring) wrap(key []byte) (*DataKey, error) {
+	wrapped, err := seal(k.keks[k.active], key, []byte(k.active))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &DataKey{
+		KeyID:   k.active,
+		Wrapped: base64.StdEncoding.EncodeToString(wrapped),
+		key:     key,
+		aead:    aead,
+	}, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode key: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns nonce || ciphertext.
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
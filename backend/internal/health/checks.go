@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/segmentio/kafka-go"
+)
+
+// Postgres pings the pool and reports its connection counts.
+func Postgres(pool *pgxpool.Pool) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		stat := pool.Stat()
+		details := map[string]any{"total_conns": stat.TotalConns(), "idle_conns": stat.IdleConns()}
+		return details, pool.Ping(ctx)
+	}
+}
+
+// Redis pings the client.
+func Redis(rdb *redis.Client) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		return nil, rdb.Ping(ctx).Err()
+	}
+}
+
+// Kafka reads the topic metadata, the last offsets and the offsets committed by the consumer group and
+// reports the group lag summed over partitions. A lag above maxLag fails the check; zero disables the limit.
+func Kafka(client *kafka.Client, topic, groupID string, maxLag int64) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+		if err != nil {
+			return nil, err
+		}
+		if len(meta.Topics) == 0 || meta.Topics[0].Error != nil {
+			return nil, fmt.Errorf("topic %s is not available", topic)
+		}
+
+		partitions := make([]int, len(meta.Topics[0].Partitions))
+		requests := make([]kafka.OffsetRequest, 0, 2*len(partitions))
+		for i, p := range meta.Topics[0].Partitions {
+			partitions[i] = p.ID
+			requests = append(requests, kafka.FirstOffsetOf(p.ID), kafka.LastOffsetOf(p.ID))
+		}
+
+		offsets, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{topic: requests}})
+		if err != nil {
+			return nil, err
+		}
+		committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: groupID, Topics: map[string][]int{topic: partitions}})
+		if err != nil {
+			return nil, err
+		}
+		if committed.Error != nil {
+			return nil, committed.Error
+		}
+
+		committedBy := make(map[int]int64, len(partitions))
+		for _, p := range committed.Topics[topic] {
+			committedBy[p.Partition] = p.CommittedOffset
+		}
+		var lag int64
+		for _, p := range offsets.Topics[topic] {
+			if p.Error != nil {
+				return nil, p.Error
+			}
+			lag += partitionLag(p.FirstOffset, p.LastOffset, committedBy[p.Partition])
+		}
+
+		details := map[string]any{"partitions": len(partitions), "lag": lag}
+		if maxLag > 0 && lag > maxLag {
+			return details, fmt.Errorf("consumer lag %d exceeds %d", lag, maxLag)
+		}
+		return details, nil
+	}
+}
+
+// partitionLag counts the messages after the committed offset. A group without a commit has not read
+// the partition yet, so everything still retained is lag.
+func partitionLag(first, last, committed int64) int64 {
+	if committed < first {
+		committed = first
+	}
+	if last <= committed {
+		return 0
+	}
+	return last - committed
+}
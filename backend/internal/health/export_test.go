@@ -0,0 +1,3 @@
+package health
+
+var PartitionLag = partitionLag
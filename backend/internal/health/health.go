@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+	"wbL0/internal/models"
+)
+
+const (
+	ReasonDraining  = "draining"
+	ReasonWarmingUp = "warming_up"
+
+	defaultTimeout = 2 * time.Second
+)
+
+// Check reports whether a dependency is usable. Details, such as pool sizes or consumer lag,
+// are included in the readiness report either way.
+type Check func(ctx context.Context) (map[string]any, error)
+
+type namedCheck struct {
+	name     string
+	check    Check
+	optional bool
+}
+
+// Health tracks the readiness of the service: every dependency check passes, no cache warm-up is running
+// and the service is not draining before shutdown.
+type Health struct {
+	checks   []namedCheck
+	timeout  time.Duration
+	draining atomic.Bool
+	warmups  atomic.Int32
+}
+
+// New returns a Health whose checks run with the given timeout each.
+func New(timeout time.Duration) *Health {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Health{timeout: timeout}
+}
+
+// WithCheck adds a dependency check reported under name.
+func (h *Health) WithCheck(name string, check Check) *Health {
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+	return h
+}
+
+// WithOptionalCheck adds a check of a dependency the service can run without. It is reported
+// but does not affect readiness.
+func (h *Health) WithOptionalCheck(name string, check Check) *Health {
+	h.checks = append(h.checks, namedCheck{name: name, check: check, optional: true})
+	return h
+}
+
+// Drain marks the service as not ready for good, so the orchestrator stops routing traffic before shutdown.
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// WarmupStarted marks the service as not ready until the returned function is called.
+func (h *Health) WarmupStarted() (done func()) {
+	h.warmups.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { h.warmups.Add(-1) })
+	}
+}
+
+// Ready runs every check concurrently and reports the result.
+func (h *Health) Ready(ctx context.Context) models.HealthReport {
+	report := models.HealthReport{Status: models.HealthReady, Checks: make(map[string]models.DependencyHealth, len(h.checks))}
+
+	results := make([]models.DependencyHealth, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.run(ctx, c.check)
+		}()
+	}
+	wg.Wait()
+
+	for i, c := range h.checks {
+		results[i].Optional = c.optional
+		report.Checks[c.name] = results[i]
+		if results[i].Status != models.HealthUp && !c.optional {
+			report.Status = models.HealthNotReady
+		}
+	}
+
+	switch {
+	case h.draining.Load():
+		report.Status, report.Reason = models.HealthNotReady, ReasonDraining
+	case h.warmups.Load() > 0:
+		report.Status, report.Reason = models.HealthNotReady, ReasonWarmingUp
+	}
+	return report
+}
+
+// run reports the dependency as down once the timeout passes, even if the check ignores its context.
+func (h *Health) run(ctx context.Context, check Check) models.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	type outcome struct {
+		details map[string]any
+		err     error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		details, err := check(ctx)
+		done <- outcome{details: details, err: err}
+	}()
+
+	var o outcome
+	select {
+	case o = <-done:
+	case <-ctx.Done():
+		o.err = ctx.Err()
+	}
+
+	result := models.DependencyHealth{Status: models.HealthUp, LatencyMs: time.Since(start).Milliseconds(), Details: o.details}
+	if o.err != nil {
+		result.Status, result.Error = models.HealthDown, o.err.Error()
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"wbL0/internal/health"
+	"wbL0/internal/models"
+)
+
+func up(context.Context) (map[string]any, error) { return map[string]any{"lag": 3}, nil }
+
+func down(context.Context) (map[string]any, error) { return nil, errors.New("connection refused") }
+
+func TestHealth_Ready(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("all dependencies up", func(t *testing.T) {
+		report := health.New(time.Second).WithCheck("postgres", up).WithCheck("redis", up).Ready(ctx)
+
+		assert.Equal(t, models.HealthReady, report.Status)
+		assert.Empty(t, report.Reason)
+		assert.Equal(t, models.HealthUp, report.Checks["postgres"].Status)
+		assert.Equal(t, map[string]any{"lag": 3}, report.Checks["redis"].Details)
+	})
+
+	t.Run("failed dependency", func(t *testing.T) {
+		report := health.New(time.Second).WithCheck("postgres", up).WithCheck("redis", down).Ready(ctx)
+
+		assert.Equal(t, models.HealthNotReady, report.Status)
+		assert.Equal(t, models.HealthUp, report.Checks["postgres"].Status)
+		assert.Equal(t, models.HealthDown, report.Checks["redis"].Status)
+		assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+	})
+
+	t.Run("optional dependency down", func(t *testing.T) {
+		report := health.New(time.Second).WithCheck("postgres", up).WithOptionalCheck("redis", down).Ready(ctx)
+
+		assert.Equal(t, models.HealthReady, report.Status)
+		assert.Equal(t, models.HealthDown, report.Checks["redis"].Status)
+		assert.True(t, report.Checks["redis"].Optional)
+	})
+
+	t.Run("hanging check times out", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		hang := func(context.Context) (map[string]any, error) {
+			<-release
+			return nil, nil
+		}
+
+		start := time.Now()
+		report := health.New(20*time.Millisecond).WithCheck("kafka", hang).Ready(ctx)
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, models.HealthNotReady, report.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["kafka"].Error)
+	})
+
+	t.Run("not ready while warming up", func(t *testing.T) {
+		h := health.New(time.Second).WithCheck("postgres", up)
+		done := h.WarmupStarted()
+
+		report := h.Ready(ctx)
+		assert.Equal(t, models.HealthNotReady, report.Status)
+		assert.Equal(t, health.ReasonWarmingUp, report.Reason)
+
+		done()
+		done()
+		assert.Equal(t, models.HealthReady, h.Ready(ctx).Status)
+	})
+
+	t.Run("not ready while draining", func(t *testing.T) {
+		h := health.New(time.Second).WithCheck("postgres", up)
+		h.Drain()
+
+		report := h.Ready(ctx)
+		assert.Equal(t, models.HealthNotReady, report.Status)
+		assert.Equal(t, health.ReasonDraining, report.Reason)
+		assert.Equal(t, models.HealthUp, report.Checks["postgres"].Status)
+	})
+}
+
+func TestPartitionLag(t *testing.T) {
+	tests := []struct {
+		name                   string
+		first, last, committed int64
+		want                   int64
+	}{
+		{name: "caught up", first: 0, last: 10, committed: 10, want: 0},
+		{name: "behind", first: 0, last: 10, committed: 4, want: 6},
+		{name: "no commit yet", first: 2, last: 10, committed: -1, want: 8},
+		{name: "committed before retention", first: 5, last: 10, committed: 3, want: 5},
+		{name: "empty partition", first: 0, last: 0, committed: -1, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, health.PartitionLag(tt.first, tt.last, tt.committed))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package healthHandler
+
+import (
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"wbL0/internal/health"
+	"wbL0/internal/models"
+)
+
+type HealthHandler struct {
+	health *health.Health
+	log    *slog.Logger
+}
+
+func NewHealthHandler(health *health.Health, log *slog.Logger) *HealthHandler {
+	return &HealthHandler{health: health, log: log}
+}
+
+// Liveness godoc
+// @Summary      Liveness probe
+// @Description  Reports that the process is running. Dependencies are not checked.
+// @Tags         health
+// @Produce      json
+// @Success      200 {object} map[string]string
+// @Router       /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness godoc
+// @Summary      Readiness probe
+// @Description  Checks Postgres, Redis and Kafka with a timeout each. Not ready while the cache warm-up runs and during shutdown.
+// @Tags         health
+// @Produce      json
+// @Success      200 {object} models.HealthReport
+// @Failure      503 {object} models.HealthReport
+// @Router       /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.health.Ready(c.Request.Context())
+	if report.Status != models.HealthReady {
+		if report.Reason == "" {
+			h.log.Warn("service is not ready", "checks", report.Checks)
+		}
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,60 @@
+package healthHandler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wbL0/internal/health"
+	"wbL0/internal/http/handler/healthHandler"
+	"wbL0/internal/models"
+)
+
+func TestReadiness_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		checkErr     error
+		expectedCode int
+		expected     models.HealthStatus
+	}{
+		{name: "ready", expectedCode: http.StatusOK, expected: models.HealthReady},
+		{name: "dependency down", checkErr: errors.New("redis down"), expectedCode: http.StatusServiceUnavailable, expected: models.HealthNotReady},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := health.New(time.Second).WithCheck("redis", func(context.Context) (map[string]any, error) {
+				return nil, tt.checkErr
+			})
+			handler := healthHandler.NewHealthHandler(h, slog.Default())
+
+			r := gin.New()
+			r.GET("/healthz", handler.Liveness)
+			r.GET("/readyz", handler.Readiness)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			var report models.HealthReport
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			assert.Equal(t, tt.expected, report.Status)
+			assert.Contains(t, report.Checks, "redis")
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			assert.Equal(t, http.StatusOK, w.Code, "liveness does not depend on dependencies")
+		})
+	}
+}
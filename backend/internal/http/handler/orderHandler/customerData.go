@@ -0,0 +1,75 @@
+package orderHandler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"wbL0/internal/models"
+)
+
+// ExportCustomerData godoc
+// @Summary      Export customer data
+// @Description  JSON archive of every order of the customer with delivery, payment and items, unmasked
+// @Tags         customers
+// @Produce      json
+// @Param        customerID  path      string  true  "customer ID"
+// @Success      200 {object} models.CustomerExport
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      500 {object} map[string]string "failed to export customer data"
+// @Failure      503 {object} map[string]string "database or cache unavailable, retry after the Retry-After header"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /customers/{customerID}/export [get]
+func (h *OrderHandler) ExportCustomerData(c *gin.Context) {
+	export, err := h.service.ExportCustomerData(c.Request.Context(), c.Param("customerID"))
+	if err != nil {
+		h.internalError(c, "failed to export customer data", err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="customer-export.json"`)
+	c.JSON(http.StatusOK, export)
+}
+
+// EraseCustomerData godoc
+// @Summary      Erase customer data
+// @Description  Anonymize the customer's personal data in Postgres and Redis, keeping payments and items for accounting.
+// @Description  The erasure is recorded in the audit log; re-ingested orders of the customer are not restored.
+// @Description  503 after the erasure was committed means Redis could not be cleaned yet; retrying the request completes it
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        customerID  path      string                 true   "customer ID"
+// @Param        request     body      models.ErasureRequest  false  "reason of the erasure"
+// @Success      200 {object} models.CustomerErasure
+// @Failure      400 {object} map[string]string "invalid request body"
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      500 {object} map[string]string "failed to erase customer data"
+// @Failure      503 {object} map[string]string "database or cache unavailable or cache eviction pending, retry the request"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /customers/{customerID}/erasure [post]
+func (h *OrderHandler) EraseCustomerData(c *gin.Context) {
+	var req models.ErasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	withAuditSource(c)
+	erasure, err := h.service.EraseCustomerData(c.Request.Context(), c.Param("customerID"), req.Reason)
+	if errors.Is(err, models.ErrEvictionPending) {
+		h.log.WarnContext(c.Request.Context(), "customer data erased but still cached", "err", err.Error())
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "customer data erased, cache eviction pending, retry the request"})
+		return
+	}
+	if err != nil {
+		h.internalError(c, "failed to erase customer data", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, erasure)
+}
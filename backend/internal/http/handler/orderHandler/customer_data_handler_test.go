@@ -0,0 +1,113 @@
+package orderHandler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"wbL0/internal/audit"
+	sht "wbL0/internal/http/handler/orderHandler"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+)
+
+func TestCustomerData_Handlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		body         io.Reader
+		mockSetup    func(srv *mocks.OrderServiceInterface)
+		expectedCode int
+	}{
+		{
+			name:   "Export - success",
+			method: http.MethodGet,
+			url:    "/customers/c1/export",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("ExportCustomerData", mock.Anything, "c1").
+					Return(&models.CustomerExport{CustomerID: "c1", Orders: []*models.FullOrder{}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "Export - service error",
+			method: http.MethodGet,
+			url:    "/customers/c1/export",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("ExportCustomerData", mock.Anything, "c1").Return(nil, errors.New("db down"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "Erase - with reason",
+			method: http.MethodPost,
+			url:    "/customers/c1/erasure",
+			body:   strings.NewReader(`{"reason":"request #1"}`),
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("EraseCustomerData", mock.MatchedBy(func(ctx context.Context) bool {
+					return audit.SourceFor(ctx, "") == "http:192.0.2.1"
+				}), "c1", "request #1").Return(&models.CustomerErasure{ID: 1}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "Erase - without body",
+			method: http.MethodPost,
+			url:    "/customers/c1/erasure",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("EraseCustomerData", mock.Anything, "c1", "").Return(&models.CustomerErasure{ID: 2}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "Erase - cache eviction pending",
+			method: http.MethodPost,
+			url:    "/customers/c1/erasure",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("EraseCustomerData", mock.Anything, "c1", "").
+					Return((*models.CustomerErasure)(nil), fmt.Errorf("%w: redis down", models.ErrEvictionPending))
+			},
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:         "Erase - invalid body",
+			method:       http.MethodPost,
+			url:          "/customers/c1/erasure",
+			body:         strings.NewReader(`{"reason":`),
+			mockSetup:    func(srv *mocks.OrderServiceInterface) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			srvMock := &mocks.OrderServiceInterface{}
+			tt.mockSetup(srvMock)
+			handler := sht.NewOrderHandler(srvMock, slog.Default())
+			router.GET("/customers/:customerID/export", handler.ExportCustomerData)
+			router.POST("/customers/:customerID/erasure", handler.EraseCustomerData)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, tt.body))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.name == "Export - success" {
+				assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+			}
+			srvMock.AssertExpectations(t)
+		})
+	}
+}
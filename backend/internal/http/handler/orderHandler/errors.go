@@ -0,0 +1,23 @@
+package orderHandler
+
+import (
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"strconv"
+	"wbL0/internal/lib/breaker"
+)
+
+// internalError answers 503 with Retry-After when a circuit breaker rejected the call, so clients back off
+// instead of piling onto a struggling dependency, and 500 otherwise.
+func (h *OrderHandler) internalError(c *gin.Context, msg string, err error, args ...any) {
+	args = append(args, "err", err.Error())
+	if retryAfter, ok := breaker.RetryAfter(err); ok {
+		h.log.WarnContext(c.Request.Context(), msg, args...)
+		c.Header("Retry-After", strconv.Itoa(max(1, int(math.Ceil(retryAfter.Seconds())))))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service temporarily unavailable"})
+		return
+	}
+	h.log.ErrorContext(c.Request.Context(), msg, args...)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+}
@@ -0,0 +1,63 @@
+package orderHandler_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	sht "wbL0/internal/http/handler/orderHandler"
+	"wbL0/internal/lib/breaker"
+	mocks "wbL0/internal/mocks"
+)
+
+func TestGetOrderInfo_BreakerOpen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+		retryAfter   string
+	}{
+		{
+			name:         "Open breaker",
+			err:          fmt.Errorf("get order: %w", &breaker.OpenError{Name: "postgres", RetryAfter: 2500 * time.Millisecond}),
+			expectedCode: http.StatusServiceUnavailable,
+			retryAfter:   "3",
+		},
+		{
+			name:         "Half-open breaker without probe slots",
+			err:          &breaker.OpenError{Name: "postgres"},
+			expectedCode: http.StatusServiceUnavailable,
+			retryAfter:   "1",
+		},
+		{
+			name:         "Other error",
+			err:          errors.New("boom"),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srvMock := &mocks.OrderServiceInterface{}
+			srvMock.On("GetOrder", mock.Anything, "o1").Return(nil, tt.err)
+			router := gin.New()
+			router.GET("/order/:orderUID", sht.NewOrderHandler(srvMock, slog.Default()).GetOrderInfo)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/order/o1", nil))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+			srvMock.AssertExpectations(t)
+		})
+	}
+}
@@ -6,19 +6,29 @@ import (
 	"github.com/gin-gonic/gin"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 	"wbL0/internal/models"
+	"wbL0/internal/redact"
 	"wbL0/internal/service/orderService"
 )
 
 type OrderHandler struct {
-	service orderService.OrderServiceInterface
-	log     *slog.Logger
+	service   orderService.OrderServiceInterface
+	log       *slog.Logger
+	redaction *redact.Policy
 }
 
 func NewOrderHandler(service orderService.OrderServiceInterface, log *slog.Logger) *OrderHandler {
 	return &OrderHandler{service: service, log: log}
 }
 
+// WithRedaction masks personal data in responses according to the caller role.
+func (h *OrderHandler) WithRedaction(policy *redact.Policy) *OrderHandler {
+	h.redaction = policy
+	return h
+}
+
 // GetOrderInfo godoc
 // @Summary      Get information about order
 // @Description  Get full information about order to UID
@@ -28,7 +38,13 @@ func NewOrderHandler(service orderService.OrderServiceInterface, log *slog.Logge
 // @Param        orderUID   path      string  true  "order UID"
 // @Success      200 {object} models.OrderResponse
 // @Failure      400 {object} map[string]string "orderUID param is empty"
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      404 {object} map[string]string "order not found"
 // @Failure      500 {object} map[string]string "failed to get order"
+// @Failure      503 {object} map[string]string "database or cache unavailable, retry after the Retry-After header"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /order/{orderUID} [get]
 func (h *OrderHandler) GetOrderInfo(c *gin.Context) {
 	orderUID := c.Param("orderUID")
@@ -42,15 +58,24 @@ func (h *OrderHandler) GetOrderInfo(c *gin.Context) {
 	fo, err := h.service.GetOrder(ctx, orderUID)
 	if err != nil {
 		if errors.Is(err, models.ErrOrderNotFound) {
-			h.log.Error("failed to get order", "orderUID", orderUID, "err", err.Error())
+			h.log.InfoContext(ctx, "order not found", "orderUID", orderUID)
 			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
 			return
 		}
-		h.log.Error("failed to get order", "orderUID", orderUID, "err", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		h.internalError(c, "failed to get order", err, "orderUID", orderUID)
 		return
 	}
 
+	c.JSON(http.StatusOK, h.orderResponse(c, fo))
+}
+
+func (h *OrderHandler) orderResponse(c *gin.Context, fo *models.FullOrder) models.OrderResponse {
+	response := toOrderResponse(fo)
+	h.redaction.OrderResponse(c.Request.Context(), &response)
+	return response
+}
+
+func toOrderResponse(fo *models.FullOrder) models.OrderResponse {
 	response := models.OrderResponse{
 		OrderUID:          fo.Order.OrderUID,
 		TrackNumber:       fo.Order.TrackNumber,
@@ -102,5 +127,121 @@ func (h *OrderHandler) GetOrderInfo(c *gin.Context) {
 		})
 	}
 
+	return response
+}
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// ListOrders godoc
+// @Summary      List orders
+// @Description  Search orders with filters and cursor pagination, sorted by date_created
+// @Tags         orders
+// @Accept       json
+// @Produce      json
+// @Param        customer_id       query     string  false  "customer ID"
+// @Param        track_number      query     string  false  "track number"
+// @Param        delivery_service  query     string  false  "delivery service"
+// @Param        email             query     string  false  "delivery email, case-insensitive exact match"
+// @Param        phone             query     string  false  "delivery phone, digits are compared"
+// @Param        date_from         query     string  false  "created at or after, RFC3339"
+// @Param        date_to           query     string  false  "created before, RFC3339"
+// @Param        currency          query     string  false  "payment currency"
+// @Param        provider          query     string  false  "payment provider"
+// @Param        bank              query     string  false  "payment bank"
+// @Param        brand             query     string  false  "item brand"
+// @Param        nm_id             query     int     false  "item nm_id"
+// @Param        sort              query     string  false  "date_created order"  Enums(desc, asc)
+// @Param        limit             query     int     false  "page size, max 100"  default(20)
+// @Param        cursor            query     string  false  "next_cursor from the previous page"
+// @Success      200 {object} models.OrderListResponse
+// @Failure      400 {object} map[string]string "invalid query parameter"
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      500 {object} map[string]string "failed to list orders"
+// @Failure      503 {object} map[string]string "database or cache unavailable, retry after the Retry-After header"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders [get]
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	filter, err := parseOrderFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.service.ListOrders(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": h.redaction.Scrub(err.Error())})
+			return
+		}
+		h.internalError(c, "failed to list orders", err)
+		return
+	}
+
+	response := models.OrderListResponse{Orders: page.Orders, NextCursor: page.NextCursor}
+	if response.Orders == nil {
+		response.Orders = []models.OrderSummary{}
+	}
+	h.redaction.OrderSummaries(c.Request.Context(), response.Orders)
 	c.JSON(http.StatusOK, response)
 }
+
+func parseOrderFilter(c *gin.Context) (models.OrderFilter, error) {
+	filter := models.OrderFilter{
+		CustomerID:      c.Query("customer_id"),
+		TrackNumber:     c.Query("track_number"),
+		DeliveryService: c.Query("delivery_service"),
+		Email:           c.Query("email"),
+		Phone:           c.Query("phone"),
+		Currency:        c.Query("currency"),
+		Provider:        c.Query("provider"),
+		Bank:            c.Query("bank"),
+		Brand:           c.Query("brand"),
+		Sort:            c.DefaultQuery("sort", models.SortDesc),
+		Limit:           defaultListLimit,
+	}
+
+	if filter.Sort != models.SortDesc && filter.Sort != models.SortAsc {
+		return filter, errors.New("sort must be asc or desc")
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+		}
+		filter.Limit = limit
+	}
+	if v := c.Query("nm_id"); v != "" {
+		nmID, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, errors.New("nm_id must be an integer")
+		}
+		filter.NmID = nmID
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"date_from", &filter.DateFrom}, {"date_to", &filter.DateTo}} {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("%s must be an RFC3339 timestamp", p.name)
+		}
+		*p.dst = &t
+	}
+	if v := c.Query("cursor"); v != "" {
+		cursor, err := models.DecodeOrderCursor(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Cursor = cursor
+	}
+	return filter, nil
+}
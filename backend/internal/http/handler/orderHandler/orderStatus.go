@@ -0,0 +1,88 @@
+package orderHandler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"wbL0/internal/models"
+)
+
+// ChangeOrderStatus godoc
+// @Summary      Change order status
+// @Description  Move the order to a new status, only transitions from the transition table are allowed
+// @Tags         orders
+// @Accept       json
+// @Produce      json
+// @Param        orderUID  path      string                      true  "order UID"
+// @Param        status    body      models.StatusChangeRequest  true  "new status"
+// @Success      200 {object} models.StatusChange
+// @Failure      400 {object} map[string]string "invalid status"
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      404 {object} map[string]string "order not found"
+// @Failure      409 {object} map[string]string "invalid status transition"
+// @Failure      500 {object} map[string]string "failed to change status"
+// @Failure      503 {object} map[string]string "database or cache unavailable, retry after the Retry-After header"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /order/{orderUID}/status [patch]
+func (h *OrderHandler) ChangeOrderStatus(c *gin.Context) {
+	orderUID := c.Param("orderUID")
+
+	var req models.StatusChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	status, err := models.ParseOrderStatus(req.Status)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": h.redaction.Scrub(err.Error())})
+		return
+	}
+
+	change, err := h.service.ChangeOrderStatus(c.Request.Context(), orderUID, status, req.Reason, requestSource(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		case errors.Is(err, models.ErrInvalidTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": h.redaction.Scrub(err.Error())})
+		default:
+			h.internalError(c, "failed to change order status", err, "orderUID", orderUID)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// GetOrderHistory godoc
+// @Summary      Get order status history
+// @Description  Current order status and all status changes, oldest first
+// @Tags         orders
+// @Produce      json
+// @Param        orderUID  path      string  true  "order UID"
+// @Success      200 {object} models.OrderStatusHistory
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      404 {object} map[string]string "order not found"
+// @Failure      500 {object} map[string]string "failed to get history"
+// @Failure      503 {object} map[string]string "database or cache unavailable, retry after the Retry-After header"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /order/{orderUID}/history [get]
+func (h *OrderHandler) GetOrderHistory(c *gin.Context) {
+	orderUID := c.Param("orderUID")
+
+	history, err := h.service.GetOrderStatusHistory(c.Request.Context(), orderUID)
+	if err != nil {
+		if errors.Is(err, models.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		h.internalError(c, "failed to get order history", err, "orderUID", orderUID)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
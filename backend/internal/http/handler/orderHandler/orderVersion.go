@@ -0,0 +1,141 @@
+package orderHandler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"wbL0/internal/audit"
+	"wbL0/internal/auth"
+	"wbL0/internal/models"
+)
+
+// ListOrderVersions godoc
+// @Summary      List order versions
+// @Description  Audit trail of the order without snapshots, oldest first. Deleted orders keep their versions
+// @Tags         orders
+// @Produce      json
+// @Param        orderUID  path      string  true  "order UID"
+// @Success      200 {array}  models.OrderVersion
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      404 {object} map[string]string "order not found"
+// @Failure      500 {object} map[string]string "failed to list versions"
+// @Failure      503 {object} map[string]string "database or cache unavailable, retry after the Retry-After header"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /order/{orderUID}/versions [get]
+func (h *OrderHandler) ListOrderVersions(c *gin.Context) {
+	orderUID := c.Param("orderUID")
+
+	versions, err := h.service.ListOrderVersions(c.Request.Context(), orderUID)
+	if err != nil {
+		h.writeVersionError(c, orderUID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetOrderVersion godoc
+// @Summary      Get order version
+// @Description  One version of the order with the previous and the new snapshot
+// @Tags         orders
+// @Produce      json
+// @Param        orderUID  path      string  true  "order UID"
+// @Param        version   path      int     true  "version number"
+// @Success      200 {object} models.OrderVersion
+// @Failure      400 {object} map[string]string "invalid version"
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      404 {object} map[string]string "version not found"
+// @Failure      500 {object} map[string]string "failed to get version"
+// @Failure      503 {object} map[string]string "database or cache unavailable, retry after the Retry-After header"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /order/{orderUID}/versions/{version} [get]
+func (h *OrderHandler) GetOrderVersion(c *gin.Context) {
+	orderUID := c.Param("orderUID")
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version must be a positive integer"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	v, err := h.service.GetOrderVersion(ctx, orderUID, version)
+	if err != nil {
+		h.writeVersionError(c, orderUID, err)
+		return
+	}
+	if v.Previous, err = h.redaction.Snapshot(ctx, v.Previous); err == nil {
+		v.Current, err = h.redaction.Snapshot(ctx, v.Current)
+	}
+	if err != nil {
+		h.writeVersionError(c, orderUID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// DiffOrderVersions godoc
+// @Summary      Diff order versions
+// @Description  Fields that differ between the order after version "from" and after version "to"
+// @Tags         orders
+// @Produce      json
+// @Param        orderUID  path      string  true  "order UID"
+// @Param        from      query     int     true  "base version"
+// @Param        to        query     int     true  "compared version"
+// @Success      200 {object} models.OrderDiff
+// @Failure      400 {object} map[string]string "invalid version"
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      404 {object} map[string]string "version not found"
+// @Failure      500 {object} map[string]string "failed to diff versions"
+// @Failure      503 {object} map[string]string "database or cache unavailable, retry after the Retry-After header"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /order/{orderUID}/diff [get]
+func (h *OrderHandler) DiffOrderVersions(c *gin.Context) {
+	orderUID := c.Param("orderUID")
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil || from <= 0 || to <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be positive integers"})
+		return
+	}
+
+	diff, err := h.service.DiffOrderVersions(c.Request.Context(), orderUID, from, to)
+	if err != nil {
+		h.writeVersionError(c, orderUID, err)
+		return
+	}
+
+	diff.Changes = h.redaction.Changes(c.Request.Context(), diff.Changes)
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *OrderHandler) writeVersionError(c *gin.Context, orderUID string, err error) {
+	switch {
+	case errors.Is(err, models.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+	case errors.Is(err, models.ErrVersionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "order version not found"})
+	default:
+		h.internalError(c, "failed to read order versions", err, "orderUID", orderUID)
+	}
+}
+
+// withAuditSource tags writes made while serving the request with the HTTP caller.
+func withAuditSource(c *gin.Context) {
+	c.Request = c.Request.WithContext(audit.WithSource(c.Request.Context(), requestSource(c)))
+}
+
+// requestSource names the authenticated caller, or the client address when authentication is disabled.
+func requestSource(c *gin.Context) string {
+	if p := auth.PrincipalFrom(c.Request.Context()); p != nil && p.Subject != "" {
+		return "http:" + p.Subject
+	}
+	return "http:" + c.ClientIP()
+}
@@ -0,0 +1,129 @@
+package orderHandler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"wbL0/internal/models"
+	"wbL0/internal/validation"
+)
+
+// CreateOrder godoc
+// @Summary      Create order
+// @Description  Validate and store a new order, the body has the same format as Kafka messages
+// @Tags         orders
+// @Accept       json
+// @Produce      json
+// @Param        order  body      models.FullOrder  true  "order"
+// @Success      201 {object} models.OrderResponse
+// @Failure      400 {object} map[string]interface{} "invalid order"
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      409 {object} map[string]string "order already exists"
+// @Failure      500 {object} map[string]string "failed to create order"
+// @Failure      503 {object} map[string]string "database or cache unavailable, retry after the Retry-After header"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /order [post]
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	var fo models.FullOrder
+	if err := c.ShouldBindJSON(&fo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	withAuditSource(c)
+	if err := h.service.CreateOrder(c.Request.Context(), &fo); err != nil {
+		h.writeSaveError(c, fo.Order.OrderUID, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, h.orderResponse(c, &fo))
+}
+
+// UpdateOrder godoc
+// @Summary      Update order
+// @Description  Replace an existing order including delivery, payment and items
+// @Tags         orders
+// @Accept       json
+// @Produce      json
+// @Param        orderUID  path      string            true  "order UID"
+// @Param        order     body      models.FullOrder  true  "order"
+// @Success      200 {object} models.OrderResponse
+// @Failure      400 {object} map[string]interface{} "invalid order"
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      404 {object} map[string]string "order not found"
+// @Failure      500 {object} map[string]string "failed to update order"
+// @Failure      503 {object} map[string]string "database or cache unavailable, retry after the Retry-After header"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /order/{orderUID} [put]
+func (h *OrderHandler) UpdateOrder(c *gin.Context) {
+	orderUID := c.Param("orderUID")
+
+	var fo models.FullOrder
+	if err := c.ShouldBindJSON(&fo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if fo.Order.OrderUID == "" {
+		fo.Order.OrderUID = orderUID
+	}
+	if fo.Order.OrderUID != orderUID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order_uid in body does not match path"})
+		return
+	}
+
+	withAuditSource(c)
+	if err := h.service.UpdateOrder(c.Request.Context(), &fo); err != nil {
+		h.writeSaveError(c, orderUID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.orderResponse(c, &fo))
+}
+
+// DeleteOrder godoc
+// @Summary      Delete order
+// @Description  Delete order with its delivery, payment and items and evict it from the cache
+// @Tags         orders
+// @Param        orderUID  path  string  true  "order UID"
+// @Success      204
+// @Failure      401 {object} map[string]string "unauthorized"
+// @Failure      403 {object} map[string]string "forbidden"
+// @Failure      404 {object} map[string]string "order not found"
+// @Failure      500 {object} map[string]string "failed to delete order"
+// @Failure      503 {object} map[string]string "database or cache unavailable, retry after the Retry-After header"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /order/{orderUID} [delete]
+func (h *OrderHandler) DeleteOrder(c *gin.Context) {
+	orderUID := c.Param("orderUID")
+
+	withAuditSource(c)
+	if err := h.service.DeleteOrder(c.Request.Context(), orderUID); err != nil {
+		if errors.Is(err, models.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		h.internalError(c, "failed to delete order", err, "orderUID", orderUID)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OrderHandler) writeSaveError(c *gin.Context, orderUID string, err error) {
+	var verr *validation.Error
+	switch {
+	case errors.As(err, &verr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidInput.Error(), "fields": verr.Fields})
+	case errors.Is(err, models.ErrOrderExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "order already exists"})
+	case errors.Is(err, models.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+	default:
+		h.internalError(c, "failed to save order", err, "orderUID", orderUID)
+	}
+}
@@ -111,3 +111,70 @@ func TestGetOrderInfo_Handler(t *testing.T) {
 		})
 	}
 }
+
+func TestListOrders_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		query        string
+		mockSetup    func(srv *mocks.OrderServiceInterface)
+		expectedCode int
+	}{
+		{
+			name:  "Success - filters are passed to service",
+			query: "?customer_id=c1&nm_id=42&date_from=2025-01-01T00:00:00Z&limit=10",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("ListOrders", mock.Anything, mock.MatchedBy(func(f models.OrderFilter) bool {
+					return f.CustomerID == "c1" && f.NmID == 42 && f.DateFrom != nil && f.Limit == 10 && f.Sort == models.SortDesc
+				})).Return(&models.OrderPage{Orders: []models.OrderSummary{{OrderUID: "o1"}}, NextCursor: "next"}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid limit",
+			query:        "?limit=1000",
+			mockSetup:    func(srv *mocks.OrderServiceInterface) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Invalid cursor",
+			query:        "?cursor=not-a-cursor",
+			mockSetup:    func(srv *mocks.OrderServiceInterface) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "Internal server error",
+			query: "",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("ListOrders", mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			srvMock := &mocks.OrderServiceInterface{}
+			tt.mockSetup(srvMock)
+			handler := sht.NewOrderHandler(srvMock, slog.Default())
+			router.GET("/orders", handler.ListOrders)
+
+			req := httptest.NewRequest(http.MethodGet, "/orders"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				var resp models.OrderListResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Len(t, resp.Orders, 1)
+				assert.Equal(t, "next", resp.NextCursor)
+			}
+
+			srvMock.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package orderHandler_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"wbL0/internal/auth"
+	"wbL0/internal/config"
+	sht "wbL0/internal/http/handler/orderHandler"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	"wbL0/internal/redact"
+)
+
+func TestGetOrderInfo_RedactsByRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policy, err := redact.NewPolicy(config.RedactionConfig{
+		Default: config.RedactionRules{Mask: []string{"delivery.phone", "delivery.email"}},
+		Roles: map[string]config.RedactionRules{
+			"admin":     {},
+			"analytics": {Drop: []string{"delivery.phone", "delivery.email", "order.customer_id"}},
+		},
+	})
+	require.NoError(t, err)
+
+	fo := &models.FullOrder{
+		Order:    models.Order{OrderUID: "r1", CustomerID: "customer"},
+		Delivery: models.Delivery{Phone: "+79001234567", Email: "john@mail.ru"},
+	}
+
+	tests := []struct {
+		name     string
+		role     auth.Role
+		expected models.DeliveryDTO
+		customer string
+	}{
+		{name: "no role", expected: models.DeliveryDTO{Phone: "+7***4567", Email: "j***@mail.ru", Zip: "0"}, customer: "customer"},
+		{name: "admin", role: auth.RoleAdmin, expected: models.DeliveryDTO{Phone: "+79001234567", Email: "john@mail.ru", Zip: "0"}, customer: "customer"},
+		{name: "analytics", role: auth.RoleAnalytics, expected: models.DeliveryDTO{Zip: "0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srvMock := &mocks.OrderServiceInterface{}
+			srvMock.On("GetOrder", mock.Anything, "r1").Return(fo, nil)
+			handler := sht.NewOrderHandler(srvMock, slog.Default()).WithRedaction(policy)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.role != "" {
+					c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), &auth.Principal{Role: tt.role}))
+				}
+			})
+			router.GET("/order/:orderUID", handler.GetOrderInfo)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/order/r1", nil))
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var resp models.OrderResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expected, resp.Delivery)
+			assert.Equal(t, tt.customer, resp.CustomerID)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package orderHandler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"wbL0/internal/audit"
+	sht "wbL0/internal/http/handler/orderHandler"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+)
+
+func TestOrderVersion_Handlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		url          string
+		mockSetup    func(srv *mocks.OrderServiceInterface)
+		expectedCode int
+	}{
+		{
+			name: "List - success",
+			url:  "/order/v1/versions",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("ListOrderVersions", mock.Anything, "v1").Return([]models.OrderVersion{{OrderUID: "v1", Version: 1}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "List - not found",
+			url:  "/order/missing/versions",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("ListOrderVersions", mock.Anything, "missing").Return(nil, models.ErrOrderNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "Get - success",
+			url:  "/order/v1/versions/2",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("GetOrderVersion", mock.Anything, "v1", 2).Return(&models.OrderVersion{OrderUID: "v1", Version: 2}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Get - version not found",
+			url:  "/order/v1/versions/9",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("GetOrderVersion", mock.Anything, "v1", 9).Return(nil, models.ErrVersionNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Get - invalid version",
+			url:          "/order/v1/versions/abc",
+			mockSetup:    func(srv *mocks.OrderServiceInterface) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Diff - success",
+			url:  "/order/v1/diff?from=1&to=2",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("DiffOrderVersions", mock.Anything, "v1", 1, 2).Return(&models.OrderDiff{OrderUID: "v1", From: 1, To: 2}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Diff - missing bounds",
+			url:          "/order/v1/diff?from=1",
+			mockSetup:    func(srv *mocks.OrderServiceInterface) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			srvMock := &mocks.OrderServiceInterface{}
+			tt.mockSetup(srvMock)
+			handler := sht.NewOrderHandler(srvMock, slog.Default())
+			router.GET("/order/:orderUID/versions", handler.ListOrderVersions)
+			router.GET("/order/:orderUID/versions/:version", handler.GetOrderVersion)
+			router.GET("/order/:orderUID/diff", handler.DiffOrderVersions)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			srvMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWriteOrder_RecordsHTTPSource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	srvMock := &mocks.OrderServiceInterface{}
+	srvMock.On("CreateOrder", mock.MatchedBy(func(ctx context.Context) bool {
+		return audit.SourceFor(ctx, "src1") == "http:192.0.2.1"
+	}), mock.Anything).Return(nil)
+
+	router := gin.New()
+	router.POST("/order", sht.NewOrderHandler(srvMock, slog.Default()).CreateOrder)
+
+	body, _ := json.Marshal(models.FullOrder{Order: models.Order{OrderUID: "src1"}})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/order", bytes.NewReader(body)))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	srvMock.AssertExpectations(t)
+}
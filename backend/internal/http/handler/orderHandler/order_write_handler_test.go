@@ -0,0 +1,217 @@
+package orderHandler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	sht "wbL0/internal/http/handler/orderHandler"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	"wbL0/internal/validation"
+)
+
+func TestWriteOrder_Handlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := func(uid string) []byte {
+		b, _ := json.Marshal(models.FullOrder{Order: models.Order{OrderUID: uid}})
+		return b
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		body         []byte
+		mockSetup    func(srv *mocks.OrderServiceInterface)
+		expectedCode int
+	}{
+		{
+			name:   "Create - success",
+			method: http.MethodPost,
+			url:    "/order",
+			body:   body("new1"),
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("CreateOrder", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "Create - already exists",
+			method: http.MethodPost,
+			url:    "/order",
+			body:   body("dup1"),
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("CreateOrder", mock.Anything, mock.Anything).Return(models.ErrOrderExists)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "Create - validation failed",
+			method: http.MethodPost,
+			url:    "/order",
+			body:   body("bad1"),
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("CreateOrder", mock.Anything, mock.Anything).
+					Return(&validation.Error{Fields: []validation.FieldError{{Field: "delivery.email", Message: "must be a valid email address"}}})
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Create - malformed body",
+			method:       http.MethodPost,
+			url:          "/order",
+			body:         []byte("{"),
+			mockSetup:    func(srv *mocks.OrderServiceInterface) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Update - success",
+			method: http.MethodPut,
+			url:    "/order/upd1",
+			body:   body(""),
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("UpdateOrder", mock.Anything, mock.MatchedBy(func(fo *models.FullOrder) bool {
+					return fo.Order.OrderUID == "upd1"
+				})).Return(nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Update - uid mismatch",
+			method:       http.MethodPut,
+			url:          "/order/upd1",
+			body:         body("other"),
+			mockSetup:    func(srv *mocks.OrderServiceInterface) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Update - not found",
+			method: http.MethodPut,
+			url:    "/order/missing",
+			body:   body("missing"),
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("UpdateOrder", mock.Anything, mock.Anything).Return(models.ErrOrderNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "Delete - success",
+			method: http.MethodDelete,
+			url:    "/order/del1",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("DeleteOrder", mock.Anything, "del1").Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:   "Delete - not found",
+			method: http.MethodDelete,
+			url:    "/order/missing",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("DeleteOrder", mock.Anything, "missing").Return(models.ErrOrderNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "Delete - internal error",
+			method: http.MethodDelete,
+			url:    "/order/err1",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("DeleteOrder", mock.Anything, "err1").Return(errors.New("db down"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "Status - success",
+			method: http.MethodPatch,
+			url:    "/order/st1/status",
+			body:   []byte(`{"status":"paid","reason":"payment confirmed"}`),
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("ChangeOrderStatus", mock.Anything, "st1", models.StatusPaid, "payment confirmed", "http:192.0.2.1").
+					Return(&models.StatusChange{OrderUID: "st1", From: models.StatusCreated, To: models.StatusPaid}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Status - unknown status",
+			method:       http.MethodPatch,
+			url:          "/order/st1/status",
+			body:         []byte(`{"status":"lost"}`),
+			mockSetup:    func(srv *mocks.OrderServiceInterface) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Status - illegal transition",
+			method: http.MethodPatch,
+			url:    "/order/st1/status",
+			body:   []byte(`{"status":"delivered"}`),
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("ChangeOrderStatus", mock.Anything, "st1", models.StatusDelivered, "", "http:192.0.2.1").
+					Return(nil, models.ErrInvalidTransition)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "Status - not found",
+			method: http.MethodPatch,
+			url:    "/order/missing/status",
+			body:   []byte(`{"status":"paid"}`),
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("ChangeOrderStatus", mock.Anything, "missing", models.StatusPaid, "", "http:192.0.2.1").
+					Return(nil, models.ErrOrderNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "History - success",
+			method: http.MethodGet,
+			url:    "/order/st1/history",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("GetOrderStatusHistory", mock.Anything, "st1").
+					Return(&models.OrderStatusHistory{OrderUID: "st1", Status: models.StatusPaid}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "History - not found",
+			method: http.MethodGet,
+			url:    "/order/missing/history",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("GetOrderStatusHistory", mock.Anything, "missing").Return(nil, models.ErrOrderNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			srvMock := &mocks.OrderServiceInterface{}
+			tt.mockSetup(srvMock)
+			handler := sht.NewOrderHandler(srvMock, slog.Default())
+			router.POST("/order", handler.CreateOrder)
+			router.PUT("/order/:orderUID", handler.UpdateOrder)
+			router.DELETE("/order/:orderUID", handler.DeleteOrder)
+			router.PATCH("/order/:orderUID/status", handler.ChangeOrderStatus)
+			router.GET("/order/:orderUID/history", handler.GetOrderHistory)
+
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			srvMock.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"slices"
+	"wbL0/internal/auth"
+	"wbL0/internal/models"
+)
+
+// AuthMiddleware authenticates the request and stores the caller in the request context.
+// Requests without valid credentials are rejected with 401.
+func AuthMiddleware(authn auth.Authenticator, log *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, err := authn.Authenticate(c.Request)
+		if err != nil {
+			log.Warn("request rejected", "path", c.FullPath(), "client_ip", c.ClientIP(), "err", err.Error())
+			c.Header("WWW-Authenticate", `Bearer realm="wbL0"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": models.ErrUnauthorized.Error()})
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
+
+// RequireRole lets the request through only when the caller has one of the roles, otherwise it answers 403.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := auth.PrincipalFrom(c.Request.Context())
+		if p == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": models.ErrUnauthorized.Error()})
+			return
+		}
+		if !slices.Contains(roles, p.Role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": models.ErrForbidden.Error()})
+			return
+		}
+		c.Next()
+	}
+}
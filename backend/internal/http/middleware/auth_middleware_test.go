@@ -0,0 +1,62 @@
+package middleware_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wbL0/internal/auth"
+	"wbL0/internal/config"
+	"wbL0/internal/http/middleware"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authn, err := auth.New(config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "support", Key: "support-key", Role: "support"},
+			{Name: "bi", Key: "analytics-key", Role: "analytics"},
+		},
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(authn, slog.Default()))
+	router.GET("/history", middleware.RequireRole(auth.RoleSupport, auth.RoleAdmin), func(c *gin.Context) {
+		c.String(http.StatusOK, auth.PrincipalFrom(c.Request.Context()).Subject)
+	})
+
+	tests := []struct {
+		name         string
+		key          string
+		expectedCode int
+	}{
+		{name: "no credentials", expectedCode: http.StatusUnauthorized},
+		{name: "unknown key", key: "nope", expectedCode: http.StatusUnauthorized},
+		{name: "role not allowed", key: "analytics-key", expectedCode: http.StatusForbidden},
+		{name: "allowed", key: "support-key", expectedCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/history", nil)
+			if tt.key != "" {
+				req.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				assert.Equal(t, "support", w.Body.String())
+			}
+		})
+	}
+}
@@ -2,12 +2,47 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"log/slog"
+	"wbL0/internal/auth"
 	"wbL0/internal/http/handler/orderHandler"
+	"wbL0/internal/http/middleware"
 )
 
-func InitRoutes(r *gin.Engine, orderHandler orderHandler.OrderHandler) {
-	orderGroup := r.Group("/order")
+// InitRoutes registers the order API. With a nil authn the API is open, otherwise every route
+// requires authentication and one of the listed roles.
+func InitRoutes(r *gin.Engine, orderHandler orderHandler.OrderHandler, authn auth.Authenticator, log *slog.Logger) {
+	allow := func(roles ...auth.Role) gin.HandlerFunc {
+		if authn == nil {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return middleware.RequireRole(roles...)
+	}
+	readers := allow(auth.RoleSupport, auth.RoleAnalytics, auth.RoleAdmin)
+	support := allow(auth.RoleSupport, auth.RoleAdmin)
+	admin := allow(auth.RoleAdmin)
+
+	api := r.Group("")
+	if authn != nil {
+		api.Use(middleware.AuthMiddleware(authn, log))
+	}
+
+	orderGroup := api.Group("/order")
+	{
+		orderGroup.POST("", admin, orderHandler.CreateOrder)
+		orderGroup.GET("/:orderUID", readers, orderHandler.GetOrderInfo)
+		orderGroup.PUT("/:orderUID", admin, orderHandler.UpdateOrder)
+		orderGroup.DELETE("/:orderUID", admin, orderHandler.DeleteOrder)
+		orderGroup.PATCH("/:orderUID/status", support, orderHandler.ChangeOrderStatus)
+		orderGroup.GET("/:orderUID/history", support, orderHandler.GetOrderHistory)
+		orderGroup.GET("/:orderUID/versions", support, orderHandler.ListOrderVersions)
+		orderGroup.GET("/:orderUID/versions/:version", support, orderHandler.GetOrderVersion)
+		orderGroup.GET("/:orderUID/diff", support, orderHandler.DiffOrderVersions)
+	}
+	api.GET("/orders", readers, orderHandler.ListOrders)
+
+	customerGroup := api.Group("/customers")
 	{
-		orderGroup.GET("/:orderUID", orderHandler.GetOrderInfo)
+		customerGroup.GET("/:customerID/export", admin, orderHandler.ExportCustomerData)
+		customerGroup.POST("/:customerID/erasure", admin, orderHandler.EraseCustomerData)
 	}
 }
@@ -4,19 +4,24 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/segmentio/kafka-go"
+	"hash/fnv"
 	"log/slog"
-	"math"
-	"math/rand"
+	"strconv"
+	"sync"
 	"time"
+	"wbL0/internal/audit"
 	"wbL0/internal/config"
+	"wbL0/internal/kafka/dlq"
+	"wbL0/internal/lib/backoff"
+	"wbL0/internal/metrics"
 	"wbL0/internal/models"
+	"wbL0/internal/repository/postgres/orderRepoPostgres"
 	"wbL0/internal/service/orderService"
+	"wbL0/internal/tracing"
+	"wbL0/internal/validation"
 )
 
-var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
-
 const (
 	maxProcessAttempts = 5
 	maxCommitAttempts  = 3
@@ -25,20 +30,38 @@ const (
 	maxBackoff  = 10 * time.Second
 
 	readErrorBackoff = 1 * time.Second
+
+	defaultWorkers    = 1
+	defaultQueueDepth = 100
+
+	defaultBatchTimeout = 200 * time.Millisecond
 )
 
-func calcBackoff(attempt int) time.Duration {
-	backoff := float64(baseBackoff) * math.Pow(2, float64(attempt-1))
-	if time.Duration(backoff) > maxBackoff {
-		backoff = float64(maxBackoff)
-	}
-	jitter := rng.Float64() * (backoff / 4)
-	return time.Duration(backoff + jitter)
+type job struct {
+	msg   kafka.Message
+	order *models.FullOrder
 }
 
-func ConsumeMessage(ctx context.Context, cfg *config.Config, svc orderService.OrderServiceInterface, log *slog.Logger) error {
-	const op = "kafka.consumeMessage"
+// deadLetterPublisher is implemented by *dlq.Publisher.
+type deadLetterPublisher interface {
+	Publish(ctx context.Context, msg kafka.Message, reason string, cause error, attempts int) error
+}
+
+type consumer struct {
+	reader  *kafka.Reader
+	svc     orderService.OrderServiceInterface
+	log     *slog.Logger
+	dlq     deadLetterPublisher
+	tracker *offsetTracker
+	done    chan kafka.Message
+	// pauseBackoff is the first wait while Postgres is unavailable.
+	pauseBackoff time.Duration
+}
 
+// ConsumeMessage reads orders from Kafka and processes them on cfg.Kafka.Workers workers.
+// Messages are routed by order_uid, so updates of one order are applied in order, and offsets
+// are committed only up to the highest offset below which every message has been handled.
+func ConsumeMessage(ctx context.Context, cfg *config.Config, svc orderService.OrderServiceInterface, log *slog.Logger) error {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:        cfg.Kafka.Brokers,
 		Topic:          cfg.Kafka.Topic,
@@ -54,84 +77,415 @@ func ConsumeMessage(ctx context.Context, cfg *config.Config, svc orderService.Or
 		}
 	}()
 
+	var deadLetters deadLetterPublisher
+	dlqPublisher := dlq.NewPublisher(cfg, log)
+	if dlqPublisher != nil {
+		deadLetters = dlqPublisher
+		defer func() {
+			if err := dlqPublisher.Close(); err != nil {
+				log.Warn("kafka dlq writer close error", "err", err)
+			}
+		}()
+	}
+
+	workers := cfg.Kafka.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	queueDepth := cfg.Kafka.QueueDepth
+	if queueDepth <= 0 {
+		queueDepth = defaultQueueDepth
+	}
+
+	c := &consumer{
+		reader:  reader,
+		svc:     svc,
+		log:     log,
+		dlq:     deadLetters,
+		tracker: newOffsetTracker(),
+		done:    make(chan kafka.Message, workers*queueDepth),
+
+		pauseBackoff: baseBackoff,
+	}
+
+	if cfg.Kafka.BatchSize > 1 {
+		batchTimeout := cfg.Kafka.BatchTimeout
+		if batchTimeout <= 0 {
+			batchTimeout = defaultBatchTimeout
+		}
+		return c.consumeBatches(ctx, cfg.Kafka.BatchSize, batchTimeout)
+	}
+
+	queues := make([]chan job, workers)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan job, queueDepth)
+		wg.Add(1)
+		go func(queue <-chan job) {
+			defer wg.Done()
+			for j := range queue {
+				if c.handle(ctx, j) {
+					c.done <- j.msg
+				}
+			}
+		}(queues[i])
+	}
+
+	committed := make(chan struct{})
+	go func() {
+		defer close(committed)
+		c.commitLoop(context.WithoutCancel(ctx))
+	}()
+
+	err := c.dispatch(ctx, queues)
+
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+	close(c.done)
+	<-committed
+	return err
+}
+
+// dispatch fetches messages and hands them to the worker owning the order UID.
+// Messages that cannot be decoded are dead-lettered right away.
+func (c *consumer) dispatch(ctx context.Context, queues []chan job) error {
+	const op = "kafka.consumeMessage"
+
 	for {
-		msg, err := reader.ReadMessage(ctx)
+		msg, err := c.reader.FetchMessage(ctx)
 		if err != nil {
 			if errors.Is(err, context.Canceled) || errors.Is(err, ctx.Err()) {
-				log.Info("consumer context canceled")
+				c.log.Info("consumer context canceled")
 				return nil
 			}
-			log.Error("error reading kafka message", "op", op, "err", err, "error_str", err.Error())
+			c.log.Error("error reading kafka message", "op", op, "err", err, "error_str", err.Error())
 
 			select {
 			case <-time.After(readErrorBackoff):
 				continue
 			case <-ctx.Done():
-				return ctx.Err()
+				return nil
 			}
 		}
+		c.tracker.track(msg)
+		recordFetch(msg)
 
-		var full models.FullOrder
-		if err := json.Unmarshal(msg.Value, &full); err != nil {
-			log.Error("invalid message format, skipping", "op", op, "err", err, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+		full, err := decode(msg)
+		if err != nil {
+			c.log.Error("invalid message format, skipping", "op", op, "err", err, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+			if !c.deadLetter(ctx, msg, dlq.ReasonDecodeFailed, err, 1) {
+				c.log.Info("consumer context canceled")
+				return nil
+			}
+			c.done <- msg
 			continue
 		}
 
-		var procErr error
-		for attempt := 1; attempt <= maxProcessAttempts; attempt++ {
-			if errors.Is(ctx.Err(), context.Canceled) {
-				log.Info("context canceled while processing", "order_uid", full.Order.OrderUID)
-				return ctx.Err()
-			}
+		select {
+		case queues[workerFor(full.Order.OrderUID, len(queues))] <- job{msg: msg, order: full}:
+		case <-ctx.Done():
+			c.log.Info("consumer context canceled")
+			return nil
+		}
+	}
+}
 
-			procErr = svc.ProcessAndCache(ctx, &full)
-			if procErr == nil {
-				break
+// handle processes one order with retries. It returns false only when the context was canceled
+// before the message was handled, so the offset is not committed and the message is redelivered.
+func (c *consumer) handle(ctx context.Context, j job) bool {
+	const op = "kafka.consumeMessage"
+	msg, full := j.msg, j.order
+
+	var procErr error
+	ctx, span := tracing.StartConsume(ctx, op, msg)
+	span.SetAttributes(tracing.OrderUID(full.Order.OrderUID))
+	defer func() { tracing.End(span, procErr) }()
+
+	outage := 0
+	for attempt := 1; attempt <= maxProcessAttempts; attempt++ {
+		if ctx.Err() != nil {
+			c.log.InfoContext(ctx, "context canceled while processing", "order_uid", full.Order.OrderUID)
+			return false
+		}
+
+		procErr = c.svc.ProcessAndCache(audit.WithSource(ctx, audit.KafkaSource(msg.Topic, msg.Partition, msg.Offset)), full)
+		if outage > 0 && !orderRepoPostgres.IsUnavailable(procErr) {
+			c.log.InfoContext(ctx, "postgres is available again, resuming", "op", op, "order_uid", full.Order.OrderUID)
+			outage = 0
+		}
+		if procErr == nil {
+			break
+		}
+
+		if orderRepoPostgres.IsUnavailable(procErr) {
+			outage++
+			if !c.pause(ctx, outage, procErr) {
+				return false
 			}
+			metrics.KafkaRetries.WithLabelValues(metrics.RetryUnavailable).Inc()
+			attempt--
+			continue
+		}
 
-			log.Warn("failed to process order, will retry", "op", op, "order_uid", full.Order.OrderUID, "attempt", attempt, "err", procErr.Error())
-			fmt.Println(procErr)
-			if attempt < maxProcessAttempts {
-				sleep := calcBackoff(attempt)
-				select {
-				case <-time.After(sleep):
-				case <-ctx.Done():
-					return ctx.Err()
-				}
+		var verr *validation.Error
+		if errors.As(procErr, &verr) {
+			c.log.ErrorContext(ctx, "invalid order, skipping message", "op", op, "order_uid", full.Order.OrderUID, "fields", verr.Fields, "offset", msg.Offset)
+			break
+		}
+
+		c.log.WarnContext(ctx, "failed to process order, will retry", "op", op, "order_uid", full.Order.OrderUID, "attempt", attempt, "err", procErr.Error())
+		if attempt < maxProcessAttempts {
+			sleep := backoff.Delay(attempt, baseBackoff, maxBackoff)
+			select {
+			case <-time.After(sleep):
+			case <-ctx.Done():
+				return false
 			}
+			metrics.KafkaRetries.WithLabelValues(metrics.RetryError).Inc()
 		}
+	}
 
-		if procErr != nil {
-			log.Error("failed to process order after retries, skipping message", "op", op, "order_uid", full.Order.OrderUID, "err", procErr.Error())
-			fmt.Println(procErr)
+	if errors.Is(procErr, models.ErrInvalidInput) {
+		metrics.KafkaMessagesHandled.WithLabelValues(metrics.ResultInvalid).Inc()
+		return c.deadLetter(ctx, msg, dlq.ReasonValidationFailed, procErr, 1)
+	}
+
+	if procErr != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		c.log.ErrorContext(ctx, "failed to process order after retries, skipping message", "op", op, "order_uid", full.Order.OrderUID, "err", procErr.Error())
+		metrics.KafkaMessagesHandled.WithLabelValues(metrics.ResultFailed).Inc()
+		return c.deadLetter(ctx, msg, dlq.ReasonProcessingFailed, procErr, maxProcessAttempts)
+	}
+	metrics.KafkaMessagesHandled.WithLabelValues(metrics.ResultProcessed).Inc()
+	return true
+}
+
+// pause waits while Postgres is unavailable instead of spending processing attempts on the message.
+// The worker stays on its message, so its queue fills up, the dispatcher blocks and no more messages
+// are fetched until Postgres is back. It returns false if the context was canceled.
+func (c *consumer) pause(ctx context.Context, outage int, cause error) bool {
+	const op = "kafka.pause"
+
+	if outage == 1 {
+		c.log.Warn("postgres is unavailable, pausing consumer", "op", op, "err", cause.Error())
+	}
+	select {
+	case <-time.After(backoff.Delay(outage, c.pauseBackoff, maxBackoff)):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// deadLetter moves the message to the dead-letter topic. Committing the offset of a message that made it to
+// neither topic would lose it, so a failed publish is retried with backoff until it succeeds, holding the worker
+// the same way a Postgres outage does. It returns false if the context was canceled first, and the offset is
+// then not committed.
+func (c *consumer) deadLetter(ctx context.Context, msg kafka.Message, reason string, cause error, attempts int) bool {
+	const op = "kafka.deadLetter"
+
+	if c.dlq == nil {
+		return true
+	}
+	for attempt := 1; ; attempt++ {
+		err := c.dlq.Publish(ctx, msg, reason, cause, attempts)
+		if err == nil {
+			return true
+		}
+		if attempt == 1 {
+			c.log.WarnContext(ctx, "dead-letter topic is unavailable, holding the message", "op", op, "offset", msg.Offset, "err", err.Error())
+		}
+		select {
+		case <-time.After(backoff.Delay(attempt, c.pauseBackoff, maxBackoff)):
+			metrics.KafkaRetries.WithLabelValues(metrics.RetryDeadLetter).Inc()
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// commitLoop commits offsets as the contiguous handled range of each partition grows.
+// It runs until the done channel is closed, so offsets handled during shutdown are still committed.
+func (c *consumer) commitLoop(ctx context.Context) {
+	for msg := range c.done {
+		next, ok := c.tracker.markDone(msg)
+		if !ok {
 			continue
 		}
+		_ = commitMessages(ctx, c.reader, []kafka.Message{next}, c.log)
+	}
+}
 
-		var commitErr error
-		for attempt := 1; attempt <= maxCommitAttempts; attempt++ {
-			commitErr = reader.CommitMessages(ctx, msg)
-			if commitErr == nil {
-				break
+// consumeBatches collects up to size messages, waiting at most timeout after the first one, and stores them
+// with one ProcessAndCacheBatch call. When the batch fails every message is processed on its own, so one bad
+// order cannot hold back the rest. Offsets of the whole batch are committed together afterwards.
+func (c *consumer) consumeBatches(ctx context.Context, size int, timeout time.Duration) error {
+	const op = "kafka.consumeBatches"
+	commitCtx := context.WithoutCancel(ctx)
+
+	for {
+		msgs, jobs := c.fetchBatch(ctx, size, timeout)
+		if ctx.Err() != nil {
+			c.log.Info("consumer context canceled")
+			return nil
+		}
+		if len(msgs) == 0 {
+			continue
+		}
+
+		if len(jobs) > 0 {
+			orders := make([]*models.FullOrder, len(jobs))
+			sources := make(map[string]string, len(jobs))
+			for i, j := range jobs {
+				orders[i] = j.order
+				sources[j.order.Order.OrderUID] = audit.KafkaSource(j.msg.Topic, j.msg.Partition, j.msg.Offset)
+			}
+			batchCtx, span := tracing.StartConsumeBatch(ctx, op, jobMessages(jobs))
+			err := c.svc.ProcessAndCacheBatch(audit.WithOrderSources(batchCtx, sources), orders)
+			for outage := 1; orderRepoPostgres.IsUnavailable(err); outage++ {
+				if !c.pause(ctx, outage, err) {
+					tracing.End(span, err)
+					c.log.Info("consumer context canceled")
+					return nil
+				}
+				metrics.KafkaRetries.WithLabelValues(metrics.RetryUnavailable).Inc()
+				err = c.svc.ProcessAndCacheBatch(audit.WithOrderSources(batchCtx, sources), orders)
 			}
+			tracing.End(span, err)
+			if err != nil {
+				c.log.Warn("batch failed, processing messages one by one", "op", op, "size", len(jobs), "err", err)
+				for _, j := range jobs {
+					if !c.handle(ctx, j) {
+						return nil
+					}
+				}
+			} else {
+				metrics.KafkaMessagesHandled.WithLabelValues(metrics.ResultProcessed).Add(float64(len(jobs)))
+			}
+		}
 
-			if errors.Is(ctx.Err(), context.Canceled) {
-				log.Info("context canceled while committing", "order_uid", full.Order.OrderUID)
-				return ctx.Err()
+		_ = commitMessages(commitCtx, c.reader, msgs, c.log)
+	}
+}
+
+// fetchBatch returns all fetched messages and the decoded jobs among them. Messages that cannot be decoded
+// are dead-lettered here and only returned for committing. Nothing is returned if the context is canceled
+// before a dead letter is published.
+func (c *consumer) fetchBatch(ctx context.Context, size int, timeout time.Duration) ([]kafka.Message, []job) {
+	const op = "kafka.consumeBatches"
+
+	var (
+		msgs     []kafka.Message
+		jobs     []job
+		deadline time.Time
+	)
+	for len(msgs) < size {
+		fetchCtx, cancel := ctx, context.CancelFunc(func() {})
+		if len(msgs) > 0 {
+			fetchCtx, cancel = context.WithDeadline(ctx, deadline)
+		}
+		msg, err := c.reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			c.log.Error("error reading kafka message", "op", op, "err", err, "error_str", err.Error())
+			if len(msgs) > 0 {
+				break
 			}
+			select {
+			case <-time.After(readErrorBackoff):
+				continue
+			case <-ctx.Done():
+				return nil, nil
+			}
+		}
+		if len(msgs) == 0 {
+			deadline = time.Now().Add(timeout)
+		}
+		msgs = append(msgs, msg)
+		recordFetch(msg)
 
-			log.Warn("failed to commit message, will retry", "op", op, "order_uid", full.Order.OrderUID, "attempt", attempt, "err", commitErr.Error())
-			fmt.Println(commitErr)
-			if attempt < maxCommitAttempts {
-				sleep := calcBackoff(attempt)
-				select {
-				case <-time.After(sleep):
-				case <-ctx.Done():
-					return ctx.Err()
-				}
+		full, err := decode(msg)
+		if err != nil {
+			c.log.Error("invalid message format, skipping", "op", op, "err", err, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+			if !c.deadLetter(ctx, msg, dlq.ReasonDecodeFailed, err, 1) {
+				return nil, nil
 			}
+			continue
+		}
+		jobs = append(jobs, job{msg: msg, order: full})
+	}
+	return msgs, jobs
+}
+
+// decode parses the order message. Messages that are not valid JSON are counted as skipped.
+func decode(msg kafka.Message) (*models.FullOrder, error) {
+	defer metrics.ObserveStage(metrics.StageDecode, time.Now())
+
+	var full models.FullOrder
+	if err := json.Unmarshal(msg.Value, &full); err != nil {
+		metrics.KafkaMessagesHandled.WithLabelValues(metrics.ResultDecodeFailed).Inc()
+		return nil, err
+	}
+	return &full, nil
+}
+
+// recordFetch counts the message and updates the lag of its partition. The high watermark comes with
+// every fetch, so the lag needs no extra requests to the broker.
+func recordFetch(msg kafka.Message) {
+	metrics.KafkaMessagesConsumed.WithLabelValues(msg.Topic).Inc()
+	metrics.KafkaConsumerLag.WithLabelValues(msg.Topic, strconv.Itoa(msg.Partition)).Set(float64(max(0, msg.HighWaterMark-msg.Offset-1)))
+}
+
+func jobMessages(jobs []job) []kafka.Message {
+	msgs := make([]kafka.Message, len(jobs))
+	for i, j := range jobs {
+		msgs[i] = j.msg
+	}
+	return msgs
+}
+
+func workerFor(orderUID string, workers int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(orderUID))
+	return int(h.Sum32() % uint32(workers))
+}
+
+// commitMessages commits the offsets of the messages in one call, retrying with backoff. A non-nil error is returned
+// when the context is canceled or all attempts failed.
+func commitMessages(ctx context.Context, reader *kafka.Reader, msgs []kafka.Message, log *slog.Logger) error {
+	const op = "kafka.commitMessages"
+
+	msg := msgs[len(msgs)-1]
+	var commitErr error
+	for attempt := 1; attempt <= maxCommitAttempts; attempt++ {
+		commitErr = reader.CommitMessages(ctx, msgs...)
+		if commitErr == nil {
+			return nil
+		}
+
+		if errors.Is(ctx.Err(), context.Canceled) {
+			log.Info("context canceled while committing", "offset", msg.Offset)
+			return ctx.Err()
 		}
-		if commitErr != nil {
-			log.Error("failed to commit message after retries", "op", op, "order_uid", full.Order.OrderUID, "err", commitErr.Error())
+
+		metrics.KafkaCommitFailures.Inc()
+		log.Warn("failed to commit message, will retry", "op", op, "offset", msg.Offset, "attempt", attempt, "err", commitErr.Error())
+		if attempt < maxCommitAttempts {
+			sleep := backoff.Delay(attempt, baseBackoff, maxBackoff)
+			select {
+			case <-time.After(sleep):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
 	}
+	log.Error("failed to commit message after retries", "op", op, "offset", msg.Offset, "err", commitErr.Error())
+	return commitErr
 }
@@ -0,0 +1,49 @@
+package consumer
+
+import (
+	"context"
+	"github.com/segmentio/kafka-go"
+	"log/slog"
+	"time"
+	"wbL0/internal/models"
+	"wbL0/internal/service/orderService"
+)
+
+type OffsetTracker struct{ t *offsetTracker }
+
+func NewOffsetTracker() OffsetTracker { return OffsetTracker{t: newOffsetTracker()} }
+
+func (o OffsetTracker) Track(msg kafka.Message) { o.t.track(msg) }
+
+func (o OffsetTracker) MarkDone(msg kafka.Message) (kafka.Message, bool) { return o.t.markDone(msg) }
+
+var WorkerFor = workerFor
+
+var ApplyStatusEvent = applyStatusEvent
+
+// NewTestHandler returns the per-message handler of a consumer without a reader or dead-letter topic.
+func NewTestHandler(svc orderService.OrderServiceInterface, pauseBackoff time.Duration) func(ctx context.Context, msg kafka.Message, order *models.FullOrder) bool {
+	return newTestHandler(&consumer{svc: svc, log: slog.Default(), tracker: newOffsetTracker(), pauseBackoff: pauseBackoff})
+}
+
+// DeadLetterFunc stands in for the dead-letter publisher.
+type DeadLetterFunc func(ctx context.Context, msg kafka.Message, reason string, cause error, attempts int) error
+
+func (f DeadLetterFunc) Publish(ctx context.Context, msg kafka.Message, reason string, cause error, attempts int) error {
+	return f(ctx, msg, reason, cause, attempts)
+}
+
+// NewTestHandlerWithDeadLetters is NewTestHandler with failed messages published through dlq.
+func NewTestHandlerWithDeadLetters(svc orderService.OrderServiceInterface, dlq DeadLetterFunc, pauseBackoff time.Duration) func(ctx context.Context, msg kafka.Message, order *models.FullOrder) bool {
+	return newTestHandler(&consumer{svc: svc, log: slog.Default(), dlq: dlq, tracker: newOffsetTracker(), pauseBackoff: pauseBackoff})
+}
+
+func newTestHandler(c *consumer) func(ctx context.Context, msg kafka.Message, order *models.FullOrder) bool {
+	return func(ctx context.Context, msg kafka.Message, order *models.FullOrder) bool {
+		return c.handle(ctx, job{msg: msg, order: order})
+	}
+}
+
+var Decode = decode
+
+var RecordFetch = recordFetch
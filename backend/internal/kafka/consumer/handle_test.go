@@ -0,0 +1,81 @@
+package consumer_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"wbL0/internal/kafka/consumer"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	"wbL0/internal/validation"
+)
+
+func TestHandle_PostgresUnavailable(t *testing.T) {
+	msg := kafka.Message{Topic: "orders", Offset: 3}
+	order := &models.FullOrder{Order: models.Order{OrderUID: "o1"}}
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: assert.AnError}
+
+	t.Run("pauses without spending attempts until postgres is back", func(t *testing.T) {
+		srv := &mocks.OrderServiceInterface{}
+		srv.On("ProcessAndCache", mock.Anything, order).Return(refused).Times(7)
+		srv.On("ProcessAndCache", mock.Anything, order).Return(nil).Once()
+
+		handled := consumer.NewTestHandler(srv, time.Millisecond)(context.Background(), msg, order)
+		assert.True(t, handled)
+		srv.AssertNumberOfCalls(t, "ProcessAndCache", 8)
+	})
+
+	t.Run("shutdown during the pause leaves the message uncommitted", func(t *testing.T) {
+		srv := &mocks.OrderServiceInterface{}
+		srv.On("ProcessAndCache", mock.Anything, order).Return(refused)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		handled := consumer.NewTestHandler(srv, time.Millisecond)(ctx, msg, order)
+		assert.False(t, handled)
+	})
+}
+
+func TestHandle_DeadLetter(t *testing.T) {
+	msg := kafka.Message{Topic: "orders", Offset: 3}
+	order := &models.FullOrder{Order: models.Order{OrderUID: "o1"}}
+	invalid := &validation.Error{Fields: []validation.FieldError{{Field: "order_uid", Message: "is required"}}}
+
+	t.Run("offset waits until the dead letter is published", func(t *testing.T) {
+		srv := &mocks.OrderServiceInterface{}
+		srv.On("ProcessAndCache", mock.Anything, order).Return(invalid).Once()
+		published := 0
+		dlq := consumer.DeadLetterFunc(func(_ context.Context, got kafka.Message, reason string, _ error, _ int) error {
+			assert.Equal(t, msg, got)
+			assert.Equal(t, "validation_failed", reason)
+			if published++; published < 3 {
+				return errors.New("dlq unavailable")
+			}
+			return nil
+		})
+
+		handled := consumer.NewTestHandlerWithDeadLetters(srv, dlq, time.Millisecond)(context.Background(), msg, order)
+		assert.True(t, handled)
+		assert.Equal(t, 3, published)
+	})
+
+	t.Run("shutdown while the dead-letter topic is down leaves the message uncommitted", func(t *testing.T) {
+		srv := &mocks.OrderServiceInterface{}
+		srv.On("ProcessAndCache", mock.Anything, order).Return(invalid).Once()
+		dlq := consumer.DeadLetterFunc(func(context.Context, kafka.Message, string, error, int) error {
+			return errors.New("dlq unavailable")
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		handled := consumer.NewTestHandlerWithDeadLetters(srv, dlq, time.Millisecond)(ctx, msg, order)
+		assert.False(t, handled)
+	})
+}
@@ -0,0 +1,88 @@
+package consumer_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"wbL0/internal/kafka/consumer"
+	"wbL0/internal/metrics"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	"wbL0/internal/validation"
+)
+
+func TestHandle_Metrics(t *testing.T) {
+	msg := kafka.Message{Topic: "orders", Offset: 7}
+	order := &models.FullOrder{Order: models.Order{OrderUID: "o1"}}
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: assert.AnError}
+
+	tests := []struct {
+		name        string
+		mockSetup   func(srv *mocks.OrderServiceInterface)
+		result      string
+		unavailable float64
+	}{
+		{
+			name: "processed after postgres is back",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("ProcessAndCache", mock.Anything, order).Return(refused).Times(2)
+				srv.On("ProcessAndCache", mock.Anything, order).Return(nil).Once()
+			},
+			result:      metrics.ResultProcessed,
+			unavailable: 2,
+		},
+		{
+			name: "invalid order",
+			mockSetup: func(srv *mocks.OrderServiceInterface) {
+				srv.On("ProcessAndCache", mock.Anything, order).Return(&validation.Error{Fields: []validation.FieldError{{Field: "order.order_uid", Message: "is required"}}}).Once()
+			},
+			result: metrics.ResultInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled := metrics.KafkaMessagesHandled.WithLabelValues(tt.result)
+			retries := metrics.KafkaRetries.WithLabelValues(metrics.RetryUnavailable)
+			handledBefore, retriesBefore := testutil.ToFloat64(handled), testutil.ToFloat64(retries)
+
+			srv := &mocks.OrderServiceInterface{}
+			tt.mockSetup(srv)
+			assert.True(t, consumer.NewTestHandler(srv, time.Millisecond)(context.Background(), msg, order))
+
+			assert.Equal(t, handledBefore+1, testutil.ToFloat64(handled))
+			assert.Equal(t, retriesBefore+tt.unavailable, testutil.ToFloat64(retries))
+			srv.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDecode_Metrics(t *testing.T) {
+	decodeFailed := metrics.KafkaMessagesHandled.WithLabelValues(metrics.ResultDecodeFailed)
+	before := testutil.ToFloat64(decodeFailed)
+
+	order, err := consumer.Decode(kafka.Message{Value: []byte(`{"order":{"order_uid":"o1"}}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, "o1", order.Order.OrderUID)
+	assert.Equal(t, before, testutil.ToFloat64(decodeFailed))
+
+	_, err = consumer.Decode(kafka.Message{Value: []byte(`{"order":`)})
+	assert.Error(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(decodeFailed))
+}
+
+func TestRecordFetch(t *testing.T) {
+	consumer.RecordFetch(kafka.Message{Topic: "lag-test", Partition: 3, Offset: 4, HighWaterMark: 10})
+	assert.Equal(t, 5.0, testutil.ToFloat64(metrics.KafkaConsumerLag.WithLabelValues("lag-test", "3")))
+
+	consumer.RecordFetch(kafka.Message{Topic: "lag-test", Partition: 3, Offset: 9, HighWaterMark: 10})
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.KafkaConsumerLag.WithLabelValues("lag-test", "3")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.KafkaMessagesConsumed.WithLabelValues("lag-test")))
+}
@@ -0,0 +1,61 @@
+package consumer_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+
+	"wbL0/internal/kafka/consumer"
+)
+
+func msgAt(partition int, offset int64) kafka.Message {
+	return kafka.Message{Topic: "orders", Partition: partition, Offset: offset}
+}
+
+func TestOffsetTracker_CommitsHighestContiguousOffset(t *testing.T) {
+	tr := consumer.NewOffsetTracker()
+	for offset := int64(10); offset <= 13; offset++ {
+		tr.Track(msgAt(0, offset))
+	}
+
+	_, ok := tr.MarkDone(msgAt(0, 12))
+	assert.False(t, ok, "offset 10 and 11 are still in flight")
+
+	_, ok = tr.MarkDone(msgAt(0, 11))
+	assert.False(t, ok)
+
+	commit, ok := tr.MarkDone(msgAt(0, 10))
+	assert.True(t, ok)
+	assert.Equal(t, msgAt(0, 12), commit)
+
+	commit, ok = tr.MarkDone(msgAt(0, 13))
+	assert.True(t, ok)
+	assert.Equal(t, msgAt(0, 13), commit)
+}
+
+func TestOffsetTracker_PartitionsAreIndependent(t *testing.T) {
+	tr := consumer.NewOffsetTracker()
+	tr.Track(msgAt(0, 1))
+	tr.Track(msgAt(1, 5))
+	tr.Track(msgAt(1, 6))
+
+	commit, ok := tr.MarkDone(msgAt(1, 5))
+	assert.True(t, ok)
+	assert.Equal(t, msgAt(1, 5), commit)
+
+	_, ok = tr.MarkDone(msgAt(0, 2))
+	assert.False(t, ok, "untracked offsets are ignored")
+
+	commit, ok = tr.MarkDone(msgAt(0, 1))
+	assert.True(t, ok)
+	assert.Equal(t, msgAt(0, 1), commit)
+}
+
+func TestWorkerFor_IsStablePerOrder(t *testing.T) {
+	first := consumer.WorkerFor("b563feb7b2b84b6test", 8)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first, consumer.WorkerFor("b563feb7b2b84b6test", 8))
+	}
+	assert.Equal(t, 0, consumer.WorkerFor("anything", 1))
+}
@@ -0,0 +1,106 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/segmentio/kafka-go"
+	"log/slog"
+	"time"
+	"wbL0/internal/audit"
+	"wbL0/internal/config"
+	"wbL0/internal/lib/backoff"
+	"wbL0/internal/models"
+	"wbL0/internal/service/orderService"
+)
+
+// ConsumeStatusEvents reads order status changes from cfg.Kafka.StatusTopic and applies them one by one.
+// Events are not dead-lettered: the DLQ replays into the orders topic, so broken and illegal events are logged and skipped.
+func ConsumeStatusEvents(ctx context.Context, cfg *config.Config, svc orderService.OrderServiceInterface, log *slog.Logger) error {
+	const op = "kafka.ConsumeStatusEvents"
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        cfg.Kafka.Brokers,
+		Topic:          cfg.Kafka.StatusTopic,
+		GroupID:        cfg.Kafka.GroupID + "-status",
+		MinBytes:       1,
+		MaxBytes:       10e6,
+		CommitInterval: time.Second,
+	})
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Warn("kafka status reader close error", "err", err)
+		}
+	}()
+
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, ctx.Err()) {
+				log.Info("status consumer context canceled")
+				return nil
+			}
+			log.Error("error reading kafka status message", "op", op, "err", err)
+
+			select {
+			case <-time.After(readErrorBackoff):
+				continue
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		recordFetch(msg)
+
+		if !applyStatusEvent(ctx, svc, msg, log) {
+			return nil
+		}
+		_ = commitMessages(context.WithoutCancel(ctx), reader, []kafka.Message{msg}, log)
+	}
+}
+
+// applyStatusEvent applies one status event with retries. An unknown order is retried as well, because the
+// status event may overtake the order itself, which arrives through another topic. It returns false only
+// when the context was canceled, so the offset is not committed.
+func applyStatusEvent(ctx context.Context, svc orderService.OrderServiceInterface, msg kafka.Message, log *slog.Logger) bool {
+	const op = "kafka.applyStatusEvent"
+
+	var event models.StatusEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil || event.OrderUID == "" {
+		log.Error("invalid status event, skipping", "op", op, "err", err, "partition", msg.Partition, "offset", msg.Offset)
+		return true
+	}
+	status, err := models.ParseOrderStatus(event.Status)
+	if err != nil {
+		log.Error("invalid status event, skipping", "op", op, "order_uid", event.OrderUID, "err", err, "offset", msg.Offset)
+		return true
+	}
+
+	source := audit.KafkaSource(msg.Topic, msg.Partition, msg.Offset)
+	var applyErr error
+	for attempt := 1; attempt <= maxProcessAttempts; attempt++ {
+		if ctx.Err() != nil {
+			return false
+		}
+
+		_, applyErr = svc.ChangeOrderStatus(ctx, event.OrderUID, status, event.Reason, source)
+		if applyErr == nil {
+			return true
+		}
+		if errors.Is(applyErr, models.ErrInvalidTransition) {
+			log.Error("illegal status transition, skipping", "op", op, "order_uid", event.OrderUID, "err", applyErr.Error(), "offset", msg.Offset)
+			return true
+		}
+
+		log.Warn("failed to apply status event, will retry", "op", op, "order_uid", event.OrderUID, "attempt", attempt, "err", applyErr.Error())
+		if attempt < maxProcessAttempts {
+			select {
+			case <-time.After(backoff.Delay(attempt, baseBackoff, maxBackoff)):
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+
+	log.Error("failed to apply status event after retries, skipping", "op", op, "order_uid", event.OrderUID, "err", applyErr.Error(), "offset", msg.Offset)
+	return true
+}
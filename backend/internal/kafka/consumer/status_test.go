@@ -0,0 +1,59 @@
+package consumer_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"wbL0/internal/kafka/consumer"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+)
+
+func TestApplyStatusEvent(t *testing.T) {
+	ctx := context.Background()
+	statusMsg := func(value string) kafka.Message {
+		return kafka.Message{Topic: "order-status", Partition: 0, Offset: 7, Value: []byte(value)}
+	}
+
+	t.Run("applies event with kafka source", func(t *testing.T) {
+		srv := &mocks.OrderServiceInterface{}
+		srv.On("ChangeOrderStatus", ctx, "o1", models.StatusPaid, "paid online", "kafka:order-status/0@7").
+			Return(&models.StatusChange{}, nil).Once()
+
+		ok := consumer.ApplyStatusEvent(ctx, srv, statusMsg(`{"order_uid":"o1","status":"paid","reason":"paid online"}`), slog.Default())
+		assert.True(t, ok)
+		srv.AssertExpectations(t)
+	})
+
+	t.Run("illegal transition is skipped without retry", func(t *testing.T) {
+		srv := &mocks.OrderServiceInterface{}
+		srv.On("ChangeOrderStatus", ctx, "o1", models.StatusDelivered, "", mock.Anything).
+			Return(nil, models.ErrInvalidTransition).Once()
+
+		ok := consumer.ApplyStatusEvent(ctx, srv, statusMsg(`{"order_uid":"o1","status":"delivered"}`), slog.Default())
+		assert.True(t, ok)
+		srv.AssertNumberOfCalls(t, "ChangeOrderStatus", 1)
+	})
+
+	t.Run("broken events are skipped", func(t *testing.T) {
+		srv := &mocks.OrderServiceInterface{}
+		for _, value := range []string{"{", `{"status":"paid"}`, `{"order_uid":"o1","status":"lost"}`} {
+			assert.True(t, consumer.ApplyStatusEvent(ctx, srv, statusMsg(value), slog.Default()), value)
+		}
+		srv.AssertNotCalled(t, "ChangeOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("canceled context is not committed", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		srv := &mocks.OrderServiceInterface{}
+
+		ok := consumer.ApplyStatusEvent(canceled, srv, statusMsg(`{"order_uid":"o1","status":"paid"}`), slog.Default())
+		assert.False(t, ok)
+	})
+}
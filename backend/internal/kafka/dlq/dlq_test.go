@@ -0,0 +1,46 @@
+package dlq_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+
+	"wbL0/internal/kafka/dlq"
+)
+
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestBuildMessageAndUnwrap(t *testing.T) {
+	orig := kafka.Message{
+		Topic:     "orders",
+		Partition: 2,
+		Offset:    42,
+		Key:       []byte("uid"),
+		Value:     []byte(`{"order":{}}`),
+		Headers:   []kafka.Header{{Key: "traceparent", Value: []byte("tp")}},
+	}
+
+	msg := dlq.BuildMessage(orig, dlq.ReasonProcessingFailed, errors.New("db down"), 5)
+
+	assert.Equal(t, orig.Key, msg.Key)
+	assert.Equal(t, orig.Value, msg.Value)
+	assert.Equal(t, "orders", header(msg, dlq.HeaderOriginalTopic))
+	assert.Equal(t, "2", header(msg, dlq.HeaderOriginalPartition))
+	assert.Equal(t, "42", header(msg, dlq.HeaderOriginalOffset))
+	assert.Equal(t, dlq.ReasonProcessingFailed, header(msg, dlq.HeaderReason))
+	assert.Equal(t, "db down", header(msg, dlq.HeaderError))
+	assert.Equal(t, "5", header(msg, dlq.HeaderAttempts))
+
+	replayed := dlq.Unwrap(msg)
+	assert.Equal(t, orig.Headers, replayed.Headers)
+	assert.Equal(t, orig.Value, replayed.Value)
+}
@@ -0,0 +1,89 @@
+package dlq
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+	"wbL0/internal/config"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	HeaderOriginalTopic     = "dlq-original-topic"
+	HeaderOriginalPartition = "dlq-original-partition"
+	HeaderOriginalOffset    = "dlq-original-offset"
+	HeaderReason            = "dlq-reason"
+	HeaderError             = "dlq-error"
+	HeaderAttempts          = "dlq-attempts"
+)
+
+const (
+	ReasonDecodeFailed     = "decode_failed"
+	ReasonValidationFailed = "validation_failed"
+	ReasonProcessingFailed = "processing_failed"
+)
+
+type Publisher struct {
+	writer *kafka.Writer
+	log    *slog.Logger
+}
+
+// NewPublisher returns nil when no dead-letter topic is configured.
+func NewPublisher(cfg *config.Config, log *slog.Logger) *Publisher {
+	if cfg.Kafka.DeadLetterTopic == "" {
+		return nil
+	}
+
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.Kafka.Brokers...),
+		Topic:                  cfg.Kafka.DeadLetterTopic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+		WriteTimeout:           10 * time.Second,
+	}
+	return &Publisher{writer: writer, log: log}
+}
+
+// Publish republishes the failed message to the dead-letter topic together with its origin and the failure reason.
+func (p *Publisher) Publish(ctx context.Context, msg kafka.Message, reason string, cause error, attempts int) error {
+	const op = "dlq.Publish"
+
+	if err := p.writer.WriteMessages(ctx, BuildMessage(msg, reason, cause, attempts)); err != nil {
+		p.log.Error("failed to publish message to dead-letter topic", "op", op, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "err", err)
+		return err
+	}
+	p.log.Warn("message moved to dead-letter topic", "op", op, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "reason", reason, "attempts", attempts)
+	return nil
+}
+
+func (p *Publisher) Close() error {
+	return p.writer.Close()
+}
+
+// BuildMessage copies key, value and headers of the original message and appends the dead-letter headers.
+func BuildMessage(msg kafka.Message, reason string, cause error, attempts int) kafka.Message {
+	errText := ""
+	if cause != nil {
+		errText = cause.Error()
+	}
+
+	headers := make([]kafka.Header, 0, len(msg.Headers)+6)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderOriginalTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderOriginalPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderOriginalOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderReason, Value: []byte(reason)},
+		kafka.Header{Key: HeaderError, Value: []byte(errText)},
+		kafka.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(attempts))},
+	)
+
+	return kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
@@ -0,0 +1,97 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"wbL0/internal/config"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Replay reads the dead-letter topic and republishes every message to the main topic.
+// It returns once no new message arrived during idleTimeout or limit messages were replayed (0 means no limit).
+func Replay(ctx context.Context, cfg *config.Config, log *slog.Logger, idleTimeout time.Duration, limit int) (int, error) {
+	const op = "dlq.Replay"
+
+	if cfg.Kafka.DeadLetterTopic == "" {
+		return 0, errors.New("dead-letter topic is not configured")
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  cfg.Kafka.Brokers,
+		Topic:    cfg.Kafka.DeadLetterTopic,
+		GroupID:  cfg.Kafka.GroupID + "-dlq-replay",
+		MinBytes: 1,
+		MaxBytes: 10e6,
+	})
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Warn("kafka reader close error", "op", op, "err", err)
+		}
+	}()
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Kafka.Brokers...),
+		Topic:        cfg.Kafka.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}
+	defer func() {
+		if err := writer.Close(); err != nil {
+			log.Warn("kafka writer close error", "op", op, "err", err)
+		}
+	}()
+
+	replayed := 0
+	for limit == 0 || replayed < limit {
+		fetchCtx, cancel := context.WithTimeout(ctx, idleTimeout)
+		msg, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				log.Info("dead-letter topic drained", "op", op, "replayed", replayed)
+				return replayed, nil
+			}
+			return replayed, err
+		}
+
+		if err := writer.WriteMessages(ctx, Unwrap(msg)); err != nil {
+			log.Error("failed to republish message", "op", op, "offset", msg.Offset, "err", err)
+			return replayed, err
+		}
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			log.Error("failed to commit dead-letter message", "op", op, "offset", msg.Offset, "err", err)
+			return replayed, err
+		}
+
+		replayed++
+		log.Info("message replayed", "op", op, "dlq_offset", msg.Offset, "reason", headerValue(msg, HeaderReason))
+	}
+
+	log.Info("replay limit reached", "op", op, "replayed", replayed)
+	return replayed, nil
+}
+
+// Unwrap strips the dead-letter headers so the message looks like it was produced to the main topic.
+func Unwrap(msg kafka.Message) kafka.Message {
+	headers := make([]kafka.Header, 0, len(msg.Headers))
+	for _, h := range msg.Headers {
+		if strings.HasPrefix(h.Key, "dlq-") {
+			continue
+		}
+		headers = append(headers, h)
+	}
+	return kafka.Message{Key: msg.Key, Value: msg.Value, Headers: headers}
+}
+
+func headerValue(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,13 @@
+package outbox
+
+import (
+	"log/slog"
+	"time"
+	"wbL0/internal/repository/postgres/orderRepoPostgres"
+)
+
+type MessageWriter = messageWriter
+
+func NewTestRelay(repo orderRepoPostgres.OutboxRepositoryInterface, writer MessageWriter, batchSize int) *Relay {
+	return newRelay(repo, writer, slog.Default(), time.Millisecond, batchSize)
+}
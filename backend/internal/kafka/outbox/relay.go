@@ -0,0 +1,125 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+	"wbL0/internal/config"
+	"wbL0/internal/metrics"
+	"wbL0/internal/models"
+	"wbL0/internal/repository/postgres/orderRepoPostgres"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	HeaderEventID   = "event-id"
+	HeaderEventType = "event-type"
+
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+)
+
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Relay publishes outbox events to Kafka. Events are keyed by order UID, so all events of one order land
+// in the same partition in the order they were stored. Delivery is at least once: an event whose sent mark
+// is lost is published again, and consumers can deduplicate by the event-id header.
+type Relay struct {
+	repo         orderRepoPostgres.OutboxRepositoryInterface
+	writer       messageWriter
+	log          *slog.Logger
+	pollInterval time.Duration
+	batchSize    int
+}
+
+// NewRelay returns nil when no outbox topic is configured.
+func NewRelay(cfg *config.Config, repo orderRepoPostgres.OutboxRepositoryInterface, log *slog.Logger) *Relay {
+	if cfg.Outbox.Topic == "" {
+		return nil
+	}
+
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.Kafka.Brokers...),
+		Topic:                  cfg.Outbox.Topic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+		WriteTimeout:           10 * time.Second,
+	}
+	return newRelay(repo, writer, log, cfg.Outbox.PollInterval, cfg.Outbox.BatchSize)
+}
+
+func newRelay(repo orderRepoPostgres.OutboxRepositoryInterface, writer messageWriter, log *slog.Logger, pollInterval time.Duration, batchSize int) *Relay {
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &Relay{repo: repo, writer: writer, log: log, pollInterval: pollInterval, batchSize: batchSize}
+}
+
+// Run relays events until the context is canceled. A full batch is followed by the next one right away,
+// otherwise the relay waits for the poll interval.
+func (r *Relay) Run(ctx context.Context) {
+	const op = "outbox.Run"
+
+	for {
+		sent, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.log.Warn("failed to relay outbox events", "op", op, "err", err)
+		}
+		r.updateBacklog(ctx)
+
+		if sent == r.batchSize {
+			continue
+		}
+		select {
+		case <-time.After(r.pollInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RelayOnce publishes one batch of events and returns how many were sent.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	sent, err := r.repo.RelayOutbox(ctx, r.batchSize, func(events []models.OutboxEvent) error {
+		msgs := make([]kafka.Message, len(events))
+		for i, e := range events {
+			msgs[i] = kafka.Message{
+				Key:   []byte(e.OrderUID),
+				Value: e.Payload,
+				Headers: []kafka.Header{
+					{Key: HeaderEventID, Value: []byte(strconv.FormatInt(e.ID, 10))},
+					{Key: HeaderEventType, Value: []byte(e.EventType)},
+				},
+			}
+		}
+		if err := r.writer.WriteMessages(ctx, msgs...); err != nil {
+			metrics.OutboxFailures.Add(float64(len(events)))
+			return err
+		}
+		return nil
+	})
+	metrics.OutboxPublished.Add(float64(sent))
+	return sent, err
+}
+
+func (r *Relay) updateBacklog(ctx context.Context) {
+	pending, lag, err := r.repo.OutboxBacklog(ctx)
+	if err != nil {
+		return
+	}
+	metrics.OutboxPending.Set(float64(pending))
+	metrics.OutboxLag.Set(lag.Seconds())
+}
+
+func (r *Relay) Close() error {
+	return r.writer.Close()
+}
@@ -0,0 +1,81 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"wbL0/internal/kafka/outbox"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+)
+
+type fakeWriter struct {
+	written []kafka.Message
+	err     error
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.written = append(w.written, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error { return nil }
+
+// relayWith makes the repository mock hand events to the publish callback and report its result.
+func relayWith(repo *mocks.OutboxRepositoryInterface, events []models.OutboxEvent) {
+	var publishErr error
+	repo.On("RelayOutbox", mock.Anything, 10, mock.Anything).
+		Run(func(args mock.Arguments) {
+			publishErr = args.Get(2).(func([]models.OutboxEvent) error)(events)
+		}).
+		Return(func(context.Context, int, func([]models.OutboxEvent) error) int {
+			if publishErr != nil {
+				return 0
+			}
+			return len(events)
+		}, func(context.Context, int, func([]models.OutboxEvent) error) error {
+			return publishErr
+		})
+}
+
+func TestRelay_RelayOnce(t *testing.T) {
+	ctx := context.Background()
+	events := []models.OutboxEvent{
+		{ID: 1, OrderUID: "a", EventType: models.EventOrderStored, Payload: []byte(`{"order_uid":"a"}`)},
+		{ID: 2, OrderUID: "b", EventType: models.EventOrderStored, Payload: []byte(`{"order_uid":"b"}`)},
+	}
+
+	t.Run("publishes events keyed by order uid", func(t *testing.T) {
+		repo := &mocks.OutboxRepositoryInterface{}
+		relayWith(repo, events)
+		writer := &fakeWriter{}
+
+		sent, err := outbox.NewTestRelay(repo, writer, 10).RelayOnce(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, sent)
+		if assert.Len(t, writer.written, 2) {
+			assert.Equal(t, []byte("a"), writer.written[0].Key)
+			assert.Equal(t, events[0].Payload, writer.written[0].Value)
+			assert.Contains(t, writer.written[0].Headers, kafka.Header{Key: outbox.HeaderEventID, Value: []byte("1")})
+			assert.Contains(t, writer.written[0].Headers, kafka.Header{Key: outbox.HeaderEventType, Value: []byte(models.EventOrderStored)})
+		}
+	})
+
+	t.Run("write failure is returned so events stay unsent", func(t *testing.T) {
+		repo := &mocks.OutboxRepositoryInterface{}
+		relayWith(repo, events)
+		writer := &fakeWriter{err: errors.New("broker down")}
+
+		sent, err := outbox.NewTestRelay(repo, writer, 10).RelayOnce(ctx)
+		assert.Error(t, err)
+		assert.Equal(t, 0, sent)
+	})
+}
@@ -0,0 +1,74 @@
+package producer
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+	"wbL0/internal/config"
+	"wbL0/internal/tracing"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Producer publishes order payloads to the orders topic asynchronously and counts delivery results.
+type Producer struct {
+	writer *kafka.Writer
+	log    *slog.Logger
+
+	sent   atomic.Int64
+	failed atomic.Int64
+}
+
+func New(cfg *config.Config, log *slog.Logger) *Producer {
+	p := &Producer{log: log}
+	p.writer = &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.Kafka.Brokers...),
+		Topic:                  cfg.Kafka.Topic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireOne,
+		AllowAutoTopicCreation: true,
+		Async:                  true,
+		BatchSize:              500,
+		BatchTimeout:           10 * time.Millisecond,
+		Completion:             p.complete,
+	}
+	return p
+}
+
+// Send enqueues the message. Delivery errors are reported through Stats. The message starts a trace
+// whose context is passed in the traceparent header, so the consumer continues it.
+func (p *Producer) Send(ctx context.Context, key, value []byte) (err error) {
+	const op = "producer.Send"
+
+	ctx, span := tracing.Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("messaging.system", "kafka"), attribute.String("messaging.destination.name", p.writer.Topic)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	msg := kafka.Message{Key: key, Value: value}
+	tracing.Inject(ctx, &msg)
+	return p.writer.WriteMessages(ctx, msg)
+}
+
+// Stats returns the number of delivered and failed messages.
+func (p *Producer) Stats() (sent, failed int64) {
+	return p.sent.Load(), p.failed.Load()
+}
+
+// Close flushes pending messages.
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
+
+func (p *Producer) complete(messages []kafka.Message, err error) {
+	if err != nil {
+		p.failed.Add(int64(len(messages)))
+		p.log.Warn("failed to publish batch", "op", "producer.complete", "count", len(messages), "err", err)
+		return
+	}
+	p.sent.Add(int64(len(messages)))
+}
@@ -0,0 +1,46 @@
+package backoff
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Delay returns the exponential delay before the given attempt, starting at base and capped at max,
+// with up to a quarter of jitter added.
+func Delay(attempt int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/4+1))
+}
+
+// Retry calls fn until it succeeds, the context is done or timeout has passed since the first attempt.
+// onRetry, if set, is called before every wait. The last error of fn is returned.
+func Retry(ctx context.Context, timeout, base, max time.Duration, fn func(ctx context.Context) error, onRetry func(attempt int, err error, wait time.Duration)) error {
+	deadline := time.Now().Add(timeout)
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		wait := Delay(attempt, base, max)
+		if time.Now().Add(wait).After(deadline) {
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		}
+		if onRetry != nil {
+			onRetry(attempt, err, wait)
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
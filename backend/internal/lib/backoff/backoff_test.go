@@ -0,0 +1,61 @@
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"wbL0/internal/lib/backoff"
+)
+
+func TestDelay(t *testing.T) {
+	base, max := 100*time.Millisecond, time.Second
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 10: time.Second} {
+		d := backoff.Delay(attempt, base, max)
+		assert.GreaterOrEqual(t, d, want, "attempt %d", attempt)
+		assert.LessOrEqual(t, d, want+want/4, "attempt %d", attempt)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	down := errors.New("connection refused")
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		calls, retries := 0, 0
+		err := backoff.Retry(ctx, time.Second, time.Millisecond, 5*time.Millisecond, func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return down
+			}
+			return nil
+		}, func(int, error, time.Duration) { retries++ })
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, 2, retries)
+	})
+
+	t.Run("gives up at the deadline", func(t *testing.T) {
+		start := time.Now()
+		err := backoff.Retry(ctx, 30*time.Millisecond, time.Millisecond, 5*time.Millisecond, func(context.Context) error {
+			return down
+		}, nil)
+
+		assert.ErrorIs(t, err, down)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("stops when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		err := backoff.Retry(ctx, time.Minute, time.Millisecond, time.Millisecond, func(context.Context) error {
+			return down
+		}, nil)
+
+		assert.ErrorIs(t, err, down)
+	})
+}
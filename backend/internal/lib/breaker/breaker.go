@@ -0,0 +1,199 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrOpen matches the *OpenError returned instead of calling a dependency whose breaker is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// OpenError names the open breaker and tells how long it stays open.
+type OpenError struct {
+	Name       string
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Name, ErrOpen)
+}
+
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
+
+// RetryAfter returns how long the breaker behind err stays open, and false if err is not an open breaker.
+func RetryAfter(err error) (time.Duration, bool) {
+	var open *OpenError
+	if !errors.As(err, &open) {
+		return 0, false
+	}
+	return open.RetryAfter, true
+}
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+const (
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 10 * time.Second
+	defaultHalfOpenCalls    = 1
+)
+
+// Settings of a breaker. Zero values fall back to the defaults.
+type Settings struct {
+	// FailureThreshold consecutive failures open the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before letting probe calls through.
+	OpenTimeout time.Duration
+	// HalfOpenCalls is the number of concurrent probe calls allowed in the half-open state.
+	HalfOpenCalls int
+}
+
+// Breaker stops calls to a failing dependency. After FailureThreshold consecutive failures it opens and
+// rejects calls for OpenTimeout, then lets HalfOpenCalls probes through: a successful probe closes it,
+// a failed one opens it again.
+type Breaker struct {
+	name     string
+	settings Settings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probes   int
+	onChange func(name string, from, to State)
+}
+
+func New(name string, settings Settings) *Breaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = defaultFailureThreshold
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = defaultOpenTimeout
+	}
+	if settings.HalfOpenCalls <= 0 {
+		settings.HalfOpenCalls = defaultHalfOpenCalls
+	}
+	return &Breaker{name: name, settings: settings, now: time.Now}
+}
+
+// OnStateChange registers a callback called on every state transition while the breaker is locked,
+// so it must not call back into the breaker.
+func (b *Breaker) OnStateChange(fn func(name string, from, to State)) *Breaker {
+	b.onChange = fn
+	return b
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+	return b.state
+}
+
+// Allow returns an *OpenError if the call must not be made. Every allowed call must be reported with Done.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+
+	switch b.state {
+	case StateOpen:
+		return &OpenError{Name: b.name, RetryAfter: b.retryAfter()}
+	case StateHalfOpen:
+		if b.probes >= b.settings.HalfOpenCalls {
+			return &OpenError{Name: b.name}
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// Done reports the outcome of an allowed call.
+func (b *Breaker) Done(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.open()
+		}
+	case StateHalfOpen:
+		b.probes--
+		if failed {
+			b.open()
+		} else {
+			b.failures = 0
+			b.setState(StateClosed)
+		}
+	}
+}
+
+// RetryAfter returns how long the breaker stays open, or zero when it is not open.
+func (b *Breaker) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+	return b.retryAfter()
+}
+
+func (b *Breaker) retryAfter() time.Duration {
+	if b.state != StateOpen {
+		return 0
+	}
+	return b.openedAt.Add(b.settings.OpenTimeout).Sub(b.now())
+}
+
+func (b *Breaker) open() {
+	b.openedAt = b.now()
+	b.probes = 0
+	b.setState(StateOpen)
+}
+
+// expire moves an open breaker whose timeout has passed to half-open.
+func (b *Breaker) expire() {
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.settings.OpenTimeout)) {
+		b.setState(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) setState(to State) {
+	from := b.state
+	if from == to {
+		return
+	}
+	b.state = to
+	if b.onChange != nil {
+		b.onChange(b.name, from, to)
+	}
+}
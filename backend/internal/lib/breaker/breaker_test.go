@@ -0,0 +1,83 @@
+package breaker_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"wbL0/internal/lib/breaker"
+)
+
+func newBreaker(now *time.Time) *breaker.Breaker {
+	b := breaker.New("redis", breaker.Settings{FailureThreshold: 3, OpenTimeout: time.Second, HalfOpenCalls: 1})
+	b.SetClock(func() time.Time { return *now })
+	return b
+}
+
+func fail(t *testing.T, b *breaker.Breaker, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if assert.NoError(t, b.Allow()) {
+			b.Done(true)
+		}
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		now := now
+		b := newBreaker(&now)
+		fail(t, b, 2)
+		assert.NoError(t, b.Allow())
+		b.Done(false)
+		fail(t, b, 2)
+		assert.Equal(t, breaker.StateClosed, b.State(), "a success resets the failure count")
+
+		fail(t, b, 1)
+		assert.Equal(t, breaker.StateOpen, b.State())
+		err := b.Allow()
+		assert.ErrorIs(t, err, breaker.ErrOpen)
+		assert.EqualError(t, err, "redis: circuit breaker is open")
+		assert.Equal(t, time.Second, b.RetryAfter())
+
+		now = now.Add(400 * time.Millisecond)
+		retryAfter, ok := breaker.RetryAfter(fmt.Errorf("get order: %w", b.Allow()))
+		assert.True(t, ok)
+		assert.Equal(t, 600*time.Millisecond, retryAfter)
+	})
+
+	t.Run("half-open probe closes on success", func(t *testing.T) {
+		now := now
+		b := newBreaker(&now)
+		fail(t, b, 3)
+
+		now = now.Add(time.Second)
+		assert.Equal(t, breaker.StateHalfOpen, b.State())
+		assert.Zero(t, b.RetryAfter())
+		assert.NoError(t, b.Allow())
+		assert.ErrorIs(t, b.Allow(), breaker.ErrOpen, "only one probe at a time")
+
+		b.Done(false)
+		assert.Equal(t, breaker.StateClosed, b.State())
+		assert.NoError(t, b.Allow())
+	})
+
+	t.Run("half-open probe reopens on failure", func(t *testing.T) {
+		now := now
+		b := newBreaker(&now)
+		var changes []string
+		b.OnStateChange(func(_ string, from, to breaker.State) { changes = append(changes, from.String()+">"+to.String()) })
+		fail(t, b, 3)
+
+		now = now.Add(time.Second)
+		assert.NoError(t, b.Allow())
+		b.Done(true)
+		assert.Equal(t, breaker.StateOpen, b.State())
+		assert.Equal(t, time.Second, b.RetryAfter())
+		assert.Equal(t, []string{"closed>open", "open>half_open", "half_open>open"}, changes)
+	})
+}
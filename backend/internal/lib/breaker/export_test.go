@@ -0,0 +1,8 @@
+package breaker
+
+import "time"
+
+// SetClock replaces the clock of the breaker.
+func (b *Breaker) SetClock(now func() time.Time) {
+	b.now = now
+}
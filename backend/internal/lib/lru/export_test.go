@@ -0,0 +1,7 @@
+package lru
+
+import "time"
+
+func SetNow[K comparable, V any](c *Cache[K, V], now func() time.Time) {
+	c.now = now
+}
@@ -0,0 +1,65 @@
+package lru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"wbL0/internal/lib/lru"
+)
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := lru.New[string, int](2, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	c.Set("c", 3)
+
+	_, ok = c.Get("b")
+	assert.False(t, ok, "b was least recently used and must be evicted")
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestCache_SetOverwritesValue(t *testing.T) {
+	c := lru.New[string, int](2, 0)
+	c.Set("a", 1)
+	c.Set("a", 2)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestCache_ExpiresEntries(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := lru.New[string, int](2, time.Minute)
+	lru.SetNow(c, func() time.Time { return now })
+
+	c.Set("a", 1)
+	now = now.Add(30 * time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCache_Delete(t *testing.T) {
+	c := lru.New[string, int](2, 0)
+	c.Set("a", 1)
+	c.Delete("a")
+	c.Delete("missing")
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,126 @@
+package ordergen
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+	"wbL0/internal/models"
+)
+
+var (
+	firstNames = []string{"Ivan", "Anna", "Petr", "Maria", "Oleg", "Elena", "Sergey", "Olga"}
+	lastNames  = []string{"Ivanov", "Petrova", "Sidorov", "Smirnova", "Kuznetsov", "Popova"}
+	cities     = []string{"Moscow", "Saint Petersburg", "Kazan", "Novosibirsk", "Yekaterinburg", "Samara"}
+	streets    = []string{"Lenina", "Mira", "Sadovaya", "Pushkina", "Gagarina"}
+	services   = []string{"meest", "cdek", "boxberry", "wb"}
+	providers  = []string{"wbpay", "sbp", "card"}
+	banks      = []string{"alpha", "sber", "tinkoff", "vtb"}
+	currencies = []string{"RUB", "USD", "EUR"}
+	brands     = []string{"Vivienne Sabo", "Nike", "Adidas", "Samsung", "Xiaomi", "Zara"}
+	products   = []string{"Mascaras", "Sneakers", "T-shirt", "Phone case", "Headphones", "Backpack"}
+	sizes      = []string{"0", "S", "M", "L", "XL"}
+)
+
+// Generator produces random orders that pass validation.ValidateFullOrder.
+// Customers and products repeat across orders, while identifiers are unique per generator run.
+type Generator struct {
+	rng    *rand.Rand
+	prefix string
+	seq    int
+}
+
+func New(seed int64) *Generator {
+	rng := rand.New(rand.NewSource(seed))
+	return &Generator{rng: rng, prefix: fmt.Sprintf("%08x", rng.Uint32())}
+}
+
+func (g *Generator) Valid() *models.FullOrder {
+	g.seq++
+	uid := fmt.Sprintf("%s%012d", g.prefix, g.seq)
+	track := fmt.Sprintf("WB%s%08d", strings.ToUpper(g.prefix), g.seq)
+	customer := g.rng.Intn(1000)
+
+	fo := &models.FullOrder{
+		Order: models.Order{
+			OrderUID:        uid,
+			TrackNumber:     track,
+			Entry:           "WBIL",
+			Locale:          g.pick([]string{"ru", "en"}),
+			CustomerID:      fmt.Sprintf("customer-%d", customer),
+			DeliveryService: g.pick(services),
+			Shardkey:        fmt.Sprintf("%d", g.rng.Intn(10)),
+			SmID:            g.rng.Intn(100),
+			DateCreated:     time.Now().UTC().Add(-time.Duration(g.rng.Intn(7*24*60)) * time.Minute).Truncate(time.Second),
+			OofShard:        fmt.Sprintf("%d", g.rng.Intn(3)),
+		},
+		Delivery: models.Delivery{
+			Name:    g.pick(firstNames) + " " + g.pick(lastNames),
+			Phone:   fmt.Sprintf("+7900%07d", customer),
+			Zip:     100000 + g.rng.Intn(900000),
+			City:    g.pick(cities),
+			Address: fmt.Sprintf("%s st. %d", g.pick(streets), 1+g.rng.Intn(150)),
+			Region:  g.pick(cities),
+			Email:   fmt.Sprintf("customer%d@example.com", customer),
+		},
+	}
+
+	var goodsTotal float64
+	itemsCount := 1 + g.rng.Intn(4)
+	for i := 0; i < itemsCount; i++ {
+		price := float64(100 + g.rng.Intn(5000))
+		sale := g.rng.Intn(60)
+		total := float64(int(price * float64(100-sale) / 100))
+		goodsTotal += total
+		product := g.rng.Intn(len(products) * len(brands))
+		fo.Items = append(fo.Items, models.Item{
+			ChrtID:      1000000 + product*10 + g.rng.Intn(10),
+			TrackNumber: track,
+			Price:       price,
+			Rid:         fmt.Sprintf("%s-%d", uid, i),
+			Name:        products[product%len(products)],
+			Sale:        sale,
+			Size:        g.pick(sizes),
+			TotalPrice:  total,
+			NmID:        2000000 + product,
+			Brand:       brands[product%len(brands)],
+			Status:      202,
+		})
+	}
+
+	deliveryCost := float64(g.rng.Intn(10) * 100)
+	fo.Payment = models.Payment{
+		Transaction:  uid,
+		Currency:     g.pick(currencies),
+		Provider:     g.pick(providers),
+		Amount:       int(goodsTotal + deliveryCost),
+		PaymentDt:    int(fo.Order.DateCreated.Unix()),
+		Bank:         g.pick(banks),
+		DeliveryCost: deliveryCost,
+		GoodsTotal:   goodsTotal,
+	}
+	fo.FillOrderUID()
+	return fo
+}
+
+// Invalid returns an order that fails validation in one random way.
+func (g *Generator) Invalid() *models.FullOrder {
+	fo := g.Valid()
+	switch g.rng.Intn(5) {
+	case 0:
+		fo.Order.OrderUID = ""
+	case 1:
+		fo.Delivery.Email = "not-an-email"
+	case 2:
+		fo.Payment.Currency = "rubles"
+	case 3:
+		fo.Payment.Amount += 100
+	default:
+		fo.Items[0].TrackNumber = "UNKNOWN"
+	}
+	return fo
+}
+
+func (g *Generator) pick(values []string) string {
+	return values[g.rng.Intn(len(values))]
+}
@@ -0,0 +1,27 @@
+package ordergen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"wbL0/internal/lib/ordergen"
+	"wbL0/internal/validation"
+)
+
+func TestGenerator(t *testing.T) {
+	g := ordergen.New(1)
+	seen := make(map[string]bool)
+
+	for i := 0; i < 200; i++ {
+		fo := g.Valid()
+		assert.NoError(t, validation.ValidateFullOrder(fo))
+		assert.False(t, seen[fo.Order.OrderUID], "duplicate order uid %s", fo.Order.OrderUID)
+		seen[fo.Order.OrderUID] = true
+		// The track number carries the whole run prefix, so runs do not collide on it any more than on the UID.
+		assert.Contains(t, fo.Order.TrackNumber, strings.ToUpper(fo.Order.OrderUID[:8]))
+
+		assert.Error(t, validation.ValidateFullOrder(g.Invalid()))
+	}
+}
@@ -9,6 +9,32 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+const (
+	TierLocal = "local"
+	TierRedis = "redis"
+
+	ResultHit  = "hit"
+	ResultMiss = "miss"
+	// ResultNegativeHit means the tier knows the order does not exist.
+	ResultNegativeHit = "negative_hit"
+
+	// Results of consumed order messages. Every result except ResultProcessed means the message was skipped.
+	ResultProcessed    = "processed"
+	ResultDecodeFailed = "decode_failed"
+	ResultInvalid      = "invalid"
+	ResultFailed       = "failed"
+
+	// Reasons for processing a message again.
+	RetryError       = "error"
+	RetryUnavailable = "unavailable"
+	RetryDeadLetter  = "dead_letter"
+
+	StageDecode     = "decode"
+	StageValidate   = "validate"
+	StageDBTx       = "db_tx"
+	StageCacheWrite = "cache_write"
+)
+
 var (
 	ReqCount = prometheus.NewCounterVec(
 		prometheus.CounterOpts{Name: "http_requests_total", Help: "Number of HTTP requests"},
@@ -18,10 +44,96 @@ var (
 		prometheus.HistogramOpts{Name: "http_request_duration_seconds", Help: "Request latency in seconds", Buckets: prometheus.DefBuckets},
 		[]string{"path", "method"},
 	)
+	OrdersSaved = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "orders_saved_total", Help: "Number of ingested orders by save outcome"},
+		[]string{"outcome"},
+	)
+	CacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "order_cache_requests_total", Help: "Order cache lookups by tier and result"},
+		[]string{"tier", "result"},
+	)
+	OutboxPublished = prometheus.NewCounter(
+		prometheus.CounterOpts{Name: "outbox_events_published_total", Help: "Number of outbox events published to Kafka"},
+	)
+	OutboxFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{Name: "outbox_publish_failures_total", Help: "Number of outbox events whose publish attempt failed"},
+	)
+	OutboxPending = prometheus.NewGauge(
+		prometheus.GaugeOpts{Name: "outbox_events_pending", Help: "Number of outbox events not yet published"},
+	)
+	OutboxLag = prometheus.NewGauge(
+		prometheus.GaugeOpts{Name: "outbox_lag_seconds", Help: "Age of the oldest unpublished outbox event"},
+	)
+	CacheWarmupOrders = prometheus.NewGauge(
+		prometheus.GaugeOpts{Name: "cache_warmup_orders", Help: "Number of orders written to Redis by the current cache warm-up"},
+	)
+	BreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: "circuit_breaker_state", Help: "Circuit breaker state: 0 closed, 1 open, 2 half-open"},
+		[]string{"name"},
+	)
+	BreakerTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "circuit_breaker_transitions_total", Help: "Circuit breaker state changes by new state"},
+		[]string{"name", "state"},
+	)
+	OrdersArchived = prometheus.NewCounter(
+		prometheus.CounterOpts{Name: "orders_archived_total", Help: "Number of orders moved to the archive by the retention job"},
+	)
+	ArchiveReads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "order_archive_reads_total", Help: "Order lookups that fell back to the archive by result"},
+		[]string{"result"},
+	)
+	KafkaMessagesConsumed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "kafka_messages_consumed_total", Help: "Number of messages fetched from Kafka"},
+		[]string{"topic"},
+	)
+	KafkaMessagesHandled = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "kafka_messages_handled_total", Help: "Number of order messages handled by result"},
+		[]string{"result"},
+	)
+	KafkaRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "kafka_message_retries_total", Help: "Number of repeated processing attempts by reason"},
+		[]string{"reason"},
+	)
+	KafkaCommitFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{Name: "kafka_commit_failures_total", Help: "Number of failed offset commit attempts"},
+	)
+	KafkaConsumerLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: "kafka_consumer_lag", Help: "Messages behind the partition end as of the last fetched message"},
+		[]string{"topic", "partition"},
+	)
+	StageDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "order_pipeline_stage_duration_seconds",
+			Help:    "Duration of order ingestion stages",
+			Buckets: []float64{.0001, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+		[]string{"stage"},
+	)
+	IngestLatency = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "order_ingest_latency_seconds",
+			Help:    "Time from the order date_created to its storage in Postgres",
+			Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300, 900, 3600, 21600, 86400},
+		},
+	)
 )
 
 func Init() {
-	prometheus.MustRegister(ReqCount, ReqDuration)
+	prometheus.MustRegister(ReqCount, ReqDuration, OrdersSaved, CacheRequests, CacheWarmupOrders,
+		OutboxPublished, OutboxFailures, OutboxPending, OutboxLag, OrdersArchived, ArchiveReads,
+		BreakerState, BreakerTransitions, KafkaMessagesConsumed, KafkaMessagesHandled, KafkaRetries,
+		KafkaCommitFailures, KafkaConsumerLag, StageDuration, IngestLatency)
+}
+
+// ObserveStage records the time since start for an ingestion stage.
+func ObserveStage(stage string, start time.Time) {
+	StageDuration.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+}
+
+// ObserveIngest records how long after its creation an order reached Postgres. Orders dated in the future
+// by clock skew count as zero.
+func ObserveIngest(created, stored time.Time) {
+	IngestLatency.Observe(max(0, stored.Sub(created).Seconds()))
 }
 
 func PrometheusHandler() gin.HandlerFunc {
@@ -8,6 +8,7 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
 	orderRepoPostgres "wbL0/internal/repository/postgres/orderRepoPostgres"
 )
 
@@ -46,6 +47,78 @@ func (_m *OrderPostgresRepositoryInterface) BeginTx(ctx context.Context) (orderR
 	return r0, r1
 }
 
+// DeleteItemsTx provides a mock function with given fields: ctx, tx, orderUID
+func (_m *OrderPostgresRepositoryInterface) DeleteItemsTx(ctx context.Context, tx orderRepoPostgres.PgxTx, orderUID string) error {
+	ret := _m.Called(ctx, tx, orderUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteItemsTx")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, string) error); ok {
+		r0 = rf(ctx, tx, orderUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteOrderTx provides a mock function with given fields: ctx, tx, orderUID
+func (_m *OrderPostgresRepositoryInterface) DeleteOrderTx(ctx context.Context, tx orderRepoPostgres.PgxTx, orderUID string) error {
+	ret := _m.Called(ctx, tx, orderUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOrderTx")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, string) error); ok {
+		r0 = rf(ctx, tx, orderUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePendingEvictions provides a mock function with given fields: ctx, uids
+func (_m *OrderPostgresRepositoryInterface) DeletePendingEvictions(ctx context.Context, uids []string) error {
+	ret := _m.Called(ctx, uids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePendingEvictions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, uids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EraseCustomerTx provides a mock function with given fields: ctx, tx, customerID, erasure
+func (_m *OrderPostgresRepositoryInterface) EraseCustomerTx(ctx context.Context, tx orderRepoPostgres.PgxTx, customerID string, erasure *models.CustomerErasure) error {
+	ret := _m.Called(ctx, tx, customerID, erasure)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EraseCustomerTx")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, string, *models.CustomerErasure) error); ok {
+		r0 = rf(ctx, tx, customerID, erasure)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAllFullOrders provides a mock function with given fields: ctx
 func (_m *OrderPostgresRepositoryInterface) GetAllFullOrders(ctx context.Context) ([]*models.FullOrder, error) {
 	ret := _m.Called(ctx)
@@ -76,6 +149,36 @@ func (_m *OrderPostgresRepositoryInterface) GetAllFullOrders(ctx context.Context
 	return r0, r1
 }
 
+// GetErasedOrderUIDs provides a mock function with given fields: ctx, uids
+func (_m *OrderPostgresRepositoryInterface) GetErasedOrderUIDs(ctx context.Context, uids []string) ([]string, error) {
+	ret := _m.Called(ctx, uids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetErasedOrderUIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]string, error)); ok {
+		return rf(ctx, uids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []string); ok {
+		r0 = rf(ctx, uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, uids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFullOrderByUID provides a mock function with given fields: ctx, orderUID
 func (_m *OrderPostgresRepositoryInterface) GetFullOrderByUID(ctx context.Context, orderUID string) (*models.FullOrder, error) {
 	ret := _m.Called(ctx, orderUID)
@@ -106,6 +209,75 @@ func (_m *OrderPostgresRepositoryInterface) GetFullOrderByUID(ctx context.Contex
 	return r0, r1
 }
 
+// GetFullOrdersBatch provides a mock function with given fields: ctx, after, since, limit
+func (_m *OrderPostgresRepositoryInterface) GetFullOrdersBatch(ctx context.Context, after *models.OrderCursor, since *time.Time, limit int) ([]*models.FullOrder, *models.OrderCursor, error) {
+	ret := _m.Called(ctx, after, since, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFullOrdersBatch")
+	}
+
+	var r0 []*models.FullOrder
+	var r1 *models.OrderCursor
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.OrderCursor, *time.Time, int) ([]*models.FullOrder, *models.OrderCursor, error)); ok {
+		return rf(ctx, after, since, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.OrderCursor, *time.Time, int) []*models.FullOrder); ok {
+		r0 = rf(ctx, after, since, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.FullOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.OrderCursor, *time.Time, int) *models.OrderCursor); ok {
+		r1 = rf(ctx, after, since, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.OrderCursor)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *models.OrderCursor, *time.Time, int) error); ok {
+		r2 = rf(ctx, after, since, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetFullOrdersByUIDs provides a mock function with given fields: ctx, uids
+func (_m *OrderPostgresRepositoryInterface) GetFullOrdersByUIDs(ctx context.Context, uids []string) ([]*models.FullOrder, error) {
+	ret := _m.Called(ctx, uids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFullOrdersByUIDs")
+	}
+
+	var r0 []*models.FullOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]*models.FullOrder, error)); ok {
+		return rf(ctx, uids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*models.FullOrder); ok {
+		r0 = rf(ctx, uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.FullOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, uids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOrderInfoByUid provides a mock function with given fields: ctx, orderUID
 func (_m *OrderPostgresRepositoryInterface) GetOrderInfoByUid(ctx context.Context, orderUID string) (*models.Order, error) {
 	ret := _m.Called(ctx, orderUID)
@@ -136,6 +308,244 @@ func (_m *OrderPostgresRepositoryInterface) GetOrderInfoByUid(ctx context.Contex
 	return r0, r1
 }
 
+// GetOrderStatusForUpdateTx provides a mock function with given fields: ctx, tx, orderUID
+func (_m *OrderPostgresRepositoryInterface) GetOrderStatusForUpdateTx(ctx context.Context, tx orderRepoPostgres.PgxTx, orderUID string) (models.OrderStatus, error) {
+	ret := _m.Called(ctx, tx, orderUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderStatusForUpdateTx")
+	}
+
+	var r0 models.OrderStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, string) (models.OrderStatus, error)); ok {
+		return rf(ctx, tx, orderUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, string) models.OrderStatus); ok {
+		r0 = rf(ctx, tx, orderUID)
+	} else {
+		r0 = ret.Get(0).(models.OrderStatus)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, orderRepoPostgres.PgxTx, string) error); ok {
+		r1 = rf(ctx, tx, orderUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderStatusHistory provides a mock function with given fields: ctx, orderUID
+func (_m *OrderPostgresRepositoryInterface) GetOrderStatusHistory(ctx context.Context, orderUID string) (*models.OrderStatusHistory, error) {
+	ret := _m.Called(ctx, orderUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderStatusHistory")
+	}
+
+	var r0 *models.OrderStatusHistory
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.OrderStatusHistory, error)); ok {
+		return rf(ctx, orderUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.OrderStatusHistory); ok {
+		r0 = rf(ctx, orderUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.OrderStatusHistory)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, orderUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderUIDsByCustomer provides a mock function with given fields: ctx, customerID
+func (_m *OrderPostgresRepositoryInterface) GetOrderUIDsByCustomer(ctx context.Context, customerID string) ([]string, error) {
+	ret := _m.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderUIDsByCustomer")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, customerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderVersion provides a mock function with given fields: ctx, orderUID, version
+func (_m *OrderPostgresRepositoryInterface) GetOrderVersion(ctx context.Context, orderUID string, version int) (*models.OrderVersion, error) {
+	ret := _m.Called(ctx, orderUID, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderVersion")
+	}
+
+	var r0 *models.OrderVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*models.OrderVersion, error)); ok {
+		return rf(ctx, orderUID, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *models.OrderVersion); ok {
+		r0 = rf(ctx, orderUID, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.OrderVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, orderUID, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingEvictions provides a mock function with given fields: ctx, limit
+func (_m *OrderPostgresRepositoryInterface) GetPendingEvictions(ctx context.Context, limit int) ([]string, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPendingEvictions")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]string, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []string); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRecentlyErasedOrderUIDs provides a mock function with given fields: ctx, window
+func (_m *OrderPostgresRepositoryInterface) GetRecentlyErasedOrderUIDs(ctx context.Context, window time.Duration) ([]string, error) {
+	ret := _m.Called(ctx, window)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRecentlyErasedOrderUIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) ([]string, error)); ok {
+		return rf(ctx, window)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) []string); ok {
+		r0 = rf(ctx, window)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(ctx, window)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOrderVersions provides a mock function with given fields: ctx, orderUID
+func (_m *OrderPostgresRepositoryInterface) ListOrderVersions(ctx context.Context, orderUID string) ([]models.OrderVersion, error) {
+	ret := _m.Called(ctx, orderUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrderVersions")
+	}
+
+	var r0 []models.OrderVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.OrderVersion, error)); ok {
+		return rf(ctx, orderUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.OrderVersion); ok {
+		r0 = rf(ctx, orderUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.OrderVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, orderUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOrders provides a mock function with given fields: ctx, filter
+func (_m *OrderPostgresRepositoryInterface) ListOrders(ctx context.Context, filter models.OrderFilter) ([]models.OrderSummary, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrders")
+	}
+
+	var r0 []models.OrderSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrderFilter) ([]models.OrderSummary, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrderFilter) []models.OrderSummary); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.OrderSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.OrderFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveDeliveryDataTx provides a mock function with given fields: ctx, tx, delivery
 func (_m *OrderPostgresRepositoryInterface) SaveDeliveryDataTx(ctx context.Context, tx orderRepoPostgres.PgxTx, delivery *models.Delivery) error {
 	ret := _m.Called(ctx, tx, delivery)
@@ -172,17 +582,93 @@ func (_m *OrderPostgresRepositoryInterface) SaveItemsDataTx(ctx context.Context,
 	return r0
 }
 
-// SaveOrderDataTx provides a mock function with given fields: ctx, tx, order
-func (_m *OrderPostgresRepositoryInterface) SaveOrderDataTx(ctx context.Context, tx orderRepoPostgres.PgxTx, order *models.Order) error {
-	ret := _m.Called(ctx, tx, order)
+// SaveOrderDataTx provides a mock function with given fields: ctx, tx, order, checksum
+func (_m *OrderPostgresRepositoryInterface) SaveOrderDataTx(ctx context.Context, tx orderRepoPostgres.PgxTx, order *models.Order, checksum string) (models.SaveOutcome, error) {
+	ret := _m.Called(ctx, tx, order, checksum)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SaveOrderDataTx")
 	}
 
+	var r0 models.SaveOutcome
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, *models.Order, string) (models.SaveOutcome, error)); ok {
+		return rf(ctx, tx, order, checksum)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, *models.Order, string) models.SaveOutcome); ok {
+		r0 = rf(ctx, tx, order, checksum)
+	} else {
+		r0 = ret.Get(0).(models.SaveOutcome)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, orderRepoPostgres.PgxTx, *models.Order, string) error); ok {
+		r1 = rf(ctx, tx, order, checksum)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveOrderVersionsTx provides a mock function with given fields: ctx, tx, versions
+func (_m *OrderPostgresRepositoryInterface) SaveOrderVersionsTx(ctx context.Context, tx orderRepoPostgres.PgxTx, versions []models.OrderVersion) error {
+	ret := _m.Called(ctx, tx, versions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveOrderVersionsTx")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, []models.OrderVersion) error); ok {
+		r0 = rf(ctx, tx, versions)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveOrdersBatchTx provides a mock function with given fields: ctx, tx, orders, checksums
+func (_m *OrderPostgresRepositoryInterface) SaveOrdersBatchTx(ctx context.Context, tx orderRepoPostgres.PgxTx, orders []*models.FullOrder, checksums []string) ([]models.SaveOutcome, error) {
+	ret := _m.Called(ctx, tx, orders, checksums)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveOrdersBatchTx")
+	}
+
+	var r0 []models.SaveOutcome
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, []*models.FullOrder, []string) ([]models.SaveOutcome, error)); ok {
+		return rf(ctx, tx, orders, checksums)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, []*models.FullOrder, []string) []models.SaveOutcome); ok {
+		r0 = rf(ctx, tx, orders, checksums)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SaveOutcome)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, orderRepoPostgres.PgxTx, []*models.FullOrder, []string) error); ok {
+		r1 = rf(ctx, tx, orders, checksums)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveOutboxEventsTx provides a mock function with given fields: ctx, tx, events
+func (_m *OrderPostgresRepositoryInterface) SaveOutboxEventsTx(ctx context.Context, tx orderRepoPostgres.PgxTx, events []models.OutboxEvent) error {
+	ret := _m.Called(ctx, tx, events)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveOutboxEventsTx")
+	}
+
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, *models.Order) error); ok {
-		r0 = rf(ctx, tx, order)
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, []models.OutboxEvent) error); ok {
+		r0 = rf(ctx, tx, events)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -208,6 +694,42 @@ func (_m *OrderPostgresRepositoryInterface) SavePaymentDataTx(ctx context.Contex
 	return r0
 }
 
+// SavePendingEvictions provides a mock function with given fields: ctx, uids
+func (_m *OrderPostgresRepositoryInterface) SavePendingEvictions(ctx context.Context, uids []string) error {
+	ret := _m.Called(ctx, uids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePendingEvictions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, uids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveStatusChangeTx provides a mock function with given fields: ctx, tx, change
+func (_m *OrderPostgresRepositoryInterface) SaveStatusChangeTx(ctx context.Context, tx orderRepoPostgres.PgxTx, change *models.StatusChange) error {
+	ret := _m.Called(ctx, tx, change)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveStatusChangeTx")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, orderRepoPostgres.PgxTx, *models.StatusChange) error); ok {
+		r0 = rf(ctx, tx, change)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewOrderPostgresRepositoryInterface creates a new instance of OrderPostgresRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewOrderPostgresRepositoryInterface(t interface {
@@ -16,6 +16,42 @@ type OrderRedisRepoInterface struct {
 	mock.Mock
 }
 
+// DeleteOrder provides a mock function with given fields: ctx, orderUID
+func (_m *OrderRedisRepoInterface) DeleteOrder(ctx context.Context, orderUID string) error {
+	ret := _m.Called(ctx, orderUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, orderUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteOrders provides a mock function with given fields: ctx, orderUIDs
+func (_m *OrderRedisRepoInterface) DeleteOrders(ctx context.Context, orderUIDs []string) error {
+	ret := _m.Called(ctx, orderUIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOrders")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, orderUIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetOrder provides a mock function with given fields: ctx, orderUID
 func (_m *OrderRedisRepoInterface) GetOrder(ctx context.Context, orderUID string) (*models.FullOrder, error) {
 	ret := _m.Called(ctx, orderUID)
@@ -82,6 +118,42 @@ func (_m *OrderRedisRepoInterface) SetOrder(ctx context.Context, order *models.F
 	return r0
 }
 
+// SetOrderNotFound provides a mock function with given fields: ctx, orderUID, ttl
+func (_m *OrderRedisRepoInterface) SetOrderNotFound(ctx context.Context, orderUID string, ttl time.Duration) error {
+	ret := _m.Called(ctx, orderUID, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetOrderNotFound")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) error); ok {
+		r0 = rf(ctx, orderUID, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetOrders provides a mock function with given fields: ctx, orders, ttl
+func (_m *OrderRedisRepoInterface) SetOrders(ctx context.Context, orders []*models.FullOrder, ttl time.Duration) error {
+	ret := _m.Called(ctx, orders, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetOrders")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*models.FullOrder, time.Duration) error); ok {
+		r0 = rf(ctx, orders, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewOrderRedisRepoInterface creates a new instance of OrderRedisRepoInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewOrderRedisRepoInterface(t interface {
@@ -7,6 +7,8 @@ import (
 	models "wbL0/internal/models"
 
 	mock "github.com/stretchr/testify/mock"
+
+	orderService "wbL0/internal/service/orderService"
 )
 
 // OrderServiceInterface is an autogenerated mock type for the OrderServiceInterface type
@@ -14,6 +16,162 @@ type OrderServiceInterface struct {
 	mock.Mock
 }
 
+// ChangeOrderStatus provides a mock function with given fields: ctx, orderUID, to, reason, source
+func (_m *OrderServiceInterface) ChangeOrderStatus(ctx context.Context, orderUID string, to models.OrderStatus, reason string, source string) (*models.StatusChange, error) {
+	ret := _m.Called(ctx, orderUID, to, reason, source)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangeOrderStatus")
+	}
+
+	var r0 *models.StatusChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.OrderStatus, string, string) (*models.StatusChange, error)); ok {
+		return rf(ctx, orderUID, to, reason, source)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.OrderStatus, string, string) *models.StatusChange); ok {
+		r0 = rf(ctx, orderUID, to, reason, source)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.StatusChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.OrderStatus, string, string) error); ok {
+		r1 = rf(ctx, orderUID, to, reason, source)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateOrder provides a mock function with given fields: ctx, fo
+func (_m *OrderServiceInterface) CreateOrder(ctx context.Context, fo *models.FullOrder) error {
+	ret := _m.Called(ctx, fo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.FullOrder) error); ok {
+		r0 = rf(ctx, fo)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteOrder provides a mock function with given fields: ctx, orderUID
+func (_m *OrderServiceInterface) DeleteOrder(ctx context.Context, orderUID string) error {
+	ret := _m.Called(ctx, orderUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, orderUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DiffOrderVersions provides a mock function with given fields: ctx, orderUID, from, to
+func (_m *OrderServiceInterface) DiffOrderVersions(ctx context.Context, orderUID string, from int, to int) (*models.OrderDiff, error) {
+	ret := _m.Called(ctx, orderUID, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DiffOrderVersions")
+	}
+
+	var r0 *models.OrderDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) (*models.OrderDiff, error)); ok {
+		return rf(ctx, orderUID, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) *models.OrderDiff); ok {
+		r0 = rf(ctx, orderUID, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.OrderDiff)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, orderUID, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EraseCustomerData provides a mock function with given fields: ctx, customerID, reason
+func (_m *OrderServiceInterface) EraseCustomerData(ctx context.Context, customerID string, reason string) (*models.CustomerErasure, error) {
+	ret := _m.Called(ctx, customerID, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EraseCustomerData")
+	}
+
+	var r0 *models.CustomerErasure
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.CustomerErasure, error)); ok {
+		return rf(ctx, customerID, reason)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.CustomerErasure); ok {
+		r0 = rf(ctx, customerID, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CustomerErasure)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, customerID, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportCustomerData provides a mock function with given fields: ctx, customerID
+func (_m *OrderServiceInterface) ExportCustomerData(ctx context.Context, customerID string) (*models.CustomerExport, error) {
+	ret := _m.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportCustomerData")
+	}
+
+	var r0 *models.CustomerExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.CustomerExport, error)); ok {
+		return rf(ctx, customerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.CustomerExport); ok {
+		r0 = rf(ctx, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CustomerExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOrder provides a mock function with given fields: ctx, orderUID
 func (_m *OrderServiceInterface) GetOrder(ctx context.Context, orderUID string) (*models.FullOrder, error) {
 	ret := _m.Called(ctx, orderUID)
@@ -44,6 +202,126 @@ func (_m *OrderServiceInterface) GetOrder(ctx context.Context, orderUID string)
 	return r0, r1
 }
 
+// GetOrderStatusHistory provides a mock function with given fields: ctx, orderUID
+func (_m *OrderServiceInterface) GetOrderStatusHistory(ctx context.Context, orderUID string) (*models.OrderStatusHistory, error) {
+	ret := _m.Called(ctx, orderUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderStatusHistory")
+	}
+
+	var r0 *models.OrderStatusHistory
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.OrderStatusHistory, error)); ok {
+		return rf(ctx, orderUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.OrderStatusHistory); ok {
+		r0 = rf(ctx, orderUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.OrderStatusHistory)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, orderUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderVersion provides a mock function with given fields: ctx, orderUID, version
+func (_m *OrderServiceInterface) GetOrderVersion(ctx context.Context, orderUID string, version int) (*models.OrderVersion, error) {
+	ret := _m.Called(ctx, orderUID, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderVersion")
+	}
+
+	var r0 *models.OrderVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*models.OrderVersion, error)); ok {
+		return rf(ctx, orderUID, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *models.OrderVersion); ok {
+		r0 = rf(ctx, orderUID, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.OrderVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, orderUID, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOrderVersions provides a mock function with given fields: ctx, orderUID
+func (_m *OrderServiceInterface) ListOrderVersions(ctx context.Context, orderUID string) ([]models.OrderVersion, error) {
+	ret := _m.Called(ctx, orderUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrderVersions")
+	}
+
+	var r0 []models.OrderVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.OrderVersion, error)); ok {
+		return rf(ctx, orderUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.OrderVersion); ok {
+		r0 = rf(ctx, orderUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.OrderVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, orderUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOrders provides a mock function with given fields: ctx, filter
+func (_m *OrderServiceInterface) ListOrders(ctx context.Context, filter models.OrderFilter) (*models.OrderPage, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrders")
+	}
+
+	var r0 *models.OrderPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrderFilter) (*models.OrderPage, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrderFilter) *models.OrderPage); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.OrderPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.OrderFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ProcessAndCache provides a mock function with given fields: ctx, fo
 func (_m *OrderServiceInterface) ProcessAndCache(ctx context.Context, fo *models.FullOrder) error {
 	ret := _m.Called(ctx, fo)
@@ -62,17 +340,53 @@ func (_m *OrderServiceInterface) ProcessAndCache(ctx context.Context, fo *models
 	return r0
 }
 
-// RestoreCacheFromDB provides a mock function with given fields: ctx
-func (_m *OrderServiceInterface) RestoreCacheFromDB(ctx context.Context) error {
-	ret := _m.Called(ctx)
+// ProcessAndCacheBatch provides a mock function with given fields: ctx, fos
+func (_m *OrderServiceInterface) ProcessAndCacheBatch(ctx context.Context, fos []*models.FullOrder) error {
+	ret := _m.Called(ctx, fos)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProcessAndCacheBatch")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*models.FullOrder) error); ok {
+		r0 = rf(ctx, fos)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreCacheFromDB provides a mock function with given fields: ctx, opts
+func (_m *OrderServiceInterface) RestoreCacheFromDB(ctx context.Context, opts orderService.WarmupOptions) error {
+	ret := _m.Called(ctx, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RestoreCacheFromDB")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, orderService.WarmupOptions) error); ok {
+		r0 = rf(ctx, opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateOrder provides a mock function with given fields: ctx, fo
+func (_m *OrderServiceInterface) UpdateOrder(ctx context.Context, fo *models.FullOrder) error {
+	ret := _m.Called(ctx, fo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.FullOrder) error); ok {
+		r0 = rf(ctx, fo)
 	} else {
 		r0 = ret.Error(0)
 	}
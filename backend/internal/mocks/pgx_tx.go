@@ -7,6 +7,7 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	pgx "github.com/jackc/pgx/v5"
 	pgconn "github.com/jackc/pgx/v5/pgconn"
 )
 
@@ -64,6 +65,29 @@ func (_m *PgxTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgc
 	return r0, r1
 }
 
+// QueryRow provides a mock function with given fields: ctx, sql, args
+func (_m *PgxTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, sql)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryRow")
+	}
+
+	var r0 pgx.Row
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...interface{}) pgx.Row); ok {
+		r0 = rf(ctx, sql, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(pgx.Row)
+		}
+	}
+
+	return r0
+}
+
 // Rollback provides a mock function with given fields: ctx
 func (_m *PgxTx) Rollback(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -82,6 +106,26 @@ func (_m *PgxTx) Rollback(ctx context.Context) error {
 	return r0
 }
 
+// SendBatch provides a mock function with given fields: ctx, b
+func (_m *PgxTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	ret := _m.Called(ctx, b)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendBatch")
+	}
+
+	var r0 pgx.BatchResults
+	if rf, ok := ret.Get(0).(func(context.Context, *pgx.Batch) pgx.BatchResults); ok {
+		r0 = rf(ctx, b)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(pgx.BatchResults)
+		}
+	}
+
+	return r0
+}
+
 // NewPgxTx creates a new instance of PgxTx. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPgxTx(t interface {
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// ErasedValue replaces the customer ID of erased orders.
+const ErasedValue = "erased"
+
+// CustomerExport is the archive of everything stored about a customer, returned on a data-subject access request.
+type CustomerExport struct {
+	CustomerID string       `json:"customer_id"`
+	ExportedAt time.Time    `json:"exported_at"`
+	Orders     []*FullOrder `json:"orders"`
+}
+
+// CustomerErasure is an entry of the erasure audit log. The customer is identified by CustomerHash only.
+type CustomerErasure struct {
+	ID           int64     `json:"id"`
+	CustomerHash string    `json:"customer_hash"`
+	OrderUIDs    []string  `json:"order_uids"`
+	RequestedBy  string    `json:"requested_by"`
+	Reason       string    `json:"reason,omitempty"`
+	ErasedAt     time.Time `json:"erased_at"`
+}
+
+type ErasureRequest struct {
+	Reason string `json:"reason"`
+}
+
+// CustomerHash is the SHA-256 of the customer ID, which lets an erasure be proven for a known ID
+// without keeping the ID itself.
+func CustomerHash(customerID string) string {
+	sum := sha256.Sum256([]byte(customerID))
+	return hex.EncodeToString(sum[:])
+}
@@ -8,4 +8,9 @@ var (
 	ErrForbidden     = errors.New("forbidden")
 	ErrInternal      = errors.New("internal server error")
 	ErrOrderNotFound = errors.New("order not found")
+	ErrOrderExists   = errors.New("order already exists")
+
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	ErrVersionNotFound   = errors.New("order version not found")
+	ErrEvictionPending   = errors.New("cache eviction pending")
 )
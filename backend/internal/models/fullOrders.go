@@ -1,8 +1,40 @@
 package models
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
 type FullOrder struct {
 	Order    Order    `json:"order"`
 	Delivery Delivery `json:"delivery"`
 	Payment  Payment  `json:"payment"`
 	Items    []Item   `json:"items"`
 }
+
+// Checksum returns a hex encoded sha256 of the order payload. It is used to detect redelivered identical orders.
+func (fo *FullOrder) Checksum() (string, error) {
+	data, err := json.Marshal(fo)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// FillOrderUID copies the order UID into delivery, payment and items when the payload omits it there.
+func (fo *FullOrder) FillOrderUID() {
+	uid := fo.Order.OrderUID
+	if fo.Delivery.OrderUID == "" {
+		fo.Delivery.OrderUID = uid
+	}
+	if fo.Payment.OrderUID == "" {
+		fo.Payment.OrderUID = uid
+	}
+	for i := range fo.Items {
+		if fo.Items[i].OrderUID == "" {
+			fo.Items[i].OrderUID = uid
+		}
+	}
+}
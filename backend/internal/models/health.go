@@ -0,0 +1,27 @@
+package models
+
+type HealthStatus string
+
+const (
+	HealthUp       HealthStatus = "up"
+	HealthDown     HealthStatus = "down"
+	HealthReady    HealthStatus = "ready"
+	HealthNotReady HealthStatus = "not_ready"
+)
+
+// HealthReport is the readiness response. Reason explains a not ready service whose dependencies are up,
+// such as a running cache warm-up or a shutdown drain.
+type HealthReport struct {
+	Status HealthStatus                `json:"status"`
+	Reason string                      `json:"reason,omitempty"`
+	Checks map[string]DependencyHealth `json:"checks"`
+}
+
+// DependencyHealth is the result of one check. An optional dependency being down does not make the service not ready.
+type DependencyHealth struct {
+	Status    HealthStatus   `json:"status"`
+	Optional  bool           `json:"optional,omitempty"`
+	LatencyMs int64          `json:"latency_ms"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
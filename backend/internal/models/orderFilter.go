@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	SortDesc = "desc"
+	SortAsc  = "asc"
+)
+
+// OrderFilter describes the GET /orders query. Empty fields are not applied.
+// Email and Phone are matched through blind indexes, so they need encryption enabled.
+type OrderFilter struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	Email           string
+	Phone           string
+	DateFrom        *time.Time
+	DateTo          *time.Time
+	Currency        string
+	Provider        string
+	Bank            string
+	Brand           string
+	NmID            int
+	Sort            string
+	Cursor          *OrderCursor
+	Limit           int
+}
+
+// OrderCursor points at the last order of the previous page in (date_created, order_uid) order.
+type OrderCursor struct {
+	DateCreated time.Time
+	OrderUID    string
+}
+
+func (c OrderCursor) Encode() string {
+	raw := c.DateCreated.UTC().Format(time.RFC3339Nano) + "|" + c.OrderUID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeOrderCursor(s string) (*OrderCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	ts, uid, ok := strings.Cut(string(raw), "|")
+	if !ok || uid == "" {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	dateCreated, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	return &OrderCursor{DateCreated: dateCreated, OrderUID: uid}, nil
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type OrderSummary struct {
+	OrderUID        string    `json:"order_uid"`
+	TrackNumber     string    `json:"track_number"`
+	CustomerID      string    `json:"customer_id,omitempty"`
+	DeliveryService string    `json:"delivery_service"`
+	DateCreated     time.Time `json:"date_created"`
+	Currency        string    `json:"currency"`
+	Provider        string    `json:"provider"`
+	Bank            string    `json:"bank"`
+	Amount          int       `json:"amount"`
+}
+
+type OrderPage struct {
+	Orders     []OrderSummary
+	NextCursor string
+}
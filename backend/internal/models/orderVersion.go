@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	VersionInserted = "inserted"
+	VersionUpdated  = "updated"
+	VersionDeleted  = "deleted"
+	VersionErased   = "erased"
+	// VersionStatusChanged and VersionArchived leave the order content as it was: both snapshots repeat
+	// the latest one and only Status differs.
+	VersionStatusChanged = "status_changed"
+	VersionArchived      = "archived"
+)
+
+// OrderVersion is one immutable entry of the order audit trail. Previous and Current are FullOrder snapshots:
+// Previous is empty for the first version of an order and Current is empty for a deletion. Orders stored
+// before the audit trail existed have no snapshot to start from, so their first version has no Previous and
+// a status change or archiving as their first version has no snapshots at all.
+// Status is the order status after the version and is empty for a deletion and for versions recorded before
+// it was tracked.
+// Erasing the customer's data clears the snapshots of every version and appends an erased version without them.
+type OrderVersion struct {
+	OrderUID  string          `json:"order_uid"`
+	Version   int             `json:"version"`
+	Operation string          `json:"operation"`
+	Source    string          `json:"source"`
+	Status    OrderStatus     `json:"status,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	Previous  json.RawMessage `json:"previous,omitempty" swaggertype:"object"`
+	Current   json.RawMessage `json:"current,omitempty" swaggertype:"object"`
+}
+
+type FieldChange struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+type OrderDiff struct {
+	OrderUID string        `json:"order_uid"`
+	From     int           `json:"from"`
+	To       int           `json:"to"`
+	Changes  []FieldChange `json:"changes"`
+}
+
+// NewOrderVersion snapshots fo for the audit trail. A nil fo records a deletion.
+func NewOrderVersion(orderUID, operation, source string, fo *FullOrder) (OrderVersion, error) {
+	version := OrderVersion{OrderUID: orderUID, Operation: operation, Source: source}
+	if fo != nil {
+		snapshot, err := json.Marshal(fo)
+		if err != nil {
+			return OrderVersion{}, err
+		}
+		version.Current = snapshot
+	}
+	return version, nil
+}
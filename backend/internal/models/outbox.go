@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const EventOrderStored = "order.stored"
+
+// OutboxEvent is a row of the order_outbox table waiting to be published to Kafka.
+type OutboxEvent struct {
+	ID        int64
+	OrderUID  string
+	EventType string
+	Payload   []byte
+	CreatedAt time.Time
+	Attempts  int
+}
+
+// OrderStoredEvent is the payload of EventOrderStored, published once an order is durably stored.
+type OrderStoredEvent struct {
+	OrderUID    string      `json:"order_uid"`
+	Outcome     SaveOutcome `json:"outcome"`
+	TrackNumber string      `json:"track_number"`
+	CustomerID  string      `json:"customer_id"`
+	DateCreated time.Time   `json:"date_created"`
+	StoredAt    time.Time   `json:"stored_at"`
+}
+
+func NewOrderStoredEvent(fo *FullOrder, outcome SaveOutcome, storedAt time.Time) (OutboxEvent, error) {
+	payload, err := json.Marshal(OrderStoredEvent{
+		OrderUID:    fo.Order.OrderUID,
+		Outcome:     outcome,
+		TrackNumber: fo.Order.TrackNumber,
+		CustomerID:  fo.Order.CustomerID,
+		DateCreated: fo.Order.DateCreated,
+		StoredAt:    storedAt,
+	})
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+	return OutboxEvent{OrderUID: fo.Order.OrderUID, EventType: EventOrderStored, Payload: payload}, nil
+}
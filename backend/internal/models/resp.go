@@ -10,8 +10,8 @@ type OrderResponse struct {
 	Payment           PaymentDTO  `json:"payment"`
 	Items             []ItemDTO   `json:"items"`
 	Locale            string      `json:"locale"`
-	InternalSignature string      `json:"internal_signature"`
-	CustomerID        string      `json:"customer_id"`
+	InternalSignature string      `json:"internal_signature,omitempty"`
+	CustomerID        string      `json:"customer_id,omitempty"`
 	DeliveryService   string      `json:"delivery_service"`
 	Shardkey          string      `json:"shardkey"`
 	SmID              int         `json:"sm_id"`
@@ -20,18 +20,18 @@ type OrderResponse struct {
 }
 
 type DeliveryDTO struct {
-	Name    string `json:"name"`
-	Phone   string `json:"phone"`
-	Zip     string `json:"zip"`
-	City    string `json:"city"`
-	Address string `json:"address"`
-	Region  string `json:"region"`
-	Email   string `json:"email"`
+	Name    string `json:"name,omitempty"`
+	Phone   string `json:"phone,omitempty"`
+	Zip     string `json:"zip,omitempty"`
+	City    string `json:"city,omitempty"`
+	Address string `json:"address,omitempty"`
+	Region  string `json:"region,omitempty"`
+	Email   string `json:"email,omitempty"`
 }
 
 type PaymentDTO struct {
-	Transaction  string  `json:"transaction"`
-	RequestID    string  `json:"request_id"`
+	Transaction  string  `json:"transaction,omitempty"`
+	RequestID    string  `json:"request_id,omitempty"`
 	Currency     string  `json:"currency"`
 	Provider     string  `json:"provider"`
 	Amount       int     `json:"amount"`
@@ -55,3 +55,8 @@ type ItemDTO struct {
 	Brand       string  `json:"brand"`
 	Status      int     `json:"status"`
 }
+
+type OrderListResponse struct {
+	Orders     []OrderSummary `json:"orders"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
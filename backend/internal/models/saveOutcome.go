@@ -0,0 +1,10 @@
+package models
+
+// SaveOutcome describes what happened to an order when it was written to the storage.
+type SaveOutcome string
+
+const (
+	OutcomeInserted  SaveOutcome = "inserted"
+	OutcomeUnchanged SaveOutcome = "unchanged"
+	OutcomeUpdated   SaveOutcome = "updated"
+)
@@ -0,0 +1,216 @@
+// Package redact masks or drops personal data of orders according to the caller role. The same policy
+// is applied to HTTP responses, stored snapshots shown through the API and structured logs.
+package redact
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+	"wbL0/internal/auth"
+	"wbL0/internal/config"
+	"wbL0/internal/models"
+)
+
+type Action string
+
+const (
+	Keep Action = "keep"
+	Mask Action = "mask"
+	Drop Action = "drop"
+)
+
+type rules map[string]Action
+
+// fields are the redactable order fields by FullOrder JSON path with the masking used for each.
+var fields = map[string]func(string) string{
+	"order.customer_id":        maskDefault,
+	"order.internal_signature": maskDefault,
+	"delivery.name":            maskDefault,
+	"delivery.phone":           MaskPhone,
+	"delivery.zip":             maskDefault,
+	"delivery.city":            maskDefault,
+	"delivery.address":         maskDefault,
+	"delivery.region":          maskDefault,
+	"delivery.email":           MaskEmail,
+	"payment.transaction":      maskDefault,
+	"payment.request_id":       maskDefault,
+}
+
+// Policy holds the redaction rules. A nil Policy leaves everything as is.
+type Policy struct {
+	roles    map[auth.Role]rules
+	fallback rules
+	logs     rules
+}
+
+func NewPolicy(cfg config.RedactionConfig) (*Policy, error) {
+	p := &Policy{roles: make(map[auth.Role]rules, len(cfg.Roles))}
+
+	var err error
+	if p.fallback, err = newRules(cfg.Default); err != nil {
+		return nil, fmt.Errorf("redaction default: %w", err)
+	}
+	if p.logs, err = newRules(cfg.Logs); err != nil {
+		return nil, fmt.Errorf("redaction logs: %w", err)
+	}
+	for name, r := range cfg.Roles {
+		role, err := auth.ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("redaction roles: %w", err)
+		}
+		if p.roles[role], err = newRules(r); err != nil {
+			return nil, fmt.Errorf("redaction role %s: %w", name, err)
+		}
+	}
+	return p, nil
+}
+
+func newRules(cfg config.RedactionRules) (rules, error) {
+	r := rules{}
+	for action, paths := range map[Action][]string{Mask: cfg.Mask, Drop: cfg.Drop} {
+		for _, path := range paths {
+			if _, ok := fields[path]; !ok {
+				return nil, fmt.Errorf("unknown field %q", path)
+			}
+			r[path] = action
+		}
+	}
+	return r, nil
+}
+
+// rulesFor picks the rules of the caller role, or the default rules for callers without a configured role.
+func (p *Policy) rulesFor(ctx context.Context) rules {
+	if principal := auth.PrincipalFrom(ctx); principal != nil {
+		if r, ok := p.roles[principal.Role]; ok {
+			return r
+		}
+	}
+	return p.fallback
+}
+
+func (r rules) apply(path, value string) string {
+	switch r[path] {
+	case Mask:
+		if value == "" {
+			return ""
+		}
+		return fields[path](value)
+	case Drop:
+		return ""
+	}
+	return value
+}
+
+func (p *Policy) OrderResponse(ctx context.Context, resp *models.OrderResponse) {
+	if p == nil {
+		return
+	}
+	r := p.rulesFor(ctx)
+	for path, value := range map[string]*string{
+		"order.customer_id":        &resp.CustomerID,
+		"order.internal_signature": &resp.InternalSignature,
+		"delivery.name":            &resp.Delivery.Name,
+		"delivery.phone":           &resp.Delivery.Phone,
+		"delivery.zip":             &resp.Delivery.Zip,
+		"delivery.city":            &resp.Delivery.City,
+		"delivery.address":         &resp.Delivery.Address,
+		"delivery.region":          &resp.Delivery.Region,
+		"delivery.email":           &resp.Delivery.Email,
+		"payment.transaction":      &resp.Payment.Transaction,
+		"payment.request_id":       &resp.Payment.RequestID,
+	} {
+		*value = r.apply(path, *value)
+	}
+}
+
+func (p *Policy) OrderSummaries(ctx context.Context, summaries []models.OrderSummary) {
+	if p == nil {
+		return
+	}
+	r := p.rulesFor(ctx)
+	for i := range summaries {
+		summaries[i].CustomerID = r.apply("order.customer_id", summaries[i].CustomerID)
+	}
+}
+
+// Snapshot redacts a FullOrder JSON snapshot. Dropped fields are removed from the document.
+func (p *Policy) Snapshot(ctx context.Context, snapshot json.RawMessage) (json.RawMessage, error) {
+	if p == nil || len(snapshot) == 0 {
+		return snapshot, nil
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(snapshot, &doc); err != nil {
+		return nil, err
+	}
+	r := p.rulesFor(ctx)
+	for path, action := range r {
+		section, key, _ := strings.Cut(path, ".")
+		obj, ok := doc[section].(map[string]any)
+		if !ok {
+			continue
+		}
+		if action == Drop {
+			delete(obj, key)
+			continue
+		}
+		if value, ok := obj[key]; ok {
+			obj[key] = r.apply(path, fmt.Sprint(value))
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// Changes redacts both sides of a diff. Dropped fields are left out.
+func (p *Policy) Changes(ctx context.Context, changes []models.FieldChange) []models.FieldChange {
+	if p == nil {
+		return changes
+	}
+	r := p.rulesFor(ctx)
+	kept := changes[:0]
+	for _, c := range changes {
+		switch r[c.Path] {
+		case Drop:
+			continue
+		case Mask:
+			c.Old, c.New = maskAny(r, c.Path, c.Old), maskAny(r, c.Path, c.New)
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
+
+func maskAny(r rules, path string, v any) any {
+	if v == nil {
+		return nil
+	}
+	return r.apply(path, fmt.Sprint(v))
+}
+
+// MaskPhone keeps the first two and the last four characters: +79001234567 becomes +7***4567.
+func MaskPhone(phone string) string {
+	if utf8.RuneCountInString(phone) <= 6 {
+		return "***"
+	}
+	runes := []rune(phone)
+	return string(runes[:2]) + "***" + string(runes[len(runes)-4:])
+}
+
+// MaskEmail keeps the first letter and the domain: john@mail.ru becomes j***@mail.ru.
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return maskDefault(email)
+	}
+	first, _ := utf8.DecodeRuneInString(local)
+	return string(first) + "***@" + domain
+}
+
+func maskDefault(value string) string {
+	if utf8.RuneCountInString(value) <= 2 {
+		return "***"
+	}
+	first, _ := utf8.DecodeRuneInString(value)
+	return string(first) + "***"
+}
@@ -0,0 +1,130 @@
+package redact_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wbL0/internal/auth"
+	"wbL0/internal/config"
+	"wbL0/internal/models"
+	"wbL0/internal/redact"
+)
+
+func testPolicy(t *testing.T) *redact.Policy {
+	p, err := redact.NewPolicy(config.RedactionConfig{
+		Default: config.RedactionRules{Mask: []string{"delivery.phone", "delivery.email", "delivery.name"}, Drop: []string{"order.internal_signature"}},
+		Roles: map[string]config.RedactionRules{
+			"admin":     {},
+			"analytics": {Drop: []string{"order.customer_id", "delivery.name", "delivery.phone", "delivery.email"}},
+		},
+		Logs: config.RedactionRules{Mask: []string{"delivery.phone", "delivery.email"}},
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func as(role auth.Role) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "test", Role: role})
+}
+
+func response() models.OrderResponse {
+	return models.OrderResponse{
+		OrderUID:          "o1",
+		CustomerID:        "customer",
+		InternalSignature: "sig",
+		Delivery:          models.DeliveryDTO{Name: "Ivan Petrov", Phone: "+79001234567", Email: "john@mail.ru", City: "Moscow"},
+	}
+}
+
+func TestMasks(t *testing.T) {
+	assert.Equal(t, "+7***4567", redact.MaskPhone("+79001234567"))
+	assert.Equal(t, "***", redact.MaskPhone("+7900"))
+	assert.Equal(t, "j***@mail.ru", redact.MaskEmail("john@mail.ru"))
+	assert.Equal(t, "И***", redact.MaskEmail("Иван"), "not an email falls back to the default mask")
+}
+
+func TestPolicy_OrderResponse(t *testing.T) {
+	p := testPolicy(t)
+
+	t.Run("default rules without a principal", func(t *testing.T) {
+		resp := response()
+		p.OrderResponse(context.Background(), &resp)
+		assert.Equal(t, "+7***4567", resp.Delivery.Phone)
+		assert.Equal(t, "j***@mail.ru", resp.Delivery.Email)
+		assert.Equal(t, "I***", resp.Delivery.Name)
+		assert.Empty(t, resp.InternalSignature)
+		assert.Equal(t, "customer", resp.CustomerID)
+		assert.Equal(t, "Moscow", resp.Delivery.City)
+	})
+
+	t.Run("role without rules sees everything", func(t *testing.T) {
+		resp := response()
+		p.OrderResponse(as(auth.RoleAdmin), &resp)
+		assert.Equal(t, response(), resp)
+	})
+
+	t.Run("analytics drops personal data", func(t *testing.T) {
+		resp := response()
+		p.OrderResponse(as(auth.RoleAnalytics), &resp)
+		assert.Empty(t, resp.CustomerID)
+		assert.Empty(t, resp.Delivery.Phone)
+		assert.Empty(t, resp.Delivery.Name)
+		assert.Equal(t, "sig", resp.InternalSignature)
+	})
+
+	t.Run("unconfigured role uses default rules", func(t *testing.T) {
+		resp := response()
+		p.OrderResponse(as(auth.RoleSupport), &resp)
+		assert.Equal(t, "+7***4567", resp.Delivery.Phone)
+	})
+
+	t.Run("nil policy keeps the response", func(t *testing.T) {
+		resp := response()
+		var nilPolicy *redact.Policy
+		nilPolicy.OrderResponse(context.Background(), &resp)
+		assert.Equal(t, response(), resp)
+	})
+}
+
+func TestPolicy_SnapshotAndChanges(t *testing.T) {
+	p := testPolicy(t)
+	fo := models.FullOrder{
+		Order:    models.Order{OrderUID: "o1", CustomerID: "customer"},
+		Delivery: models.Delivery{Phone: "+79001234567", Email: "john@mail.ru"},
+	}
+	snapshot, _ := json.Marshal(fo)
+
+	redacted, err := p.Snapshot(as(auth.RoleAnalytics), snapshot)
+	require.NoError(t, err)
+	var doc map[string]map[string]any
+	require.NoError(t, json.Unmarshal(redacted, &doc))
+	assert.NotContains(t, doc["delivery"], "phone")
+	assert.NotContains(t, doc["order"], "customer_id")
+	assert.Equal(t, "o1", doc["order"]["order_uid"])
+
+	redacted, err = p.Snapshot(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(redacted, &doc))
+	assert.Equal(t, "+7***4567", doc["delivery"]["phone"])
+
+	changes := p.Changes(as(auth.RoleAnalytics), []models.FieldChange{
+		{Path: "delivery.phone", Old: "+79001234567", New: "+79007654321"},
+		{Path: "delivery.city", Old: "A", New: "B"},
+	})
+	assert.Equal(t, []models.FieldChange{{Path: "delivery.city", Old: "A", New: "B"}}, changes)
+
+	changes = p.Changes(context.Background(), []models.FieldChange{{Path: "delivery.email", Old: nil, New: "john@mail.ru"}})
+	assert.Equal(t, []models.FieldChange{{Path: "delivery.email", Old: nil, New: "j***@mail.ru"}}, changes)
+}
+
+func TestNewPolicy_Errors(t *testing.T) {
+	_, err := redact.NewPolicy(config.RedactionConfig{Default: config.RedactionRules{Mask: []string{"delivery.passport"}}})
+	assert.Error(t, err)
+
+	_, err = redact.NewPolicy(config.RedactionConfig{Roles: map[string]config.RedactionRules{"root": {}}})
+	assert.Error(t, err)
+}
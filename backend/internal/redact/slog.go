@@ -0,0 +1,95 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// Only numbers with a leading + are treated as phones, so offsets and timestamps stay readable.
+	phonePattern = regexp.MustCompile(`\+\d{10,15}\b`)
+)
+
+// Scrub masks emails and phone numbers found in free text such as error messages.
+func (p *Policy) Scrub(s string) string {
+	if p == nil {
+		return s
+	}
+	s = emailPattern.ReplaceAllStringFunc(s, MaskEmail)
+	return phonePattern.ReplaceAllStringFunc(s, MaskPhone)
+}
+
+// Logger returns a logger that applies the log rules to attributes named after order fields, either by full
+// path ("delivery.phone") or by the last segment ("phone"), and scrubs all other string and error values.
+func (p *Policy) Logger(log *slog.Logger) *slog.Logger {
+	if p == nil {
+		return log
+	}
+	return slog.New(&handler{next: log.Handler(), policy: p})
+}
+
+type handler struct {
+	next   slog.Handler
+	policy *Policy
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, h.policy.Scrub(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.attr(a)
+	}
+	return &handler{next: h.next.WithAttrs(redacted), policy: h.policy}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), policy: h.policy}
+}
+
+func (h *handler) attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, ga := range group {
+			redacted[i] = h.attr(ga)
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindString:
+		if path, ok := h.logField(a.Key); ok {
+			return slog.String(a.Key, h.policy.logs.apply(path, v.String()))
+		}
+		return slog.String(a.Key, h.policy.Scrub(v.String()))
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, h.policy.Scrub(err.Error()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// logField finds the order field configured for logs that the attribute key names.
+func (h *handler) logField(key string) (string, bool) {
+	for path := range h.policy.logs {
+		if key == path || strings.HasSuffix(path, "."+key) {
+			return path, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,31 @@
+package redact_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	log := testPolicy(t).Logger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	log.With("email", "john@mail.ru").Info("stored order for john@mail.ru",
+		"phone", "+79001234567",
+		"offset", 1700000000123,
+		"err", errors.New(`duplicate key (phone)=(+79001234567)`),
+		slog.Group("delivery", "phone", "+79001234567"),
+	)
+
+	out := buf.String()
+	assert.NotContains(t, out, "john@mail.ru")
+	assert.NotContains(t, out, "+79001234567")
+	assert.Contains(t, out, `"email":"j***@mail.ru"`)
+	assert.Contains(t, out, `stored order for j***@mail.ru`)
+	assert.Contains(t, out, `"phone":"+7***4567"`)
+	assert.Contains(t, out, `"offset":1700000000123`)
+	assert.Contains(t, out, `(phone)=(+7***4567)`)
+}
@@ -0,0 +1,173 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"time"
+	"wbL0/internal/audit"
+	"wbL0/internal/models"
+)
+
+//go:generate mockery --name=ArchiveRepositoryInterface --dir=. --output=../../../mocks --outpkg=mocks --case=underscore
+type ArchiveRepositoryInterface interface {
+	ArchiveOrders(ctx context.Context, before time.Time, limit int) ([]string, error)
+	GetArchivedOrder(ctx context.Context, orderUID string) (*models.FullOrder, error)
+	GetArchivedOrdersByCustomer(ctx context.Context, customerID string) ([]*models.FullOrder, error)
+}
+
+// archiveOrdersQuery copies up to $2 orders created before $1 into orders_archive and deletes them from the
+// hot tables in one statement; delivery, payment and items go with ON DELETE CASCADE, the status history
+// stays in order_status_history. Each archived order gets an archived version ($3) from source $4 that repeats
+// its latest snapshot.
+// The delivery is copied as stored, so encrypted fields stay encrypted with the data key moved to the archive row.
+// Rows locked by a concurrent write are skipped until the next run.
+const archiveOrdersQuery = `WITH picked AS (
+			SELECT order_uid FROM orders
+			WHERE date_created < $1
+			ORDER BY date_created, order_uid
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		), archived AS (
+			INSERT INTO orders_archive (order_uid, customer_id, date_created, status, payload, dek, key_id, erased_at)
+			SELECT o.order_uid, o.customer_id, o.date_created, o.status,
+				jsonb_build_object(
+					'order', jsonb_build_object(
+						'order_uid', o.order_uid, 'track_number', o.track_number, 'entry', o.entry, 'locale', o.locale,
+						'internal_signature', o.internal_signature, 'customer_id', o.customer_id,
+						'delivery_service', o.delivery_service, 'shardkey', o.shardkey, 'sm_id', o.sm_id,
+						'date_created', o.date_created AT TIME ZONE 'UTC', 'oof_shard', o.oof_shard),
+					'delivery', jsonb_build_object(
+						'order_uid', o.order_uid, 'name', d.name, 'phone', d.phone, 'zip', d.zip, 'city', d.city,
+						'address', d.address, 'region', d.region, 'email', d.email),
+					'payment', jsonb_build_object(
+						'order_uid', o.order_uid, 'transaction', p.transaction, 'request_id', p.request_id,
+						'currency', p.currency, 'provider', p.provider, 'amount', p.amount, 'payment_dt', p.payment_dt,
+						'bank', p.bank, 'delivery_cost', p.delivery_cost, 'goods_total', p.goods_total, 'custom_fee', p.custom_fee),
+					'items', COALESCE((SELECT jsonb_agg(jsonb_build_object(
+							'id', i.id, 'order_uid', i.order_uid, 'chrt_id', i.chrt_id, 'track_number', i.track_number,
+							'price', i.price, 'rid', i.rid, 'name', i.name, 'sale', i.sale, 'size', i.size,
+							'total_price', i.total_price, 'nm_id', i.nm_id, 'brand', i.brand, 'status', i.status
+						) ORDER BY i.id) FROM items i WHERE i.order_uid = o.order_uid), '[]'::jsonb)),
+				d.dek, d.key_id, o.erased_at
+			FROM picked
+			JOIN orders o ON o.order_uid = picked.order_uid
+			LEFT JOIN delivery d ON d.order_uid = o.order_uid
+			LEFT JOIN payment p ON p.order_uid = o.order_uid
+			ON CONFLICT (order_uid) DO UPDATE SET
+				customer_id = EXCLUDED.customer_id,
+				date_created = EXCLUDED.date_created,
+				status = EXCLUDED.status,
+				payload = EXCLUDED.payload,
+				dek = EXCLUDED.dek,
+				key_id = EXCLUDED.key_id,
+				erased_at = EXCLUDED.erased_at,
+				archived_at = now()
+			RETURNING order_uid, status
+		), versioned AS (
+			INSERT INTO order_versions (order_uid, version, operation, previous, current, source, status)
+			SELECT a.order_uid, COALESCE(l.version, 0) + 1, $3, l.current, l.current, $4, a.status
+			FROM archived a
+			LEFT JOIN LATERAL (
+				SELECT version, current FROM order_versions
+				WHERE order_uid = a.order_uid
+				ORDER BY version DESC
+				LIMIT 1
+			) l ON true
+		)
+		DELETE FROM orders WHERE order_uid IN (SELECT order_uid FROM archived)
+		RETURNING order_uid`
+
+// ArchiveOrders moves up to limit orders created before the cutoff to the archive, oldest first,
+// and returns their UIDs.
+func (r *OrderPostgresRepository) ArchiveOrders(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	const op = "OrderPostgresRepository.ArchiveOrders"
+
+	rows, err := r.pool.Query(ctx, archiveOrdersQuery, before.UTC(), limit, models.VersionArchived, audit.Retention)
+	if err != nil {
+		r.log.Error("failed to archive orders", "op", op, "err", err)
+		return nil, err
+	}
+	uids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		r.log.Error("failed to archive orders", "op", op, "err", err)
+		return nil, err
+	}
+	r.log.Info("orders archived", "op", op, "count", len(uids))
+	return uids, nil
+}
+
+// GetArchivedOrder returns an archived order or models.ErrOrderNotFound.
+func (r *OrderPostgresRepository) GetArchivedOrder(ctx context.Context, orderUID string) (*models.FullOrder, error) {
+	const op = "OrderPostgresRepository.GetArchivedOrder"
+
+	var (
+		payload    []byte
+		dek, keyID *string
+	)
+	err := r.pool.QueryRow(ctx, `SELECT payload, dek, key_id FROM orders_archive WHERE order_uid = $1`, orderUID).
+		Scan(&payload, &dek, &keyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrOrderNotFound
+	}
+	if err != nil {
+		r.log.Error("failed to get archived order", "op", op, "orderUID", orderUID, "err", err)
+		return nil, err
+	}
+	fo, err := r.decodeArchivedOrder(payload, dek, keyID)
+	if err != nil {
+		r.log.Error("failed to decode archived order", "op", op, "orderUID", orderUID, "err", err)
+		return nil, err
+	}
+	return fo, nil
+}
+
+// GetArchivedOrdersByCustomer returns the archived orders of the customer that were not erased, oldest first.
+func (r *OrderPostgresRepository) GetArchivedOrdersByCustomer(ctx context.Context, customerID string) ([]*models.FullOrder, error) {
+	const op = "OrderPostgresRepository.GetArchivedOrdersByCustomer"
+
+	rows, err := r.pool.Query(ctx, `SELECT payload, dek, key_id FROM orders_archive
+              WHERE customer_id = $1 AND erased_at IS NULL
+              ORDER BY date_created, order_uid`, customerID)
+	if err != nil {
+		r.log.Error("failed to query archived orders", "op", op, "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*models.FullOrder
+	for rows.Next() {
+		var (
+			payload    []byte
+			dek, keyID *string
+		)
+		if err := rows.Scan(&payload, &dek, &keyID); err != nil {
+			r.log.Error("failed to scan archived order", "op", op, "err", err)
+			return nil, err
+		}
+		fo, err := r.decodeArchivedOrder(payload, dek, keyID)
+		if err != nil {
+			r.log.Error("failed to decode archived order", "op", op, "err", err)
+			return nil, err
+		}
+		orders = append(orders, fo)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error("failed to iterate archived orders", "op", op, "err", err)
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *OrderPostgresRepository) decodeArchivedOrder(payload []byte, dek, keyID *string) (*models.FullOrder, error) {
+	var fo models.FullOrder
+	if err := json.Unmarshal(payload, &fo); err != nil {
+		return nil, err
+	}
+	if err := r.decryptDelivery(&fo.Delivery, dek, keyID); err != nil {
+		return nil, fmt.Errorf("order %s: %w", fo.Order.OrderUID, err)
+	}
+	return &fo, nil
+}
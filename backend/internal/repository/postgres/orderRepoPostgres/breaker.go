@@ -0,0 +1,183 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"time"
+	"wbL0/internal/lib/breaker"
+	"wbL0/internal/models"
+)
+
+// breakerRepo fails fast with a *breaker.OpenError while Postgres is unreachable or timing out, so retries
+// of the consumer and cache misses of the API do not pile more load on a struggling database.
+// Query errors such as constraint violations or missing rows do not count as failures.
+type breakerRepo struct {
+	next OrderPostgresRepositoryInterface
+	cb   *breaker.Breaker
+}
+
+// WithBreaker wraps the repository with the circuit breaker. A nil breaker returns repo unchanged.
+func WithBreaker(repo OrderPostgresRepositoryInterface, cb *breaker.Breaker) OrderPostgresRepositoryInterface {
+	if cb == nil {
+		return repo
+	}
+	return &breakerRepo{next: repo, cb: cb}
+}
+
+func call[T any](cb *breaker.Breaker, fn func() (T, error)) (T, error) {
+	if err := cb.Allow(); err != nil {
+		var zero T
+		return zero, err
+	}
+	v, err := fn()
+	cb.Done(IsUnavailable(err))
+	return v, err
+}
+
+func exec(cb *breaker.Breaker, fn func() error) error {
+	_, err := call(cb, func() (struct{}, error) { return struct{}{}, fn() })
+	return err
+}
+
+func (r *breakerRepo) BeginTx(ctx context.Context) (PgxTx, error) {
+	return call(r.cb, func() (PgxTx, error) { return r.next.BeginTx(ctx) })
+}
+
+func (r *breakerRepo) SaveOrderDataTx(ctx context.Context, tx PgxTx, order *models.Order, checksum string) (models.SaveOutcome, error) {
+	return call(r.cb, func() (models.SaveOutcome, error) { return r.next.SaveOrderDataTx(ctx, tx, order, checksum) })
+}
+
+func (r *breakerRepo) SaveDeliveryDataTx(ctx context.Context, tx PgxTx, delivery *models.Delivery) error {
+	return exec(r.cb, func() error { return r.next.SaveDeliveryDataTx(ctx, tx, delivery) })
+}
+
+func (r *breakerRepo) SavePaymentDataTx(ctx context.Context, tx PgxTx, payment *models.Payment) error {
+	return exec(r.cb, func() error { return r.next.SavePaymentDataTx(ctx, tx, payment) })
+}
+
+func (r *breakerRepo) DeleteItemsTx(ctx context.Context, tx PgxTx, orderUID string) error {
+	return exec(r.cb, func() error { return r.next.DeleteItemsTx(ctx, tx, orderUID) })
+}
+
+func (r *breakerRepo) SaveItemsDataTx(ctx context.Context, tx PgxTx, item *models.Item) error {
+	return exec(r.cb, func() error { return r.next.SaveItemsDataTx(ctx, tx, item) })
+}
+
+func (r *breakerRepo) SaveOrdersBatchTx(ctx context.Context, tx PgxTx, orders []*models.FullOrder, checksums []string) ([]models.SaveOutcome, error) {
+	return call(r.cb, func() ([]models.SaveOutcome, error) { return r.next.SaveOrdersBatchTx(ctx, tx, orders, checksums) })
+}
+
+func (r *breakerRepo) SaveOutboxEventsTx(ctx context.Context, tx PgxTx, events []models.OutboxEvent) error {
+	return exec(r.cb, func() error { return r.next.SaveOutboxEventsTx(ctx, tx, events) })
+}
+
+func (r *breakerRepo) DeleteOrderTx(ctx context.Context, tx PgxTx, orderUID string) error {
+	return exec(r.cb, func() error { return r.next.DeleteOrderTx(ctx, tx, orderUID) })
+}
+
+func (r *breakerRepo) GetOrderInfoByUid(ctx context.Context, orderUID string) (*models.Order, error) {
+	return call(r.cb, func() (*models.Order, error) { return r.next.GetOrderInfoByUid(ctx, orderUID) })
+}
+
+func (r *breakerRepo) GetAllFullOrders(ctx context.Context) ([]*models.FullOrder, error) {
+	return call(r.cb, func() ([]*models.FullOrder, error) { return r.next.GetAllFullOrders(ctx) })
+}
+
+func (r *breakerRepo) GetFullOrdersBatch(ctx context.Context, after *models.OrderCursor, since *time.Time, limit int) ([]*models.FullOrder, *models.OrderCursor, error) {
+	var next *models.OrderCursor
+	orders, err := call(r.cb, func() (orders []*models.FullOrder, err error) {
+		orders, next, err = r.next.GetFullOrdersBatch(ctx, after, since, limit)
+		return orders, err
+	})
+	return orders, next, err
+}
+
+func (r *breakerRepo) GetFullOrderByUID(ctx context.Context, orderUID string) (*models.FullOrder, error) {
+	return call(r.cb, func() (*models.FullOrder, error) { return r.next.GetFullOrderByUID(ctx, orderUID) })
+}
+
+func (r *breakerRepo) GetFullOrdersByUIDs(ctx context.Context, uids []string) ([]*models.FullOrder, error) {
+	return call(r.cb, func() ([]*models.FullOrder, error) { return r.next.GetFullOrdersByUIDs(ctx, uids) })
+}
+
+func (r *breakerRepo) ListOrders(ctx context.Context, filter models.OrderFilter) ([]models.OrderSummary, error) {
+	return call(r.cb, func() ([]models.OrderSummary, error) { return r.next.ListOrders(ctx, filter) })
+}
+
+func (r *breakerRepo) GetOrderStatusForUpdateTx(ctx context.Context, tx PgxTx, orderUID string) (models.OrderStatus, error) {
+	return call(r.cb, func() (models.OrderStatus, error) { return r.next.GetOrderStatusForUpdateTx(ctx, tx, orderUID) })
+}
+
+func (r *breakerRepo) SaveStatusChangeTx(ctx context.Context, tx PgxTx, change *models.StatusChange) error {
+	return exec(r.cb, func() error { return r.next.SaveStatusChangeTx(ctx, tx, change) })
+}
+
+func (r *breakerRepo) GetOrderStatusHistory(ctx context.Context, orderUID string) (*models.OrderStatusHistory, error) {
+	return call(r.cb, func() (*models.OrderStatusHistory, error) { return r.next.GetOrderStatusHistory(ctx, orderUID) })
+}
+
+func (r *breakerRepo) SaveOrderVersionsTx(ctx context.Context, tx PgxTx, versions []models.OrderVersion) error {
+	return exec(r.cb, func() error { return r.next.SaveOrderVersionsTx(ctx, tx, versions) })
+}
+
+func (r *breakerRepo) ListOrderVersions(ctx context.Context, orderUID string) ([]models.OrderVersion, error) {
+	return call(r.cb, func() ([]models.OrderVersion, error) { return r.next.ListOrderVersions(ctx, orderUID) })
+}
+
+func (r *breakerRepo) GetOrderVersion(ctx context.Context, orderUID string, version int) (*models.OrderVersion, error) {
+	return call(r.cb, func() (*models.OrderVersion, error) { return r.next.GetOrderVersion(ctx, orderUID, version) })
+}
+
+func (r *breakerRepo) GetOrderUIDsByCustomer(ctx context.Context, customerID string) ([]string, error) {
+	return call(r.cb, func() ([]string, error) { return r.next.GetOrderUIDsByCustomer(ctx, customerID) })
+}
+
+func (r *breakerRepo) EraseCustomerTx(ctx context.Context, tx PgxTx, customerID string, erasure *models.CustomerErasure) error {
+	return exec(r.cb, func() error { return r.next.EraseCustomerTx(ctx, tx, customerID, erasure) })
+}
+
+func (r *breakerRepo) GetErasedOrderUIDs(ctx context.Context, uids []string) ([]string, error) {
+	return call(r.cb, func() ([]string, error) { return r.next.GetErasedOrderUIDs(ctx, uids) })
+}
+
+func (r *breakerRepo) GetRecentlyErasedOrderUIDs(ctx context.Context, window time.Duration) ([]string, error) {
+	return call(r.cb, func() ([]string, error) { return r.next.GetRecentlyErasedOrderUIDs(ctx, window) })
+}
+
+func (r *breakerRepo) GetPendingEvictions(ctx context.Context, limit int) ([]string, error) {
+	return call(r.cb, func() ([]string, error) { return r.next.GetPendingEvictions(ctx, limit) })
+}
+
+func (r *breakerRepo) SavePendingEvictions(ctx context.Context, uids []string) error {
+	return exec(r.cb, func() error { return r.next.SavePendingEvictions(ctx, uids) })
+}
+
+func (r *breakerRepo) DeletePendingEvictions(ctx context.Context, uids []string) error {
+	return exec(r.cb, func() error { return r.next.DeletePendingEvictions(ctx, uids) })
+}
+
+// archiveBreakerRepo puts archive reads and the archiving job behind the same breaker as the hot tables:
+// they share the database, so they must not keep hitting it while the breaker is open.
+type archiveBreakerRepo struct {
+	next ArchiveRepositoryInterface
+	cb   *breaker.Breaker
+}
+
+// WithArchiveBreaker wraps the archive repository with the circuit breaker. A nil breaker returns repo unchanged.
+func WithArchiveBreaker(repo ArchiveRepositoryInterface, cb *breaker.Breaker) ArchiveRepositoryInterface {
+	if cb == nil {
+		return repo
+	}
+	return &archiveBreakerRepo{next: repo, cb: cb}
+}
+
+func (r *archiveBreakerRepo) ArchiveOrders(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	return call(r.cb, func() ([]string, error) { return r.next.ArchiveOrders(ctx, before, limit) })
+}
+
+func (r *archiveBreakerRepo) GetArchivedOrder(ctx context.Context, orderUID string) (*models.FullOrder, error) {
+	return call(r.cb, func() (*models.FullOrder, error) { return r.next.GetArchivedOrder(ctx, orderUID) })
+}
+
+func (r *archiveBreakerRepo) GetArchivedOrdersByCustomer(ctx context.Context, customerID string) ([]*models.FullOrder, error) {
+	return call(r.cb, func() ([]*models.FullOrder, error) { return r.next.GetArchivedOrdersByCustomer(ctx, customerID) })
+}
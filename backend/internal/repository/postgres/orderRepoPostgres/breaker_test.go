@@ -0,0 +1,90 @@
+package orderRepoPostgres_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"wbL0/internal/lib/breaker"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	"wbL0/internal/repository/postgres/orderRepoPostgres"
+)
+
+func TestWithBreaker(t *testing.T) {
+	ctx := context.Background()
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: assert.AnError}
+
+	t.Run("connection failures open the breaker", func(t *testing.T) {
+		next := &mocks.OrderPostgresRepositoryInterface{}
+		next.On("GetFullOrderByUID", mock.Anything, "o1").Return(nil, refused).Times(2)
+		repo := orderRepoPostgres.WithBreaker(next, breaker.New("postgres", breaker.Settings{FailureThreshold: 2, OpenTimeout: time.Minute}))
+
+		for i := 0; i < 2; i++ {
+			_, err := repo.GetFullOrderByUID(ctx, "o1")
+			assert.ErrorIs(t, err, refused)
+		}
+
+		_, err := repo.GetFullOrderByUID(ctx, "o1")
+		assert.ErrorIs(t, err, breaker.ErrOpen)
+		_, err = repo.BeginTx(ctx)
+		assert.ErrorIs(t, err, breaker.ErrOpen, "the breaker covers every method")
+		retryAfter, ok := breaker.RetryAfter(err)
+		assert.True(t, ok)
+		assert.Positive(t, retryAfter)
+		next.AssertExpectations(t)
+	})
+
+	t.Run("query errors keep the breaker closed", func(t *testing.T) {
+		next := &mocks.OrderPostgresRepositoryInterface{}
+		next.On("GetFullOrderByUID", mock.Anything, "missing").Return(nil, models.ErrOrderNotFound).Times(3)
+		next.On("SaveOrderDataTx", mock.Anything, mock.Anything, mock.Anything, "sum").
+			Return(models.SaveOutcome(""), &pgconn.PgError{Code: "23505"}).Times(3)
+		repo := orderRepoPostgres.WithBreaker(next, breaker.New("postgres", breaker.Settings{FailureThreshold: 2}))
+
+		for i := 0; i < 3; i++ {
+			_, err := repo.GetFullOrderByUID(ctx, "missing")
+			assert.ErrorIs(t, err, models.ErrOrderNotFound)
+			_, err = repo.SaveOrderDataTx(ctx, nil, &models.Order{}, "sum")
+			assert.Error(t, err)
+			assert.NotErrorIs(t, err, breaker.ErrOpen)
+		}
+		next.AssertExpectations(t)
+	})
+
+	t.Run("multiple results pass through", func(t *testing.T) {
+		next := &mocks.OrderPostgresRepositoryInterface{}
+		cursor := &models.OrderCursor{OrderUID: "o2"}
+		orders := []*models.FullOrder{{Order: models.Order{OrderUID: "o2"}}}
+		next.On("GetFullOrdersBatch", mock.Anything, (*models.OrderCursor)(nil), (*time.Time)(nil), 10).Return(orders, cursor, nil)
+		repo := orderRepoPostgres.WithBreaker(next, breaker.New("postgres", breaker.Settings{}))
+
+		got, gotCursor, err := repo.GetFullOrdersBatch(ctx, nil, nil, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, orders, got)
+		assert.Equal(t, cursor, gotCursor)
+	})
+	t.Run("archive shares the breaker", func(t *testing.T) {
+		next := &mocks.OrderPostgresRepositoryInterface{}
+		next.On("GetFullOrderByUID", mock.Anything, "o1").Return(nil, refused).Once()
+		archive := &mocks.ArchiveRepositoryInterface{}
+		cb := breaker.New("postgres", breaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute})
+		repo := orderRepoPostgres.WithBreaker(next, cb)
+		archiveRepo := orderRepoPostgres.WithArchiveBreaker(archive, cb)
+
+		_, err := repo.GetFullOrderByUID(ctx, "o1")
+		assert.ErrorIs(t, err, refused)
+
+		_, err = archiveRepo.GetArchivedOrder(ctx, "o1")
+		assert.ErrorIs(t, err, breaker.ErrOpen)
+		_, err = archiveRepo.ArchiveOrders(ctx, time.Now(), 10)
+		assert.ErrorIs(t, err, breaker.ErrOpen)
+		archive.AssertNotCalled(t, "GetArchivedOrder", mock.Anything, mock.Anything)
+		archive.AssertNotCalled(t, "ArchiveOrders", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,78 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+const (
+	erasedOrderUIDsQuery = `SELECT order_uid FROM orders WHERE order_uid = ANY($1) AND erased_at IS NOT NULL
+		UNION ALL
+		SELECT order_uid FROM orders_archive WHERE order_uid = ANY($1) AND erased_at IS NOT NULL`
+	recentlyErasedOrderUIDsQuery = `SELECT DISTINCT unnest(order_uids) FROM customer_erasures
+		WHERE erased_at > now() - make_interval(secs => $1)`
+	insertPendingEvictionsQuery = `INSERT INTO cache_evictions (order_uid) SELECT unnest($1::text[])
+		ON CONFLICT (order_uid) DO NOTHING`
+)
+
+// GetErasedOrderUIDs returns which of the orders, hot or archived, were erased.
+func (r *OrderPostgresRepository) GetErasedOrderUIDs(ctx context.Context, uids []string) ([]string, error) {
+	const op = "OrderPostgresRepository.GetErasedOrderUIDs"
+	return r.queryUIDs(ctx, op, erasedOrderUIDsQuery, uids)
+}
+
+// GetRecentlyErasedOrderUIDs returns the orders erased within the last window, which lets every instance
+// drop them from its local tier.
+func (r *OrderPostgresRepository) GetRecentlyErasedOrderUIDs(ctx context.Context, window time.Duration) ([]string, error) {
+	const op = "OrderPostgresRepository.GetRecentlyErasedOrderUIDs"
+	return r.queryUIDs(ctx, op, recentlyErasedOrderUIDsQuery, window.Seconds())
+}
+
+// GetPendingEvictions returns up to limit orders still waiting to be evicted from Redis, oldest first.
+func (r *OrderPostgresRepository) GetPendingEvictions(ctx context.Context, limit int) ([]string, error) {
+	const op = "OrderPostgresRepository.GetPendingEvictions"
+	return r.queryUIDs(ctx, op, `SELECT order_uid FROM cache_evictions ORDER BY created_at, order_uid LIMIT $1`, limit)
+}
+
+// SavePendingEvictions queues the orders for eviction from Redis. Already queued orders are kept as they are.
+func (r *OrderPostgresRepository) SavePendingEvictions(ctx context.Context, uids []string) error {
+	const op = "OrderPostgresRepository.SavePendingEvictions"
+
+	if len(uids) == 0 {
+		return nil
+	}
+	if _, err := r.pool.Exec(ctx, insertPendingEvictionsQuery, uids); err != nil {
+		r.log.Error("failed to save pending evictions", "op", op, "count", len(uids), "err", err)
+		return err
+	}
+	return nil
+}
+
+// DeletePendingEvictions removes the orders from the eviction queue once they were evicted.
+func (r *OrderPostgresRepository) DeletePendingEvictions(ctx context.Context, uids []string) error {
+	const op = "OrderPostgresRepository.DeletePendingEvictions"
+
+	if len(uids) == 0 {
+		return nil
+	}
+	if _, err := r.pool.Exec(ctx, `DELETE FROM cache_evictions WHERE order_uid = ANY($1)`, uids); err != nil {
+		r.log.Error("failed to delete pending evictions", "op", op, "count", len(uids), "err", err)
+		return err
+	}
+	return nil
+}
+
+func (r *OrderPostgresRepository) queryUIDs(ctx context.Context, op, query string, args ...any) ([]string, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.log.Error("failed to query order uids", "op", op, "err", err)
+		return nil, err
+	}
+	uids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		r.log.Error("failed to scan order uids", "op", op, "err", err)
+		return nil, err
+	}
+	return uids, nil
+}
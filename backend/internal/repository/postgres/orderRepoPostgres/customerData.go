@@ -0,0 +1,94 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"wbL0/internal/models"
+)
+
+const (
+	// eraseOrdersQuery anonymizes the hot and the archived orders of the customer and returns their UIDs.
+	eraseOrdersQuery = `WITH hot AS (
+			UPDATE orders SET customer_id = $2, internal_signature = '', payload_hash = NULL, erased_at = now()
+			WHERE customer_id = $1 AND erased_at IS NULL
+			RETURNING order_uid
+		), archived AS (
+			UPDATE orders_archive SET customer_id = $2, dek = NULL, key_id = NULL, erased_at = now(),
+				payload = jsonb_set(
+					jsonb_set(jsonb_set(payload, '{order,customer_id}', to_jsonb($2::text)), '{order,internal_signature}', '""'),
+					'{delivery}', jsonb_build_object('order_uid', order_uid, 'name', '', 'phone', '', 'zip', 0,
+						'city', '', 'address', '', 'region', '', 'email', ''))
+			WHERE customer_id = $1 AND erased_at IS NULL
+			RETURNING order_uid
+		)
+		SELECT COALESCE(array_agg(order_uid ORDER BY order_uid), '{}')
+		FROM (SELECT order_uid FROM hot UNION ALL SELECT order_uid FROM archived) erased`
+	eraseDeliveriesQuery = `UPDATE delivery
+		SET name = '', phone = '', zip = 0, city = '', address = '', region = '', email = '',
+			dek = NULL, key_id = NULL, email_bidx = NULL, phone_bidx = NULL
+		WHERE order_uid = ANY($1)`
+	eraseVersionSnapshotsQuery = `UPDATE order_versions SET previous = NULL, current = NULL WHERE order_uid = ANY($1)`
+	eraseOutboxPayloadsQuery   = `UPDATE order_outbox SET payload = jsonb_set(payload, '{customer_id}', to_jsonb($2::text))
+		WHERE order_uid = ANY($1) AND payload ? 'customer_id'`
+	insertErasedOrdersQuery = `INSERT INTO erased_orders (order_uid) SELECT unnest($1::text[])
+		ON CONFLICT (order_uid) DO NOTHING`
+	insertErasureQuery = `INSERT INTO customer_erasures (customer_hash, order_uids, requested_by, reason)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id, erased_at`
+)
+
+// GetOrderUIDsByCustomer returns the UIDs of the customer's orders that were not erased, oldest first.
+func (r *OrderPostgresRepository) GetOrderUIDsByCustomer(ctx context.Context, customerID string) ([]string, error) {
+	const op = "OrderPostgresRepository.GetOrderUIDsByCustomer"
+
+	rows, err := r.pool.Query(ctx, `SELECT order_uid FROM orders
+              WHERE customer_id = $1 AND erased_at IS NULL
+              ORDER BY date_created, order_uid`, customerID)
+	if err != nil {
+		r.log.Error("failed to query customer orders", "op", op, "err", err)
+		return nil, err
+	}
+	uids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		r.log.Error("failed to scan customer orders", "op", op, "err", err)
+		return nil, err
+	}
+	return uids, nil
+}
+
+// EraseCustomerTx anonymizes the personal data of the customer's orders, archived ones included: the customer ID,
+// the internal signature, the delivery, version snapshots and pending outbox payloads. Payment and items are kept for accounting.
+// The erasure is added to the audit log, filling erasure's ID, order UIDs and time, the orders are queued for eviction
+// from Redis and get a tombstone that keeps them from being ingested again.
+func (r *OrderPostgresRepository) EraseCustomerTx(ctx context.Context, tx PgxTx, customerID string, erasure *models.CustomerErasure) error {
+	const op = "OrderPostgresRepository.EraseCustomerTx"
+
+	var uids []string
+	if err := tx.QueryRow(ctx, eraseOrdersQuery, customerID, models.ErasedValue).Scan(&uids); err != nil {
+		r.log.Error("failed to erase customer orders", "op", op, "err", err)
+		return err
+	}
+
+	if len(uids) > 0 {
+		batch := &pgx.Batch{}
+		batch.Queue(eraseDeliveriesQuery, uids)
+		batch.Queue(eraseVersionSnapshotsQuery, uids)
+		batch.Queue(eraseOutboxPayloadsQuery, uids, models.ErasedValue)
+		batch.Queue(insertPendingEvictionsQuery, uids)
+		batch.Queue(insertErasedOrdersQuery, uids)
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			r.log.Error("failed to erase customer order data", "op", op, "count", len(uids), "err", err)
+			return err
+		}
+	}
+
+	erasure.CustomerHash = models.CustomerHash(customerID)
+	erasure.OrderUIDs = uids
+	if err := tx.QueryRow(ctx, insertErasureQuery, erasure.CustomerHash, uids, erasure.RequestedBy, erasure.Reason).
+		Scan(&erasure.ID, &erasure.ErasedAt); err != nil {
+		r.log.Error("failed to record erasure", "op", op, "err", err)
+		return err
+	}
+	r.log.Info("customer data erased", "op", op, "erasureID", erasure.ID, "count", len(uids))
+	return nil
+}
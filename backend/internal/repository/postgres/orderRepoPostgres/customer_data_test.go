@@ -0,0 +1,90 @@
+package orderRepoPostgres_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	orderRepoPostgres "wbL0/internal/repository/postgres/orderRepoPostgres"
+)
+
+type scanFunc func(dest ...any) error
+
+func (f scanFunc) Scan(dest ...any) error { return f(dest...) }
+
+func TestEraseCustomerTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := orderRepoPostgres.NewPostgresRepository(nil, slog.Default())
+	erasedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	recordErasure := func(tx *mocks.PgxTx, uids []string) {
+		tx.On("QueryRow", ctx, mock.MatchedBy(func(sql string) bool { return containsAll(sql, "INSERT INTO customer_erasures") }),
+			models.CustomerHash("c1"), uids, "http:admin", "request #1").
+			Return(scanFunc(func(dest ...any) error {
+				*dest[0].(*int64) = 3
+				*dest[1].(*time.Time) = erasedAt
+				return nil
+			})).Once()
+	}
+
+	t.Run("anonymizes the orders and records the erasure", func(t *testing.T) {
+		uids := []string{"o1", "o2"}
+		tx := mocks.NewPgxTx(t)
+		tx.On("QueryRow", ctx, mock.MatchedBy(func(sql string) bool { return containsAll(sql, "UPDATE orders", "erased_at IS NULL") }),
+			"c1", models.ErasedValue).
+			Return(scanFunc(func(dest ...any) error {
+				*dest[0].(*[]string) = uids
+				return nil
+			})).Once()
+		tx.On("SendBatch", ctx, mock.MatchedBy(func(b *pgx.Batch) bool {
+			return b.Len() == 5 &&
+				containsAll(b.QueuedQueries[0].SQL, "UPDATE delivery", "dek = NULL") &&
+				containsAll(b.QueuedQueries[1].SQL, "UPDATE order_versions") &&
+				containsAll(b.QueuedQueries[2].SQL, "UPDATE order_outbox") &&
+				containsAll(b.QueuedQueries[3].SQL, "INSERT INTO cache_evictions") &&
+				containsAll(b.QueuedQueries[4].SQL, "INSERT INTO erased_orders")
+		})).Return(&fakeBatchResults{}).Once()
+		recordErasure(tx, uids)
+
+		erasure := &models.CustomerErasure{RequestedBy: "http:admin", Reason: "request #1"}
+		require.NoError(t, repo.EraseCustomerTx(ctx, tx, "c1", erasure))
+		assert.Equal(t, int64(3), erasure.ID)
+		assert.Equal(t, uids, erasure.OrderUIDs)
+		assert.Equal(t, erasedAt, erasure.ErasedAt)
+		assert.NotContains(t, erasure.CustomerHash, "c1")
+	})
+
+	t.Run("customer without orders is only recorded", func(t *testing.T) {
+		tx := mocks.NewPgxTx(t)
+		tx.On("QueryRow", ctx, mock.MatchedBy(func(sql string) bool { return containsAll(sql, "UPDATE orders") }), "c1", models.ErasedValue).
+			Return(scanFunc(func(dest ...any) error {
+				*dest[0].(*[]string) = []string{}
+				return nil
+			})).Once()
+		recordErasure(tx, []string{})
+
+		erasure := &models.CustomerErasure{RequestedBy: "http:admin", Reason: "request #1"}
+		require.NoError(t, repo.EraseCustomerTx(ctx, tx, "c1", erasure))
+		assert.Empty(t, erasure.OrderUIDs)
+	})
+}
+
+func containsAll(s string, parts ...string) bool {
+	for _, p := range parts {
+		if !strings.Contains(s, p) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,387 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"wbL0/internal/encryption"
+	"wbL0/internal/models"
+)
+
+// deliveryArgs returns the arguments of upsertDeliveryQuery. With encryption enabled name, phone, address and
+// email are encrypted with a fresh data key and email and phone get blind indexes; otherwise the key columns are NULL.
+func (r *OrderPostgresRepository) deliveryArgs(delivery *models.Delivery) ([]any, error) {
+	args := []any{
+		delivery.OrderUID,
+		delivery.Name,
+		delivery.Phone,
+		delivery.Zip,
+		delivery.City,
+		delivery.Address,
+		delivery.Region,
+		delivery.Email,
+		nil, nil, nil, nil,
+	}
+	if r.keys == nil {
+		return args, nil
+	}
+
+	dk, err := r.keys.NewDataKey()
+	if err != nil {
+		return nil, err
+	}
+	sealed := *delivery
+	if err := encryptDelivery(dk, &sealed); err != nil {
+		return nil, err
+	}
+	args[1], args[2], args[5], args[7] = sealed.Name, sealed.Phone, sealed.Address, sealed.Email
+	args[8], args[9] = dk.Wrapped, dk.KeyID
+	args[10] = nullableString(r.keys.BlindIndex(encryption.IndexEmail, delivery.Email))
+	args[11] = nullableString(r.keys.BlindIndex(encryption.IndexPhone, delivery.Phone))
+	return args, nil
+}
+
+// deliveryFields lists the encrypted columns. The order UID and the column name are the associated data,
+// so a ciphertext moved to another row or column fails to decrypt.
+func deliveryFields(d *models.Delivery) map[string]*string {
+	return map[string]*string{
+		"name":    &d.Name,
+		"phone":   &d.Phone,
+		"address": &d.Address,
+		"email":   &d.Email,
+	}
+}
+
+func fieldAAD(orderUID, column string) string {
+	return "delivery/" + orderUID + "/" + column
+}
+
+func encryptDelivery(dk *encryption.DataKey, d *models.Delivery) error {
+	for column, value := range deliveryFields(d) {
+		encrypted, err := dk.Encrypt(*value, fieldAAD(d.OrderUID, column))
+		if err != nil {
+			return fmt.Errorf("encrypt delivery %s: %w", column, err)
+		}
+		*value = encrypted
+	}
+	return nil
+}
+
+// decryptDelivery decrypts a delivery row read with its dek and key_id columns. Rows without a data key
+// were written before encryption was enabled and are returned as they are.
+func (r *OrderPostgresRepository) decryptDelivery(d *models.Delivery, wrapped, keyID *string) error {
+	_, err := r.openDelivery(d, wrapped, keyID)
+	return err
+}
+
+// openDelivery decrypts the row in place and returns its data key, or nil for a plaintext row.
+func (r *OrderPostgresRepository) openDelivery(d *models.Delivery, wrapped, keyID *string) (*encryption.DataKey, error) {
+	if wrapped == nil || keyID == nil {
+		return nil, nil
+	}
+	if r.keys == nil {
+		return nil, errors.New("delivery is encrypted but encryption is not configured")
+	}
+	dk, err := r.keys.OpenDataKey(*keyID, *wrapped)
+	if err != nil {
+		return nil, err
+	}
+	for column, value := range deliveryFields(d) {
+		plaintext, err := dk.Decrypt(*value, fieldAAD(d.OrderUID, column))
+		if err != nil {
+			return nil, fmt.Errorf("decrypt delivery %s: %w", column, err)
+		}
+		*value = plaintext
+	}
+	return dk, nil
+}
+
+func nullableString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+const (
+	selectDeliveriesToReencryptQuery = `SELECT order_uid, name, phone, address, email, dek, key_id
+		FROM delivery WHERE key_id IS DISTINCT FROM $1
+		ORDER BY order_uid LIMIT $2
+		FOR UPDATE SKIP LOCKED`
+	updateReencryptedDeliveryQuery = `UPDATE delivery
+		SET name = $2, phone = $3, address = $4, email = $5, dek = $6, key_id = $7, email_bidx = $8, phone_bidx = $9
+		WHERE order_uid = $1`
+	selectArchiveToReencryptQuery = `SELECT order_uid, payload, dek, key_id
+		FROM orders_archive WHERE key_id IS DISTINCT FROM $1 AND erased_at IS NULL
+		ORDER BY order_uid LIMIT $2
+		FOR UPDATE SKIP LOCKED`
+	updateReencryptedArchiveQuery  = `UPDATE orders_archive SET payload = $2, dek = $3, key_id = $4 WHERE order_uid = $1`
+	selectDeliveriesToDecryptQuery = `SELECT order_uid, name, phone, address, email, dek, key_id
+		FROM delivery WHERE dek IS NOT NULL
+		ORDER BY order_uid LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+	updateDecryptedDeliveryQuery = `UPDATE delivery
+		SET name = $2, phone = $3, address = $4, email = $5, dek = NULL, key_id = NULL, email_bidx = NULL, phone_bidx = NULL
+		WHERE order_uid = $1`
+	selectArchiveToDecryptQuery = `SELECT order_uid, payload, dek, key_id
+		FROM orders_archive WHERE dek IS NOT NULL
+		ORDER BY order_uid LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+	updateDecryptedArchiveQuery = `UPDATE orders_archive SET payload = $2, dek = NULL, key_id = NULL WHERE order_uid = $1`
+)
+
+// ReencryptDeliveries moves every delivery row and archived order to the active key, batchSize rows per
+// transaction, and returns the number of rewritten rows. Rows of a retired key keep their data key, which is
+// rewrapped by the active key; plaintext rows get a new data key. Blind indexes are refreshed for every
+// delivery row. Rows locked by concurrent writers are skipped and picked up by the next run.
+func (r *OrderPostgresRepository) ReencryptDeliveries(ctx context.Context, batchSize int) (int, error) {
+	const op = "OrderPostgresRepository.ReencryptDeliveries"
+
+	if r.keys == nil {
+		return 0, errors.New("encryption is not configured")
+	}
+	return r.rewriteInBatches(ctx, op, batchSize, []rewriteTable{
+		{"delivery", r.reencryptDeliveriesBatch},
+		{"orders_archive", r.reencryptArchiveBatch},
+	})
+}
+
+// DecryptDeliveries writes every encrypted delivery row and archived order back as plaintext and clears its
+// data key and blind indexes, batchSize rows per transaction, and returns the number of rewritten rows.
+// It prepares rolling back the delivery encryption migration and must run while the service is stopped.
+func (r *OrderPostgresRepository) DecryptDeliveries(ctx context.Context, batchSize int) (int, error) {
+	const op = "OrderPostgresRepository.DecryptDeliveries"
+
+	if r.keys == nil {
+		return 0, errors.New("encryption is not configured")
+	}
+	return r.rewriteInBatches(ctx, op, batchSize, []rewriteTable{
+		{"delivery", r.decryptDeliveriesBatch},
+		{"orders_archive", r.decryptArchiveBatch},
+	})
+}
+
+type rewriteTable struct {
+	name  string
+	batch func(ctx context.Context, batchSize int) (int, error)
+}
+
+// rewriteInBatches runs the batches of every table until one returns less than batchSize rows.
+func (r *OrderPostgresRepository) rewriteInBatches(ctx context.Context, op string, batchSize int, tables []rewriteTable) (int, error) {
+	total := 0
+	for _, table := range tables {
+		for {
+			n, err := table.batch(ctx, batchSize)
+			total += n
+			if err != nil {
+				r.log.Error("failed to rewrite rows", "op", op, "table", table.name, "rewritten", total, "err", err)
+				return total, err
+			}
+			r.log.Info("rows rewritten", "op", op, "table", table.name, "batch", n, "total", total, "keyID", r.keys.ActiveKeyID())
+			if n < batchSize {
+				break
+			}
+		}
+	}
+	return total, nil
+}
+
+type deliveryRow struct {
+	delivery models.Delivery
+	wrapped  *string
+	keyID    *string
+}
+
+func queryDeliveryRows(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]deliveryRow, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []deliveryRow
+	for rows.Next() {
+		var row deliveryRow
+		d := &row.delivery
+		if err := rows.Scan(&d.OrderUID, &d.Name, &d.Phone, &d.Address, &d.Email, &row.wrapped, &row.keyID); err != nil {
+			return nil, err
+		}
+		batch = append(batch, row)
+	}
+	return batch, rows.Err()
+}
+
+type archiveRow struct {
+	orderUID string
+	payload  []byte
+	wrapped  *string
+	keyID    *string
+}
+
+func queryArchiveRows(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]archiveRow, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []archiveRow
+	for rows.Next() {
+		var row archiveRow
+		if err := rows.Scan(&row.orderUID, &row.payload, &row.wrapped, &row.keyID); err != nil {
+			return nil, err
+		}
+		batch = append(batch, row)
+	}
+	return batch, rows.Err()
+}
+
+func (r *OrderPostgresRepository) reencryptDeliveriesBatch(ctx context.Context, batchSize int) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	batch, err := queryDeliveryRows(ctx, tx, selectDeliveriesToReencryptQuery, r.keys.ActiveKeyID(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range batch {
+		plain, sealed, dk, err := r.reencryptDelivery(row)
+		if err != nil {
+			return 0, fmt.Errorf("order %s: %w", row.delivery.OrderUID, err)
+		}
+		if _, err := tx.Exec(ctx, updateReencryptedDeliveryQuery,
+			plain.OrderUID, sealed.Name, sealed.Phone, sealed.Address, sealed.Email, dk.Wrapped, dk.KeyID,
+			nullableString(r.keys.BlindIndex(encryption.IndexEmail, plain.Email)),
+			nullableString(r.keys.BlindIndex(encryption.IndexPhone, plain.Phone)),
+		); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
+
+func (r *OrderPostgresRepository) decryptDeliveriesBatch(ctx context.Context, batchSize int) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	batch, err := queryDeliveryRows(ctx, tx, selectDeliveriesToDecryptQuery, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range batch {
+		plain := row.delivery
+		if err := r.decryptDelivery(&plain, row.wrapped, row.keyID); err != nil {
+			return 0, fmt.Errorf("order %s: %w", plain.OrderUID, err)
+		}
+		if _, err := tx.Exec(ctx, updateDecryptedDeliveryQuery,
+			plain.OrderUID, plain.Name, plain.Phone, plain.Address, plain.Email); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
+
+// reencryptDelivery returns the plaintext delivery of the row, the delivery encrypted for the active key
+// and the data key used.
+func (r *OrderPostgresRepository) reencryptDelivery(row deliveryRow) (plain, sealed models.Delivery, dk *encryption.DataKey, err error) {
+	plain = row.delivery
+	old, err := r.openDelivery(&plain, row.wrapped, row.keyID)
+	if err != nil {
+		return plain, sealed, nil, err
+	}
+
+	if old != nil {
+		dk, err = r.keys.Rewrap(old)
+	} else {
+		dk, err = r.keys.NewDataKey()
+	}
+	if err != nil {
+		return plain, sealed, nil, err
+	}
+
+	sealed = plain
+	err = encryptDelivery(dk, &sealed)
+	return plain, sealed, dk, err
+}
+
+func (r *OrderPostgresRepository) reencryptArchiveBatch(ctx context.Context, batchSize int) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	batch, err := queryArchiveRows(ctx, tx, selectArchiveToReencryptQuery, r.keys.ActiveKeyID(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range batch {
+		var fo models.FullOrder
+		if err := json.Unmarshal(row.payload, &fo); err != nil {
+			return 0, fmt.Errorf("order %s: %w", row.orderUID, err)
+		}
+		_, sealed, dk, err := r.reencryptDelivery(deliveryRow{delivery: fo.Delivery, wrapped: row.wrapped, keyID: row.keyID})
+		if err != nil {
+			return 0, fmt.Errorf("order %s: %w", row.orderUID, err)
+		}
+		fo.Delivery = sealed
+		payload, err := json.Marshal(fo)
+		if err != nil {
+			return 0, fmt.Errorf("order %s: %w", row.orderUID, err)
+		}
+		if _, err := tx.Exec(ctx, updateReencryptedArchiveQuery, row.orderUID, payload, dk.Wrapped, dk.KeyID); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
+
+func (r *OrderPostgresRepository) decryptArchiveBatch(ctx context.Context, batchSize int) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	batch, err := queryArchiveRows(ctx, tx, selectArchiveToDecryptQuery, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range batch {
+		var fo models.FullOrder
+		if err := json.Unmarshal(row.payload, &fo); err != nil {
+			return 0, fmt.Errorf("order %s: %w", row.orderUID, err)
+		}
+		if err := r.decryptDelivery(&fo.Delivery, row.wrapped, row.keyID); err != nil {
+			return 0, fmt.Errorf("order %s: %w", row.orderUID, err)
+		}
+		payload, err := json.Marshal(fo)
+		if err != nil {
+			return 0, fmt.Errorf("order %s: %w", row.orderUID, err)
+		}
+		if _, err := tx.Exec(ctx, updateDecryptedArchiveQuery, row.orderUID, payload); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
@@ -0,0 +1,128 @@
+package orderRepoPostgres_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+
+	"wbL0/internal/encryption"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	orderRepoPostgres "wbL0/internal/repository/postgres/orderRepoPostgres"
+)
+
+func testKeyring(t *testing.T) *encryption.Keyring {
+	t.Helper()
+	key := func(b byte) string { return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32))) }
+	keys, err := encryption.ParseKeyring([]byte(`{"active":"k1","keys":{"k1":"` + key('a') + `"},"index_key":"` + key('i') + `"}`))
+	require.NoError(t, err)
+	return keys
+}
+
+func TestSaveDeliveryDataTxEncrypted(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	keys := testKeyring(t)
+	repo := orderRepoPostgres.NewPostgresRepository(nil, slog.Default()).WithEncryption(keys)
+	delivery := &models.Delivery{
+		OrderUID: "u1", Name: "Test Testov", Phone: "+9720000000", Zip: 2639809,
+		City: "Kiryat Mozkin", Address: "Ploshad Mira 15", Region: "Kraiot", Email: "test@gmail.com",
+	}
+
+	var args []any
+	tx := mocks.NewPgxTx(t)
+	tx.On("Exec", anyArgs(14)...).
+		Run(func(a mock.Arguments) { args = a[2:] }).
+		Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+
+	require.NoError(t, repo.SaveDeliveryDataTx(ctx, tx, delivery))
+	require.Len(t, args, 12)
+
+	for _, i := range []int{1, 2, 5, 7} {
+		assert.True(t, encryption.IsEncrypted(args[i].(string)), "argument %d is not encrypted", i)
+	}
+	assert.Equal(t, delivery.City, args[4])
+	assert.Equal(t, "k1", args[9])
+	assert.Equal(t, keys.BlindIndex(encryption.IndexEmail, delivery.Email), args[10])
+	assert.Equal(t, keys.BlindIndex(encryption.IndexPhone, delivery.Phone), args[11])
+
+	stored := models.Delivery{
+		OrderUID: "u1", Name: args[1].(string), Phone: args[2].(string), Zip: delivery.Zip,
+		City: delivery.City, Address: args[5].(string), Region: delivery.Region, Email: args[7].(string),
+	}
+	dek, keyID := args[8].(string), args[9].(string)
+	require.NoError(t, orderRepoPostgres.DecryptDelivery(repo, &stored, &dek, &keyID))
+	assert.Equal(t, *delivery, stored)
+
+	t.Run("ciphertext of another order does not decrypt", func(t *testing.T) {
+		moved := models.Delivery{OrderUID: "u2", Name: args[1].(string)}
+		assert.Error(t, orderRepoPostgres.DecryptDelivery(repo, &moved, &dek, &keyID))
+	})
+}
+
+func TestDeliveryWithoutEncryption(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := orderRepoPostgres.NewPostgresRepository(nil, slog.Default())
+
+	t.Run("plaintext is written without keys", func(t *testing.T) {
+		tx := mocks.NewPgxTx(t)
+		tx.On("Exec", ctx, mock.Anything, "u1", "Test Testov", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, nil, nil, nil, nil).
+			Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+
+		assert.NoError(t, repo.SaveDeliveryDataTx(ctx, tx, &models.Delivery{OrderUID: "u1", Name: "Test Testov"}))
+	})
+
+	t.Run("legacy plaintext rows are read as they are", func(t *testing.T) {
+		d := models.Delivery{OrderUID: "u1", Name: "Test Testov"}
+		assert.NoError(t, orderRepoPostgres.DecryptDelivery(repo, &d, nil, nil))
+		assert.Equal(t, "Test Testov", d.Name)
+	})
+
+	t.Run("email filter needs encryption", func(t *testing.T) {
+		_, err := repo.ListOrders(ctx, models.OrderFilter{Email: "test@gmail.com", Limit: 1})
+		assert.ErrorIs(t, err, models.ErrInvalidInput)
+	})
+}
+
+func TestSaveOrderVersionsTxEncrypted(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := orderRepoPostgres.NewPostgresRepository(nil, slog.Default()).WithEncryption(testKeyring(t))
+	snapshot := json.RawMessage(`{"delivery":{"email":"test@gmail.com"}}`)
+
+	var batch *pgx.Batch
+	tx := mocks.NewPgxTx(t)
+	tx.On("SendBatch", ctx, mock.Anything).
+		Run(func(a mock.Arguments) { batch = a[1].(*pgx.Batch) }).
+		Return(&fakeBatchResults{}).Once()
+
+	err := repo.SaveOrderVersionsTx(ctx, tx, []models.OrderVersion{{OrderUID: "u1", Operation: models.VersionInserted, Current: snapshot}})
+	require.NoError(t, err)
+
+	args := batch.QueuedQueries[0].Arguments
+	assert.Nil(t, args[2])
+	stored := args[3].(string)
+	assert.NotContains(t, stored, "test@gmail.com")
+
+	opened, err := orderRepoPostgres.OpenSnapshot(repo, json.RawMessage(stored))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(snapshot), string(opened))
+
+	plain, err := orderRepoPostgres.OpenSnapshot(repo, snapshot)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(snapshot), string(plain))
+}
@@ -0,0 +1,16 @@
+package orderRepoPostgres
+
+var (
+	BuildListOrdersQuery  = buildListOrdersQuery
+	BuildOrdersBatchQuery = buildOrdersBatchQuery
+)
+
+var (
+	GetFullOrderByUIDPerTable = (*OrderPostgresRepository).getFullOrderByUIDPerTable
+	GetAllFullOrdersPerTable  = (*OrderPostgresRepository).getAllFullOrdersPerTable
+)
+
+var (
+	DecryptDelivery = (*OrderPostgresRepository).decryptDelivery
+	OpenSnapshot    = (*OrderPostgresRepository).openSnapshot
+)
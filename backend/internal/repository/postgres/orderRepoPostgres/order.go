@@ -2,15 +2,20 @@ package orderRepoPostgres
 
 import (
 	"context"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"log/slog"
+	"time"
+	"wbL0/internal/encryption"
 	"wbL0/internal/models"
 )
 
 //go:generate mockery --name=PgxTx --dir=. --output=../../../mocks --outpkg=mocks --case=underscore
 type PgxTx interface {
 	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
 	Commit(ctx context.Context) error
 	Rollback(ctx context.Context) error
 }
@@ -18,23 +23,51 @@ type PgxTx interface {
 //go:generate mockery --name=OrderPostgresRepositoryInterface --dir=. --output=../../../mocks --outpkg=mocks --case=underscore
 type OrderPostgresRepositoryInterface interface {
 	BeginTx(ctx context.Context) (PgxTx, error)
-	SaveOrderDataTx(ctx context.Context, tx PgxTx, order *models.Order) error
+	SaveOrderDataTx(ctx context.Context, tx PgxTx, order *models.Order, checksum string) (models.SaveOutcome, error)
 	SaveDeliveryDataTx(ctx context.Context, tx PgxTx, delivery *models.Delivery) error
 	SavePaymentDataTx(ctx context.Context, tx PgxTx, payment *models.Payment) error
+	DeleteItemsTx(ctx context.Context, tx PgxTx, orderUID string) error
 	SaveItemsDataTx(ctx context.Context, tx PgxTx, item *models.Item) error
+	SaveOrdersBatchTx(ctx context.Context, tx PgxTx, orders []*models.FullOrder, checksums []string) ([]models.SaveOutcome, error)
+	SaveOutboxEventsTx(ctx context.Context, tx PgxTx, events []models.OutboxEvent) error
+	DeleteOrderTx(ctx context.Context, tx PgxTx, orderUID string) error
 	GetOrderInfoByUid(ctx context.Context, orderUID string) (*models.Order, error)
 	GetAllFullOrders(ctx context.Context) ([]*models.FullOrder, error)
+	GetFullOrdersBatch(ctx context.Context, after *models.OrderCursor, since *time.Time, limit int) ([]*models.FullOrder, *models.OrderCursor, error)
 	GetFullOrderByUID(ctx context.Context, orderUID string) (*models.FullOrder, error)
+	GetFullOrdersByUIDs(ctx context.Context, uids []string) ([]*models.FullOrder, error)
+	ListOrders(ctx context.Context, filter models.OrderFilter) ([]models.OrderSummary, error)
+	GetOrderStatusForUpdateTx(ctx context.Context, tx PgxTx, orderUID string) (models.OrderStatus, error)
+	SaveStatusChangeTx(ctx context.Context, tx PgxTx, change *models.StatusChange) error
+	GetOrderStatusHistory(ctx context.Context, orderUID string) (*models.OrderStatusHistory, error)
+	SaveOrderVersionsTx(ctx context.Context, tx PgxTx, versions []models.OrderVersion) error
+	ListOrderVersions(ctx context.Context, orderUID string) ([]models.OrderVersion, error)
+	GetOrderVersion(ctx context.Context, orderUID string, version int) (*models.OrderVersion, error)
+	GetOrderUIDsByCustomer(ctx context.Context, customerID string) ([]string, error)
+	EraseCustomerTx(ctx context.Context, tx PgxTx, customerID string, erasure *models.CustomerErasure) error
+	GetErasedOrderUIDs(ctx context.Context, uids []string) ([]string, error)
+	GetRecentlyErasedOrderUIDs(ctx context.Context, window time.Duration) ([]string, error)
+	GetPendingEvictions(ctx context.Context, limit int) ([]string, error)
+	SavePendingEvictions(ctx context.Context, uids []string) error
+	DeletePendingEvictions(ctx context.Context, uids []string) error
 }
 type OrderPostgresRepository struct {
 	pool *pgxpool.Pool
 	log  *slog.Logger
+	keys *encryption.Keyring
 }
 
 func NewPostgresRepository(pool *pgxpool.Pool, log *slog.Logger) *OrderPostgresRepository {
 	return &OrderPostgresRepository{pool: pool, log: log}
 }
 
+// WithEncryption encrypts delivery PII and order version snapshots with keys from the keyring.
+// Rows written before encryption was enabled are still read as plaintext.
+func (r *OrderPostgresRepository) WithEncryption(keys *encryption.Keyring) *OrderPostgresRepository {
+	r.keys = keys
+	return r
+}
+
 func (r *OrderPostgresRepository) BeginTx(ctx context.Context) (PgxTx, error) {
 	const op = "OrderPostgresRepository.BeginTx"
 	tx, err := r.pool.Begin(ctx)
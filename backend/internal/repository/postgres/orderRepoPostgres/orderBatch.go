@@ -0,0 +1,190 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"strings"
+	"time"
+	"wbL0/internal/models"
+)
+
+// GetFullOrdersBatch returns up to limit full orders created after since (when set), newest first, starting after the cursor.
+// The returned cursor points at the last scanned order and is nil when there are no more orders.
+// Children of the whole batch are loaded with one query per table.
+func (r *OrderPostgresRepository) GetFullOrdersBatch(ctx context.Context, after *models.OrderCursor, since *time.Time, limit int) ([]*models.FullOrder, *models.OrderCursor, error) {
+	const op = "OrderPostgresRepository.GetFullOrdersBatch"
+
+	query, args := buildOrdersBatchQuery(after, since, limit)
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.log.Error("failed to query orders batch", "op", op, "err", err)
+		return nil, nil, err
+	}
+	orders, err := scanOrders(rows)
+	if err != nil {
+		r.log.Error("failed to scan orders batch", "op", op, "err", err)
+		return nil, nil, err
+	}
+	if len(orders) == 0 {
+		return nil, nil, nil
+	}
+
+	fullOrders, err := r.attachChildren(ctx, orders)
+	if err != nil {
+		r.log.Error("failed to load order children", "op", op, "err", err)
+		return nil, nil, err
+	}
+
+	var next *models.OrderCursor
+	if len(orders) == limit {
+		last := orders[len(orders)-1]
+		next = &models.OrderCursor{DateCreated: last.DateCreated, OrderUID: last.OrderUID}
+	}
+	r.log.Debug("orders batch retrieved", "op", op, "count", len(fullOrders))
+	return fullOrders, next, nil
+}
+
+// buildOrdersBatchQuery builds the query of GetFullOrdersBatch.
+// date_created is a TIMESTAMP holding UTC, so since and the cursor are converted first.
+func buildOrdersBatchQuery(after *models.OrderCursor, since *time.Time, limit int) (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	if since != nil {
+		args = append(args, since.UTC())
+		conds = append(conds, fmt.Sprintf("date_created >= $%d", len(args)))
+	}
+	if after != nil {
+		args = append(args, after.DateCreated.UTC(), after.OrderUID)
+		conds = append(conds, fmt.Sprintf("(date_created, order_uid) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	query := `SELECT order_uid, track_number, entry, locale, internal_signature, customer_id,
+                     delivery_service, shardkey, sm_id, date_created, oof_shard
+              FROM orders`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY date_created DESC, order_uid DESC LIMIT $%d", len(args))
+	return query, args
+}
+
+func scanOrders(rows pgx.Rows) ([]models.Order, error) {
+	defer rows.Close()
+
+	var orders []models.Order
+	for rows.Next() {
+		var order models.Order
+		if err := rows.Scan(
+			&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale,
+			&order.InternalSignature, &order.CustomerID, &order.DeliveryService,
+			&order.Shardkey, &order.SmID, &order.DateCreated, &order.OofShard,
+		); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+// attachChildren loads delivery, payment and items for all orders with one query per table.
+// Orders without delivery or payment are skipped, as a broken order cannot be served.
+func (r *OrderPostgresRepository) attachChildren(ctx context.Context, orders []models.Order) ([]*models.FullOrder, error) {
+	uids := make([]string, len(orders))
+	for i := range orders {
+		uids[i] = orders[i].OrderUID
+	}
+
+	deliveries := make(map[string]models.Delivery, len(orders))
+	rows, err := r.pool.Query(ctx, `SELECT order_uid, name, phone, zip, city, address, region, email, dek, key_id
+              FROM delivery WHERE order_uid = ANY($1)`, uids)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			d          models.Delivery
+			dek, keyID *string
+		)
+		if err := rows.Scan(&d.OrderUID, &d.Name, &d.Phone, &d.Zip, &d.City, &d.Address, &d.Region, &d.Email, &dek, &keyID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if err := r.decryptDelivery(&d, dek, keyID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("order %s: %w", d.OrderUID, err)
+		}
+		deliveries[d.OrderUID] = d
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	payments := make(map[string]models.Payment, len(orders))
+	rows, err = r.pool.Query(ctx, `SELECT order_uid, transaction, request_id, currency, provider, amount,
+                    payment_dt, bank, delivery_cost, goods_total, custom_fee
+              FROM payment WHERE order_uid = ANY($1)`, uids)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var p models.Payment
+		if err := rows.Scan(
+			&p.OrderUID, &p.Transaction, &p.RequestID, &p.Currency,
+			&p.Provider, &p.Amount, &p.PaymentDt, &p.Bank,
+			&p.DeliveryCost, &p.GoodsTotal, &p.CustomFee,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		payments[p.OrderUID] = p
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	items := make(map[string][]models.Item, len(orders))
+	rows, err = r.pool.Query(ctx, `SELECT order_uid, chrt_id, track_number, price, rid, name, sale, size,
+                    total_price, nm_id, brand, status
+              FROM items WHERE order_uid = ANY($1) ORDER BY id`, uids)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var it models.Item
+		if err := rows.Scan(
+			&it.OrderUID, &it.ChrtID, &it.TrackNumber, &it.Price,
+			&it.Rid, &it.Name, &it.Sale, &it.Size, &it.TotalPrice,
+			&it.NmID, &it.Brand, &it.Status,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		items[it.OrderUID] = append(items[it.OrderUID], it)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	fullOrders := make([]*models.FullOrder, 0, len(orders))
+	for _, order := range orders {
+		delivery, okDelivery := deliveries[order.OrderUID]
+		payment, okPayment := payments[order.OrderUID]
+		if !okDelivery || !okPayment {
+			r.log.Warn("order has no delivery or payment, skipping", "op", "OrderPostgresRepository.attachChildren", "orderUID", order.OrderUID)
+			continue
+		}
+		fullOrders = append(fullOrders, &models.FullOrder{
+			Order:    order,
+			Delivery: delivery,
+			Payment:  payment,
+			Items:    items[order.OrderUID],
+		})
+	}
+	return fullOrders, nil
+}
@@ -0,0 +1,25 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"wbL0/internal/models"
+)
+
+// DeleteOrderTx deletes the order row. Delivery, payment and items are removed by ON DELETE CASCADE,
+// the status history is kept for audit like the order versions. The tombstone of an erased order is kept too,
+// so a replay of the order is still not ingested.
+func (r *OrderPostgresRepository) DeleteOrderTx(ctx context.Context, tx PgxTx, orderUID string) error {
+	const op = "OrderPostgresRepository.DeleteOrderTx"
+
+	query := `DELETE FROM orders WHERE order_uid = $1`
+	tag, err := tx.Exec(ctx, query, orderUID)
+	if err != nil {
+		r.log.Error("failed to delete order", "op", op, "orderUID", orderUID, "err", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrOrderNotFound
+	}
+	r.log.Info("order deleted", "op", op, "orderUID", orderUID)
+	return nil
+}
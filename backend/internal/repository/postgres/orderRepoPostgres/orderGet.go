@@ -2,7 +2,12 @@ package orderRepoPostgres
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
 	"wbL0/internal/models"
+	"wbL0/internal/tracing"
 )
 
 func (r *OrderPostgresRepository) GetOrderInfoByUid(ctx context.Context, orderUID string) (*models.Order, error) {
@@ -25,6 +30,9 @@ func (r *OrderPostgresRepository) GetOrderInfoByUid(ctx context.Context, orderUI
 		&order.DateCreated,
 		&order.OofShard,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrOrderNotFound
+	}
 	if err != nil {
 		r.log.Error("failed to get order info", "op", op, "orderUID", orderUID, "err", err)
 		return nil, err
@@ -33,156 +41,126 @@ func (r *OrderPostgresRepository) GetOrderInfoByUid(ctx context.Context, orderUI
 	return order, nil
 }
 
+const allOrdersBatchSize = 1000
+
+// GetAllFullOrders reads every order in batches of allOrdersBatchSize, so the number of queries
+// grows with the number of batches rather than the number of orders.
 func (r *OrderPostgresRepository) GetAllFullOrders(ctx context.Context) ([]*models.FullOrder, error) {
 	const op = "OrderPostgresRepository.GetAllFullOrders"
 	var fullOrders []*models.FullOrder
 
-	queryOrders := `SELECT order_uid, track_number, entry, locale, internal_signature, customer_id, 
-                           delivery_service, shardkey, sm_id, date_created, oof_shard 
-                    FROM orders`
-	rows, err := r.pool.Query(ctx, queryOrders)
-	if err != nil {
-		r.log.Error("failed to query orders", "op", op, "err", err)
-		return nil, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var order models.Order
-		if err := rows.Scan(
-			&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale,
-			&order.InternalSignature, &order.CustomerID, &order.DeliveryService,
-			&order.Shardkey, &order.SmID, &order.DateCreated, &order.OofShard,
-		); err != nil {
-			r.log.Error("failed to scan order", "op", op, "err", err)
-			continue
-		}
-
-		delivery, err := r.getDeliveryByOrderUID(ctx, order.OrderUID)
-		if err != nil {
-			r.log.Warn("failed to get delivery", "op", op, "orderUID", order.OrderUID, "err", err)
-			continue
-		}
-
-		payment, err := r.getPaymentByOrderUID(ctx, order.OrderUID)
+	var cursor *models.OrderCursor
+	for {
+		batch, next, err := r.GetFullOrdersBatch(ctx, cursor, nil, allOrdersBatchSize)
 		if err != nil {
-			r.log.Warn("failed to get payment", "op", op, "orderUID", order.OrderUID, "err", err)
-			continue
+			r.log.Error("failed to get orders batch", "op", op, "err", err)
+			return nil, err
 		}
-
-		items, err := r.getItemsByOrderUID(ctx, order.OrderUID)
-		if err != nil {
-			r.log.Warn("failed to get items", "op", op, "orderUID", order.OrderUID, "err", err)
-			continue
+		fullOrders = append(fullOrders, batch...)
+		if next == nil {
+			break
 		}
-
-		fullOrders = append(fullOrders, &models.FullOrder{
-			Order:    order,
-			Delivery: *delivery,
-			Payment:  *payment,
-			Items:    items,
-		})
+		cursor = next
 	}
 
 	r.log.Info("retrieved all full orders", "op", op, "count", len(fullOrders))
 	return fullOrders, nil
 }
 
-func (r *OrderPostgresRepository) GetFullOrderByUID(ctx context.Context, orderUID string) (*models.FullOrder, error) {
-	const op = "OrderPostgresRepository.GetFullOrderByUID"
+// GetFullOrdersByUIDs returns the full orders for the given UIDs using one query per table.
+// Unknown UIDs are skipped and the result follows date_created order, not the order of uids.
+func (r *OrderPostgresRepository) GetFullOrdersByUIDs(ctx context.Context, uids []string) ([]*models.FullOrder, error) {
+	const op = "OrderPostgresRepository.GetFullOrdersByUIDs"
 
-	order, err := r.GetOrderInfoByUid(ctx, orderUID)
-	if err != nil {
-		r.log.Error("failed to get order info", "op", op, "orderUID", orderUID, "err", err)
-		return nil, err
+	if len(uids) == 0 {
+		return nil, nil
 	}
 
-	delivery, err := r.getDeliveryByOrderUID(ctx, orderUID)
+	query := `SELECT order_uid, track_number, entry, locale, internal_signature, customer_id,
+                     delivery_service, shardkey, sm_id, date_created, oof_shard
+              FROM orders WHERE order_uid = ANY($1)
+              ORDER BY date_created DESC, order_uid DESC`
+	rows, err := r.pool.Query(ctx, query, uids)
 	if err != nil {
-		r.log.Error("failed to get delivery", "op", op, "orderUID", orderUID, "err", err)
+		r.log.Error("failed to query orders", "op", op, "err", err)
 		return nil, err
 	}
-
-	payment, err := r.getPaymentByOrderUID(ctx, orderUID)
+	orders, err := scanOrders(rows)
 	if err != nil {
-		r.log.Error("failed to get payment", "op", op, "orderUID", orderUID, "err", err)
+		r.log.Error("failed to scan orders", "op", op, "err", err)
 		return nil, err
 	}
+	if len(orders) == 0 {
+		return nil, nil
+	}
 
-	items, err := r.getItemsByOrderUID(ctx, orderUID)
+	fullOrders, err := r.attachChildren(ctx, orders)
 	if err != nil {
-		r.log.Error("failed to get items", "op", op, "orderUID", orderUID, "err", err)
+		r.log.Error("failed to load order children", "op", op, "err", err)
 		return nil, err
 	}
 
-	r.log.Info("full order retrieved", "op", op, "orderUID", orderUID)
-	return &models.FullOrder{
-		Order:    *order,
-		Delivery: *delivery,
-		Payment:  *payment,
-		Items:    items,
-	}, nil
+	r.log.Debug("full orders retrieved", "op", op, "requested", len(uids), "count", len(fullOrders))
+	return fullOrders, nil
 }
 
-func (r *OrderPostgresRepository) getDeliveryByOrderUID(ctx context.Context, orderUID string) (*models.Delivery, error) {
-	const op = "OrderPostgresRepository.getDeliveryByOrderUID"
-	var delivery models.Delivery
-	query := `SELECT order_uid, name, phone, zip, city, address, region, email 
-              FROM delivery WHERE order_uid = $1`
-	err := r.pool.QueryRow(ctx, query, orderUID).Scan(
-		&delivery.OrderUID, &delivery.Name, &delivery.Phone, &delivery.Zip,
-		&delivery.City, &delivery.Address, &delivery.Region, &delivery.Email,
+// GetFullOrderByUID reads the order, its delivery and payment with a join and aggregates items into JSON,
+// so the whole order takes a single round trip.
+func (r *OrderPostgresRepository) GetFullOrderByUID(ctx context.Context, orderUID string) (_ *models.FullOrder, err error) {
+	const op = "OrderPostgresRepository.GetFullOrderByUID"
+	ctx, span := tracing.Start(ctx, op, trace.WithAttributes(tracing.OrderUID(orderUID)))
+	defer func() { tracing.End(span, err, models.ErrOrderNotFound) }()
+
+	query := `SELECT o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature, o.customer_id,
+                     o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard,
+                     d.order_uid, d.name, d.phone, d.zip, d.city, d.address, d.region, d.email, d.dek, d.key_id,
+                     p.order_uid, p.transaction, p.request_id, p.currency, p.provider, p.amount,
+                     p.payment_dt, p.bank, p.delivery_cost, p.goods_total, p.custom_fee,
+                     (SELECT json_agg(json_build_object(
+                                 'order_uid', i.order_uid, 'chrt_id', i.chrt_id, 'track_number', i.track_number,
+                                 'price', i.price, 'rid', i.rid, 'name', i.name, 'sale', i.sale, 'size', i.size,
+                                 'total_price', i.total_price, 'nm_id', i.nm_id, 'brand', i.brand, 'status', i.status
+                             ) ORDER BY i.id)
+                      FROM items i WHERE i.order_uid = o.order_uid)
+              FROM orders o
+              JOIN delivery d ON d.order_uid = o.order_uid
+              JOIN payment p ON p.order_uid = o.order_uid
+              WHERE o.order_uid = $1`
+
+	var (
+		fo         models.FullOrder
+		rawItems   []byte
+		dek, keyID *string
 	)
-	if err != nil {
-		r.log.Error("failed to get delivery", "op", op, "orderUID", orderUID, "err", err)
-		return nil, err
-	}
-	return &delivery, nil
-}
-
-func (r *OrderPostgresRepository) getPaymentByOrderUID(ctx context.Context, orderUID string) (*models.Payment, error) {
-	const op = "OrderPostgresRepository.getPaymentByOrderUID"
-	var payment models.Payment
-	query := `SELECT order_uid, transaction, request_id, currency, provider, amount, 
-                    payment_dt, bank, delivery_cost, goods_total, custom_fee 
-              FROM payment WHERE order_uid = $1`
-	err := r.pool.QueryRow(ctx, query, orderUID).Scan(
-		&payment.OrderUID, &payment.Transaction, &payment.RequestID, &payment.Currency,
-		&payment.Provider, &payment.Amount, &payment.PaymentDt, &payment.Bank,
-		&payment.DeliveryCost, &payment.GoodsTotal, &payment.CustomFee,
+	err = r.pool.QueryRow(ctx, query, orderUID).Scan(
+		&fo.Order.OrderUID, &fo.Order.TrackNumber, &fo.Order.Entry, &fo.Order.Locale,
+		&fo.Order.InternalSignature, &fo.Order.CustomerID, &fo.Order.DeliveryService,
+		&fo.Order.Shardkey, &fo.Order.SmID, &fo.Order.DateCreated, &fo.Order.OofShard,
+		&fo.Delivery.OrderUID, &fo.Delivery.Name, &fo.Delivery.Phone, &fo.Delivery.Zip,
+		&fo.Delivery.City, &fo.Delivery.Address, &fo.Delivery.Region, &fo.Delivery.Email, &dek, &keyID,
+		&fo.Payment.OrderUID, &fo.Payment.Transaction, &fo.Payment.RequestID, &fo.Payment.Currency,
+		&fo.Payment.Provider, &fo.Payment.Amount, &fo.Payment.PaymentDt, &fo.Payment.Bank,
+		&fo.Payment.DeliveryCost, &fo.Payment.GoodsTotal, &fo.Payment.CustomFee,
+		&rawItems,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrOrderNotFound
+	}
 	if err != nil {
-		r.log.Error("failed to get payment", "op", op, "orderUID", orderUID, "err", err)
+		r.log.ErrorContext(ctx, "failed to get full order", "op", op, "orderUID", orderUID, "err", err)
 		return nil, err
 	}
-	return &payment, nil
-}
-
-func (r *OrderPostgresRepository) getItemsByOrderUID(ctx context.Context, orderUID string) ([]models.Item, error) {
-	const op = "OrderPostgresRepository.getItemsByOrderUID"
-	var items []models.Item
-	query := `SELECT order_uid, chrt_id, track_number, price, rid, name, sale, size, 
-                    total_price, nm_id, brand, status 
-              FROM items WHERE order_uid = $1`
-	rows, err := r.pool.Query(ctx, query, orderUID)
-	if err != nil {
-		r.log.Error("failed to query items", "op", op, "orderUID", orderUID, "err", err)
+	if err := r.decryptDelivery(&fo.Delivery, dek, keyID); err != nil {
+		r.log.ErrorContext(ctx, "failed to decrypt delivery", "op", op, "orderUID", orderUID, "err", err)
 		return nil, err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var item models.Item
-		if err := rows.Scan(
-			&item.OrderUID, &item.ChrtID, &item.TrackNumber, &item.Price,
-			&item.Rid, &item.Name, &item.Sale, &item.Size, &item.TotalPrice,
-			&item.NmID, &item.Brand, &item.Status,
-		); err != nil {
-			r.log.Warn("failed to scan item", "op", op, "orderUID", orderUID, "err", err)
-			continue
+	if rawItems != nil {
+		if err := json.Unmarshal(rawItems, &fo.Items); err != nil {
+			r.log.ErrorContext(ctx, "failed to unmarshal items", "op", op, "orderUID", orderUID, "err", err)
+			return nil, err
 		}
-		items = append(items, item)
 	}
-	r.log.Info("items retrieved", "op", op, "orderUID", orderUID, "count", len(items))
-	return items, nil
+
+	r.log.InfoContext(ctx, "full order retrieved", "op", op, "orderUID", orderUID)
+	return &fo, nil
 }
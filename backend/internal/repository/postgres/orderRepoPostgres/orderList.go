@@ -0,0 +1,126 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"wbL0/internal/encryption"
+	"wbL0/internal/models"
+)
+
+// ListOrders returns up to filter.Limit order summaries matching the filter, ordered by (date_created, order_uid).
+func (r *OrderPostgresRepository) ListOrders(ctx context.Context, filter models.OrderFilter) ([]models.OrderSummary, error) {
+	const op = "OrderPostgresRepository.ListOrders"
+
+	if (filter.Email != "" || filter.Phone != "") && r.keys == nil {
+		return nil, fmt.Errorf("%w: email and phone filters require encryption", models.ErrInvalidInput)
+	}
+	query, args := buildListOrdersQuery(filter, r.keys)
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.log.Error("failed to query orders", "op", op, "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]models.OrderSummary, 0, filter.Limit)
+	for rows.Next() {
+		var o models.OrderSummary
+		if err := rows.Scan(
+			&o.OrderUID, &o.TrackNumber, &o.CustomerID, &o.DeliveryService, &o.DateCreated,
+			&o.Currency, &o.Provider, &o.Bank, &o.Amount,
+		); err != nil {
+			r.log.Error("failed to scan order summary", "op", op, "err", err)
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error("failed to iterate orders", "op", op, "err", err)
+		return nil, err
+	}
+
+	r.log.Info("orders listed", "op", op, "count", len(orders))
+	return orders, nil
+}
+
+// buildListOrdersQuery renders the ListOrders query. keys computes the blind indexes of the email and phone
+// filters and may be nil when they are empty.
+func buildListOrdersQuery(filter models.OrderFilter, keys *encryption.Keyring) (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.CustomerID != "" {
+		conds = append(conds, "o.customer_id = "+arg(filter.CustomerID))
+	}
+	if filter.TrackNumber != "" {
+		conds = append(conds, "o.track_number = "+arg(filter.TrackNumber))
+	}
+	if filter.DeliveryService != "" {
+		conds = append(conds, "o.delivery_service = "+arg(filter.DeliveryService))
+	}
+	if filter.Email != "" || filter.Phone != "" {
+		deliveryConds := []string{"d.order_uid = o.order_uid"}
+		if filter.Email != "" {
+			deliveryConds = append(deliveryConds, "d.email_bidx = "+arg(keys.BlindIndex(encryption.IndexEmail, filter.Email)))
+		}
+		if filter.Phone != "" {
+			deliveryConds = append(deliveryConds, "d.phone_bidx = "+arg(keys.BlindIndex(encryption.IndexPhone, filter.Phone)))
+		}
+		conds = append(conds, "EXISTS (SELECT 1 FROM delivery d WHERE "+strings.Join(deliveryConds, " AND ")+")")
+	}
+	// date_created is a TIMESTAMP holding UTC, so bounds given with another offset are converted first.
+	if filter.DateFrom != nil {
+		conds = append(conds, "o.date_created >= "+arg(filter.DateFrom.UTC()))
+	}
+	if filter.DateTo != nil {
+		conds = append(conds, "o.date_created < "+arg(filter.DateTo.UTC()))
+	}
+	if filter.Currency != "" {
+		conds = append(conds, "p.currency = "+arg(filter.Currency))
+	}
+	if filter.Provider != "" {
+		conds = append(conds, "p.provider = "+arg(filter.Provider))
+	}
+	if filter.Bank != "" {
+		conds = append(conds, "p.bank = "+arg(filter.Bank))
+	}
+	if filter.Brand != "" || filter.NmID != 0 {
+		itemConds := []string{"i.order_uid = o.order_uid"}
+		if filter.Brand != "" {
+			itemConds = append(itemConds, "i.brand = "+arg(filter.Brand))
+		}
+		if filter.NmID != 0 {
+			itemConds = append(itemConds, "i.nm_id = "+arg(filter.NmID))
+		}
+		conds = append(conds, "EXISTS (SELECT 1 FROM items i WHERE "+strings.Join(itemConds, " AND ")+")")
+	}
+
+	direction, cmp := "DESC", "<"
+	if filter.Sort == models.SortAsc {
+		direction, cmp = "ASC", ">"
+	}
+	if filter.Cursor != nil {
+		conds = append(conds, fmt.Sprintf("(o.date_created, o.order_uid) %s (%s, %s)",
+			cmp, arg(filter.Cursor.DateCreated.UTC()), arg(filter.Cursor.OrderUID)))
+	}
+
+	var b strings.Builder
+	b.WriteString(`SELECT o.order_uid, o.track_number, o.customer_id, o.delivery_service, o.date_created,
+		p.currency, p.provider, p.bank, p.amount
+		FROM orders o
+		JOIN payment p ON p.order_uid = o.order_uid`)
+	if len(conds) > 0 {
+		b.WriteString("\n\t\tWHERE ")
+		b.WriteString(strings.Join(conds, " AND "))
+	}
+	fmt.Fprintf(&b, "\n\t\tORDER BY o.date_created %s, o.order_uid %s\n\t\tLIMIT %s", direction, direction, arg(filter.Limit))
+
+	return b.String(), args
+}
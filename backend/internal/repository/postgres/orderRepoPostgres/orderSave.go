@@ -2,64 +2,172 @@ package orderRepoPostgres
 
 import (
 	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"wbL0/internal/models"
+	"wbL0/internal/tracing"
 )
 
-func (r *OrderPostgresRepository) SaveOrderDataTx(ctx context.Context, tx PgxTx, order *models.Order) error {
+const (
+	upsertOrderQuery = `INSERT INTO orders (order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, payload_hash)
+		SELECT $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12
+		WHERE NOT EXISTS (SELECT 1 FROM erased_orders e WHERE e.order_uid = $1)
+		ON CONFLICT (order_uid) DO UPDATE SET
+			track_number = EXCLUDED.track_number,
+			entry = EXCLUDED.entry,
+			locale = EXCLUDED.locale,
+			internal_signature = EXCLUDED.internal_signature,
+			customer_id = EXCLUDED.customer_id,
+			delivery_service = EXCLUDED.delivery_service,
+			shardkey = EXCLUDED.shardkey,
+			sm_id = EXCLUDED.sm_id,
+			date_created = EXCLUDED.date_created,
+			oof_shard = EXCLUDED.oof_shard,
+			payload_hash = EXCLUDED.payload_hash
+		WHERE orders.payload_hash IS DISTINCT FROM EXCLUDED.payload_hash AND orders.erased_at IS NULL
+		RETURNING (xmax = 0) AS inserted`
+	upsertDeliveryQuery = `INSERT INTO delivery (order_uid, name, phone, zip, city, address, region, email, dek, key_id, email_bidx, phone_bidx)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+		ON CONFLICT (order_uid) DO UPDATE SET
+			name = EXCLUDED.name,
+			phone = EXCLUDED.phone,
+			zip = EXCLUDED.zip,
+			city = EXCLUDED.city,
+			address = EXCLUDED.address,
+			region = EXCLUDED.region,
+			email = EXCLUDED.email,
+			dek = EXCLUDED.dek,
+			key_id = EXCLUDED.key_id,
+			email_bidx = EXCLUDED.email_bidx,
+			phone_bidx = EXCLUDED.phone_bidx`
+	upsertPaymentQuery = `INSERT INTO payment (order_uid, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		ON CONFLICT (order_uid) DO UPDATE SET
+			transaction = EXCLUDED.transaction,
+			request_id = EXCLUDED.request_id,
+			currency = EXCLUDED.currency,
+			provider = EXCLUDED.provider,
+			amount = EXCLUDED.amount,
+			payment_dt = EXCLUDED.payment_dt,
+			bank = EXCLUDED.bank,
+			delivery_cost = EXCLUDED.delivery_cost,
+			goods_total = EXCLUDED.goods_total,
+			custom_fee = EXCLUDED.custom_fee`
+	deleteItemsQuery = `DELETE FROM items WHERE order_uid = $1`
+	insertItemQuery  = `INSERT INTO items (order_uid, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`
+)
+
+// SaveOrderDataTx upserts the order row. The row is rewritten only when the payload checksum differs
+// from the stored one, so a redelivered identical order is reported as unchanged. Orders whose customer data
+// was erased are never written again, even once archived or deleted, and are reported as unchanged too.
+func (r *OrderPostgresRepository) SaveOrderDataTx(ctx context.Context, tx PgxTx, order *models.Order, checksum string) (_ models.SaveOutcome, err error) {
 	const op = "OrderPostgresRepository.SaveOrderDataTx"
+	ctx, span := tracing.Start(ctx, op, trace.WithAttributes(tracing.OrderUID(order.OrderUID)))
+	defer func() { tracing.End(span, err) }()
 
-	query := `INSERT INTO orders (order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`
-	_, err := tx.Exec(ctx, query,
-		order.OrderUID,
-		order.TrackNumber,
-		order.Entry,
-		order.Locale,
-		order.InternalSignature,
-		order.CustomerID,
-		order.DeliveryService,
-		order.Shardkey,
-		order.SmID,
-		order.DateCreated,
-		order.OofShard,
-	)
+	var inserted bool
+	err = tx.QueryRow(ctx, upsertOrderQuery, orderArgs(order, checksum)...).Scan(&inserted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.log.InfoContext(ctx, "order data unchanged", "op", op, "orderUID", order.OrderUID)
+		span.SetAttributes(attribute.String("order.outcome", string(models.OutcomeUnchanged)))
+		return models.OutcomeUnchanged, nil
+	}
 	if err != nil {
-		r.log.Error("failed to save order data", "op", op, "orderUID", order.OrderUID, "err", err)
-		return err
+		r.log.ErrorContext(ctx, "failed to save order data", "op", op, "orderUID", order.OrderUID, "err", err)
+		return "", err
 	}
-	r.log.Info("order data saved", "op", op, "orderUID", order.OrderUID)
-	return nil
+
+	outcome := models.OutcomeUpdated
+	if inserted {
+		outcome = models.OutcomeInserted
+	}
+	r.log.InfoContext(ctx, "order data saved", "op", op, "orderUID", order.OrderUID, "outcome", outcome)
+	span.SetAttributes(attribute.String("order.outcome", string(outcome)))
+	return outcome, nil
 }
 
-func (r *OrderPostgresRepository) SaveDeliveryDataTx(ctx context.Context, tx PgxTx, delivery *models.Delivery) error {
+func (r *OrderPostgresRepository) SaveDeliveryDataTx(ctx context.Context, tx PgxTx, delivery *models.Delivery) (err error) {
 	const op = "OrderPostgresRepository.SaveDeliveryDataTx"
+	ctx, span := tracing.Start(ctx, op, trace.WithAttributes(tracing.OrderUID(delivery.OrderUID)))
+	defer func() { tracing.End(span, err) }()
 
-	query := `INSERT INTO delivery (order_uid, name, phone, zip, city, address, region, email)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
-	_, err := tx.Exec(ctx, query,
-		delivery.OrderUID,
-		delivery.Name,
-		delivery.Phone,
-		delivery.Zip,
-		delivery.City,
-		delivery.Address,
-		delivery.Region,
-		delivery.Email,
-	)
+	args, err := r.deliveryArgs(delivery)
+	if err != nil {
+		r.log.ErrorContext(ctx, "failed to encrypt delivery data", "op", op, "orderUID", delivery.OrderUID, "err", err)
+		return err
+	}
+	_, err = tx.Exec(ctx, upsertDeliveryQuery, args...)
 	if err != nil {
-		r.log.Error("failed to save delivery data", "op", op, "orderUID", delivery.OrderUID, "err", err)
+		r.log.ErrorContext(ctx, "failed to save delivery data", "op", op, "orderUID", delivery.OrderUID, "err", err)
 		return err
 	}
-	r.log.Info("delivery data saved", "op", op, "orderUID", delivery.OrderUID)
+	r.log.InfoContext(ctx, "delivery data saved", "op", op, "orderUID", delivery.OrderUID)
 	return nil
 }
 
-func (r *OrderPostgresRepository) SavePaymentDataTx(ctx context.Context, tx PgxTx, payment *models.Payment) error {
+func (r *OrderPostgresRepository) SavePaymentDataTx(ctx context.Context, tx PgxTx, payment *models.Payment) (err error) {
 	const op = "OrderPostgresRepository.SavePaymentDataTx"
+	ctx, span := tracing.Start(ctx, op, trace.WithAttributes(tracing.OrderUID(payment.OrderUID)))
+	defer func() { tracing.End(span, err) }()
+
+	_, err = tx.Exec(ctx, upsertPaymentQuery, paymentArgs(payment)...)
+	if err != nil {
+		r.log.ErrorContext(ctx, "failed to save payment data", "op", op, "orderUID", payment.OrderUID, "err", err)
+		return err
+	}
+	r.log.InfoContext(ctx, "payment data saved", "op", op, "orderUID", payment.OrderUID)
+	return nil
+}
+
+// DeleteItemsTx removes all items of the order, so a changed payload can replace them.
+func (r *OrderPostgresRepository) DeleteItemsTx(ctx context.Context, tx PgxTx, orderUID string) error {
+	const op = "OrderPostgresRepository.DeleteItemsTx"
+
+	tag, err := tx.Exec(ctx, deleteItemsQuery, orderUID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "failed to delete items", "op", op, "orderUID", orderUID, "err", err)
+		return err
+	}
+	r.log.InfoContext(ctx, "items deleted", "op", op, "orderUID", orderUID, "count", tag.RowsAffected())
+	return nil
+}
 
-	query := `INSERT INTO payment (order_uid, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`
-	_, err := tx.Exec(ctx, query,
+func (r *OrderPostgresRepository) SaveItemsDataTx(ctx context.Context, tx PgxTx, item *models.Item) (err error) {
+	const op = "OrderPostgresRepository.SaveItemsDataTx"
+	ctx, span := tracing.Start(ctx, op, trace.WithAttributes(tracing.OrderUID(item.OrderUID)))
+	defer func() { tracing.End(span, err) }()
+
+	_, err = tx.Exec(ctx, insertItemQuery, itemArgs(item)...)
+	if err != nil {
+		r.log.ErrorContext(ctx, "failed to save item data", "op", op, "orderUID", item.OrderUID, "err", err)
+		return err
+	}
+	r.log.InfoContext(ctx, "item data saved", "op", op, "orderUID", item.OrderUID)
+	return nil
+}
+
+func orderArgs(order *models.Order, checksum string) []any {
+	return []any{
+		order.OrderUID,
+		order.TrackNumber,
+		order.Entry,
+		order.Locale,
+		order.InternalSignature,
+		order.CustomerID,
+		order.DeliveryService,
+		order.Shardkey,
+		order.SmID,
+		order.DateCreated,
+		order.OofShard,
+		checksum,
+	}
+}
+
+func paymentArgs(payment *models.Payment) []any {
+	return []any{
 		payment.OrderUID,
 		payment.Transaction,
 		payment.RequestID,
@@ -71,21 +179,11 @@ func (r *OrderPostgresRepository) SavePaymentDataTx(ctx context.Context, tx PgxT
 		payment.DeliveryCost,
 		payment.GoodsTotal,
 		payment.CustomFee,
-	)
-	if err != nil {
-		r.log.Error("failed to save payment data", "op", op, "orderUID", payment.OrderUID, "err", err)
-		return err
 	}
-	r.log.Info("payment data saved", "op", op, "orderUID", payment.OrderUID)
-	return nil
 }
 
-func (r *OrderPostgresRepository) SaveItemsDataTx(ctx context.Context, tx PgxTx, item *models.Item) error {
-	const op = "OrderPostgresRepository.SaveItemsDataTx"
-
-	query := `INSERT INTO items (order_uid, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`
-	_, err := tx.Exec(ctx, query,
+func itemArgs(item *models.Item) []any {
+	return []any{
 		item.OrderUID,
 		item.ChrtID,
 		item.TrackNumber,
@@ -98,11 +196,5 @@ func (r *OrderPostgresRepository) SaveItemsDataTx(ctx context.Context, tx PgxTx,
 		item.NmID,
 		item.Brand,
 		item.Status,
-	)
-	if err != nil {
-		r.log.Error("failed to save item data", "op", op, "orderUID", item.OrderUID, "err", err)
-		return err
 	}
-	r.log.Info("item data saved", "op", op, "orderUID", item.OrderUID)
-	return nil
 }
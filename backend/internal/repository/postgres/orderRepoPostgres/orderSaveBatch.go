@@ -0,0 +1,72 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"wbL0/internal/models"
+)
+
+// SaveOrdersBatchTx upserts many orders in two round trips: the first batch upserts the order rows and
+// reports the outcome of each, the second writes delivery, payment and items of the orders that changed.
+// checksums[i] is the payload checksum of orders[i].
+func (r *OrderPostgresRepository) SaveOrdersBatchTx(ctx context.Context, tx PgxTx, orders []*models.FullOrder, checksums []string) ([]models.SaveOutcome, error) {
+	const op = "OrderPostgresRepository.SaveOrdersBatchTx"
+
+	ordersBatch := &pgx.Batch{}
+	for i, fo := range orders {
+		ordersBatch.Queue(upsertOrderQuery, orderArgs(&fo.Order, checksums[i])...)
+	}
+
+	results := tx.SendBatch(ctx, ordersBatch)
+	outcomes := make([]models.SaveOutcome, len(orders))
+	for i, fo := range orders {
+		var inserted bool
+		err := results.QueryRow().Scan(&inserted)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			outcomes[i] = models.OutcomeUnchanged
+		case err != nil:
+			_ = results.Close()
+			r.log.Error("failed to save order data", "op", op, "orderUID", fo.Order.OrderUID, "err", err)
+			return nil, err
+		case inserted:
+			outcomes[i] = models.OutcomeInserted
+		default:
+			outcomes[i] = models.OutcomeUpdated
+		}
+	}
+	if err := results.Close(); err != nil {
+		r.log.Error("failed to save orders batch", "op", op, "err", err)
+		return nil, err
+	}
+
+	childrenBatch := &pgx.Batch{}
+	for i, fo := range orders {
+		if outcomes[i] == models.OutcomeUnchanged {
+			continue
+		}
+		if outcomes[i] == models.OutcomeUpdated {
+			childrenBatch.Queue(deleteItemsQuery, fo.Order.OrderUID)
+		}
+		deliveryArgs, err := r.deliveryArgs(&fo.Delivery)
+		if err != nil {
+			r.log.Error("failed to encrypt delivery data", "op", op, "orderUID", fo.Order.OrderUID, "err", err)
+			return nil, err
+		}
+		childrenBatch.Queue(upsertDeliveryQuery, deliveryArgs...)
+		childrenBatch.Queue(upsertPaymentQuery, paymentArgs(&fo.Payment)...)
+		for j := range fo.Items {
+			childrenBatch.Queue(insertItemQuery, itemArgs(&fo.Items[j])...)
+		}
+	}
+	if childrenBatch.Len() > 0 {
+		if err := tx.SendBatch(ctx, childrenBatch).Close(); err != nil {
+			r.log.Error("failed to save order children batch", "op", op, "err", err)
+			return nil, err
+		}
+	}
+
+	r.log.Info("orders batch saved", "op", op, "count", len(orders), "statements", ordersBatch.Len()+childrenBatch.Len())
+	return outcomes, nil
+}
@@ -0,0 +1,85 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"wbL0/internal/models"
+)
+
+// GetOrderStatusForUpdateTx returns the current status and locks the order row until the transaction ends,
+// so concurrent status changes of one order are applied one after another.
+func (r *OrderPostgresRepository) GetOrderStatusForUpdateTx(ctx context.Context, tx PgxTx, orderUID string) (models.OrderStatus, error) {
+	const op = "OrderPostgresRepository.GetOrderStatusForUpdateTx"
+
+	var status models.OrderStatus
+	err := tx.QueryRow(ctx, `SELECT status FROM orders WHERE order_uid = $1 FOR UPDATE`, orderUID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", models.ErrOrderNotFound
+	}
+	if err != nil {
+		r.log.Error("failed to get order status", "op", op, "orderUID", orderUID, "err", err)
+		return "", err
+	}
+	return status, nil
+}
+
+// SaveStatusChangeTx sets the new status and appends the change to the history.
+func (r *OrderPostgresRepository) SaveStatusChangeTx(ctx context.Context, tx PgxTx, change *models.StatusChange) error {
+	const op = "OrderPostgresRepository.SaveStatusChangeTx"
+
+	if _, err := tx.Exec(ctx, `UPDATE orders SET status = $2 WHERE order_uid = $1`, change.OrderUID, change.To); err != nil {
+		r.log.Error("failed to update order status", "op", op, "orderUID", change.OrderUID, "err", err)
+		return err
+	}
+
+	query := `INSERT INTO order_status_history (order_uid, from_status, to_status, reason, source, changed_at)
+		VALUES ($1,$2,$3,$4,$5,$6)`
+	if _, err := tx.Exec(ctx, query, change.OrderUID, change.From, change.To, change.Reason, change.Source, change.ChangedAt); err != nil {
+		r.log.Error("failed to save status history", "op", op, "orderUID", change.OrderUID, "err", err)
+		return err
+	}
+	r.log.Info("order status changed", "op", op, "orderUID", change.OrderUID, "from", change.From, "to", change.To)
+	return nil
+}
+
+// GetOrderStatusHistory returns the current status of a hot or archived order and its status changes, oldest first.
+// The history is kept when the order is archived.
+func (r *OrderPostgresRepository) GetOrderStatusHistory(ctx context.Context, orderUID string) (*models.OrderStatusHistory, error) {
+	const op = "OrderPostgresRepository.GetOrderStatusHistory"
+
+	history := &models.OrderStatusHistory{OrderUID: orderUID, History: []models.StatusChange{}}
+	err := r.pool.QueryRow(ctx, `SELECT status FROM orders WHERE order_uid = $1
+              UNION ALL
+              SELECT status FROM orders_archive WHERE order_uid = $1
+              LIMIT 1`, orderUID).Scan(&history.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrOrderNotFound
+	}
+	if err != nil {
+		r.log.Error("failed to get order status", "op", op, "orderUID", orderUID, "err", err)
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT from_status, to_status, COALESCE(reason, ''), source, changed_at
+              FROM order_status_history WHERE order_uid = $1 ORDER BY id`, orderUID)
+	if err != nil {
+		r.log.Error("failed to query status history", "op", op, "orderUID", orderUID, "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		change := models.StatusChange{OrderUID: orderUID}
+		if err := rows.Scan(&change.From, &change.To, &change.Reason, &change.Source, &change.ChangedAt); err != nil {
+			r.log.Error("failed to scan status history", "op", op, "orderUID", orderUID, "err", err)
+			return nil, err
+		}
+		history.History = append(history.History, change)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error("failed to iterate status history", "op", op, "orderUID", orderUID, "err", err)
+		return nil, err
+	}
+	return history, nil
+}
@@ -0,0 +1,140 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"wbL0/internal/encryption"
+	"wbL0/internal/models"
+)
+
+// saveOrderVersionQuery numbers the version after the latest one of the order. When the caller has no previous
+// snapshot it is taken from the latest version; orders stored before the audit trail existed have none.
+// Operations that keep the order content repeat the latest snapshot as the current one. The status is read
+// from the order written earlier in the transaction and is NULL once the order is deleted.
+// The order row is locked by the write in the same transaction, so versions of one order cannot race.
+const saveOrderVersionQuery = `WITH latest AS (
+			SELECT current FROM order_versions WHERE order_uid = $1 ORDER BY version DESC LIMIT 1
+		)
+		INSERT INTO order_versions (order_uid, version, operation, previous, current, source, status)
+		SELECT $1, COALESCE(MAX(v.version), 0) + 1, $2,
+			COALESCE($3::jsonb, (SELECT current FROM latest)),
+			CASE WHEN $6 THEN (SELECT current FROM latest) ELSE $4::jsonb END,
+			$5, (SELECT status FROM orders WHERE order_uid = $1)
+		FROM order_versions v WHERE v.order_uid = $1`
+
+// SaveOrderVersionsTx appends the versions to the audit trail in one round trip.
+func (r *OrderPostgresRepository) SaveOrderVersionsTx(ctx context.Context, tx PgxTx, versions []models.OrderVersion) error {
+	const op = "OrderPostgresRepository.SaveOrderVersionsTx"
+
+	if len(versions) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, v := range versions {
+		previous, err := r.sealSnapshot(v.Previous)
+		if err != nil {
+			r.log.Error("failed to encrypt order version", "op", op, "orderUID", v.OrderUID, "err", err)
+			return err
+		}
+		current, err := r.sealSnapshot(v.Current)
+		if err != nil {
+			r.log.Error("failed to encrypt order version", "op", op, "orderUID", v.OrderUID, "err", err)
+			return err
+		}
+		keepsSnapshot := v.Operation == models.VersionStatusChanged || v.Operation == models.VersionArchived
+		batch.Queue(saveOrderVersionQuery, v.OrderUID, v.Operation, nullableJSON(previous), nullableJSON(current), v.Source, keepsSnapshot)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		r.log.Error("failed to save order versions", "op", op, "count", len(versions), "err", err)
+		return err
+	}
+	return nil
+}
+
+// ListOrderVersions returns the versions of the order without snapshots, oldest first.
+func (r *OrderPostgresRepository) ListOrderVersions(ctx context.Context, orderUID string) ([]models.OrderVersion, error) {
+	const op = "OrderPostgresRepository.ListOrderVersions"
+
+	rows, err := r.pool.Query(ctx, `SELECT order_uid, version, operation, source, COALESCE(status, ''), created_at
+              FROM order_versions WHERE order_uid = $1 ORDER BY version`, orderUID)
+	if err != nil {
+		r.log.Error("failed to query order versions", "op", op, "orderUID", orderUID, "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []models.OrderVersion{}
+	for rows.Next() {
+		var v models.OrderVersion
+		if err := rows.Scan(&v.OrderUID, &v.Version, &v.Operation, &v.Source, &v.Status, &v.CreatedAt); err != nil {
+			r.log.Error("failed to scan order version", "op", op, "orderUID", orderUID, "err", err)
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error("failed to iterate order versions", "op", op, "orderUID", orderUID, "err", err)
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *OrderPostgresRepository) GetOrderVersion(ctx context.Context, orderUID string, version int) (*models.OrderVersion, error) {
+	const op = "OrderPostgresRepository.GetOrderVersion"
+
+	v := &models.OrderVersion{}
+	err := r.pool.QueryRow(ctx, `SELECT order_uid, version, operation, source, COALESCE(status, ''), created_at, previous, current
+              FROM order_versions WHERE order_uid = $1 AND version = $2`, orderUID, version).
+		Scan(&v.OrderUID, &v.Version, &v.Operation, &v.Source, &v.Status, &v.CreatedAt, &v.Previous, &v.Current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrVersionNotFound
+	}
+	if err != nil {
+		r.log.Error("failed to get order version", "op", op, "orderUID", orderUID, "version", version, "err", err)
+		return nil, err
+	}
+	if v.Previous, err = r.openSnapshot(v.Previous); err != nil {
+		r.log.Error("failed to decrypt order version", "op", op, "orderUID", orderUID, "version", version, "err", err)
+		return nil, err
+	}
+	if v.Current, err = r.openSnapshot(v.Current); err != nil {
+		r.log.Error("failed to decrypt order version", "op", op, "orderUID", orderUID, "version", version, "err", err)
+		return nil, err
+	}
+	return v, nil
+}
+
+// sealSnapshot encrypts a snapshot with encryption enabled. The sealed payload is stored as a JSON string,
+// which keeps the column valid JSONB and lets the previous snapshot be copied from the latest version as is.
+func (r *OrderPostgresRepository) sealSnapshot(snapshot json.RawMessage) (json.RawMessage, error) {
+	if r.keys == nil || len(snapshot) == 0 {
+		return snapshot, nil
+	}
+	sealed, err := r.keys.Seal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(sealed))
+}
+
+// openSnapshot decrypts a sealed snapshot and returns plaintext snapshots unchanged.
+func (r *OrderPostgresRepository) openSnapshot(snapshot json.RawMessage) (json.RawMessage, error) {
+	var sealed string
+	if len(snapshot) == 0 || snapshot[0] != '"' || json.Unmarshal(snapshot, &sealed) != nil || !encryption.IsSealed([]byte(sealed)) {
+		return snapshot, nil
+	}
+	if r.keys == nil {
+		return nil, errors.New("order version is encrypted but encryption is not configured")
+	}
+	return r.keys.Open([]byte(sealed))
+}
+
+func nullableJSON(b []byte) any {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
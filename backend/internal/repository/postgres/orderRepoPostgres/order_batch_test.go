@@ -0,0 +1,34 @@
+package orderRepoPostgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"wbL0/internal/models"
+	orderRepoPostgres "wbL0/internal/repository/postgres/orderRepoPostgres"
+)
+
+func TestBuildOrdersBatchQuery(t *testing.T) {
+	t.Run("no bounds", func(t *testing.T) {
+		query, args := orderRepoPostgres.BuildOrdersBatchQuery(nil, nil, 100)
+		assert.NotContains(t, query, "WHERE")
+		assert.Contains(t, query, "ORDER BY date_created DESC, order_uid DESC LIMIT $1")
+		assert.Equal(t, []any{100}, args)
+	})
+
+	t.Run("since and cursor are bound in UTC", func(t *testing.T) {
+		moscow := time.FixedZone("+03:00", 3*60*60)
+		since := time.Date(2025, 1, 1, 3, 0, 0, 0, moscow)
+		cursor := &models.OrderCursor{DateCreated: time.Date(2025, 1, 2, 15, 0, 0, 0, moscow), OrderUID: "uid"}
+
+		query, args := orderRepoPostgres.BuildOrdersBatchQuery(cursor, &since, 100)
+		assert.Contains(t, query, "WHERE date_created >= $1 AND (date_created, order_uid) < ($2, $3)")
+		assert.Equal(t, []any{
+			time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC),
+			"uid", 100,
+		}, args)
+	})
+}
@@ -0,0 +1,121 @@
+package orderRepoPostgres_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"wbL0/internal/lib/ordergen"
+	orderRepoPostgres "wbL0/internal/repository/postgres/orderRepoPostgres"
+	"wbL0/tests"
+)
+
+const benchOrders = 500
+
+// benchRepo starts a throwaway Postgres in Docker, like the integration tests in tests/, and seeds benchOrders orders.
+func benchRepo(b *testing.B) (*orderRepoPostgres.OrderPostgresRepository, []string) {
+	b.Helper()
+
+	ctx := context.Background()
+	pool := tests.StartPostgres(b)
+	repo := orderRepoPostgres.NewPostgresRepository(pool, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	gen := ordergen.New(42)
+	uids := make([]string, 0, benchOrders)
+	for i := 0; i < benchOrders; i++ {
+		fo := gen.Valid()
+		checksum, err := fo.Checksum()
+		if err != nil {
+			b.Fatalf("checksum: %v", err)
+		}
+		tx, err := repo.BeginTx(ctx)
+		if err != nil {
+			b.Fatalf("begin: %v", err)
+		}
+		if _, err := repo.SaveOrderDataTx(ctx, tx, &fo.Order, checksum); err != nil {
+			b.Fatalf("save order: %v", err)
+		}
+		if err := repo.SaveDeliveryDataTx(ctx, tx, &fo.Delivery); err != nil {
+			b.Fatalf("save delivery: %v", err)
+		}
+		if err := repo.SavePaymentDataTx(ctx, tx, &fo.Payment); err != nil {
+			b.Fatalf("save payment: %v", err)
+		}
+		if err := repo.DeleteItemsTx(ctx, tx, fo.Order.OrderUID); err != nil {
+			b.Fatalf("delete items: %v", err)
+		}
+		for j := range fo.Items {
+			if err := repo.SaveItemsDataTx(ctx, tx, &fo.Items[j]); err != nil {
+				b.Fatalf("save item: %v", err)
+			}
+		}
+		if err := tx.Commit(ctx); err != nil {
+			b.Fatalf("commit: %v", err)
+		}
+		uids = append(uids, fo.Order.OrderUID)
+	}
+	return repo, uids
+}
+
+func BenchmarkGetFullOrderByUID(b *testing.B) {
+	repo, uids := benchRepo(b)
+	ctx := context.Background()
+
+	b.Run("single_query", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.GetFullOrderByUID(ctx, uids[i%len(uids)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("per_table", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := orderRepoPostgres.GetFullOrderByUIDPerTable(repo, ctx, uids[i%len(uids)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkGetFullOrdersByUIDs(b *testing.B) {
+	repo, uids := benchRepo(b)
+	ctx := context.Background()
+	batch := uids[:100]
+
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.GetFullOrdersByUIDs(ctx, batch); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("per_table", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, uid := range batch {
+				if _, err := orderRepoPostgres.GetFullOrderByUIDPerTable(repo, ctx, uid); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkGetAllFullOrders(b *testing.B) {
+	repo, _ := benchRepo(b)
+	ctx := context.Background()
+
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.GetAllFullOrders(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("per_table", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := orderRepoPostgres.GetAllFullOrdersPerTable(repo, ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
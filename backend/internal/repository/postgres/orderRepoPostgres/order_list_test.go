@@ -0,0 +1,88 @@
+package orderRepoPostgres_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"wbL0/internal/encryption"
+	"wbL0/internal/models"
+	orderRepoPostgres "wbL0/internal/repository/postgres/orderRepoPostgres"
+)
+
+func TestBuildListOrdersQuery(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("no filters", func(t *testing.T) {
+		query, args := orderRepoPostgres.BuildListOrdersQuery(models.OrderFilter{Sort: models.SortDesc, Limit: 21}, nil)
+		assert.NotContains(t, query, "WHERE")
+		assert.Contains(t, query, "ORDER BY o.date_created DESC, o.order_uid DESC")
+		assert.Equal(t, []any{21}, args)
+	})
+
+	t.Run("all filters with cursor", func(t *testing.T) {
+		cursor := &models.OrderCursor{DateCreated: from, OrderUID: "uid"}
+		query, args := orderRepoPostgres.BuildListOrdersQuery(models.OrderFilter{
+			CustomerID: "c1",
+			DateFrom:   &from,
+			Currency:   "USD",
+			Brand:      "Vivienne Sabo",
+			NmID:       42,
+			Sort:       models.SortAsc,
+			Cursor:     cursor,
+			Limit:      11,
+		}, nil)
+
+		assert.Contains(t, query, "o.customer_id = $1")
+		assert.Contains(t, query, "o.date_created >= $2")
+		assert.Contains(t, query, "p.currency = $3")
+		assert.Contains(t, query, "EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = $4 AND i.nm_id = $5)")
+		assert.Contains(t, query, "(o.date_created, o.order_uid) > ($6, $7)")
+		assert.True(t, strings.HasSuffix(query, "LIMIT $8"))
+		assert.Contains(t, query, "ORDER BY o.date_created ASC, o.order_uid ASC")
+		assert.Equal(t, []any{"c1", from, "USD", "Vivienne Sabo", 42, from, "uid", 11}, args)
+	})
+
+	t.Run("date bounds are bound in UTC", func(t *testing.T) {
+		moscow := time.FixedZone("+03:00", 3*60*60)
+		from, err := time.Parse(time.RFC3339, "2025-01-01T03:00:00+03:00")
+		assert.NoError(t, err)
+		to := time.Date(2025, 1, 2, 3, 0, 0, 0, moscow)
+		cursor := &models.OrderCursor{DateCreated: time.Date(2025, 1, 1, 15, 0, 0, 0, moscow), OrderUID: "uid"}
+
+		_, args := orderRepoPostgres.BuildListOrdersQuery(models.OrderFilter{
+			DateFrom: &from,
+			DateTo:   &to,
+			Sort:     models.SortAsc,
+			Cursor:   cursor,
+			Limit:    11,
+		}, nil)
+
+		assert.Equal(t, []any{
+			time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			"uid", 11,
+		}, args)
+	})
+
+	t.Run("email and phone match blind indexes", func(t *testing.T) {
+		keys := testKeyring(t)
+		query, args := orderRepoPostgres.BuildListOrdersQuery(models.OrderFilter{
+			Email: " Test@Gmail.com",
+			Phone: "+7 (972) 000-00-00",
+			Sort:  models.SortDesc,
+			Limit: 5,
+		}, keys)
+
+		assert.Contains(t, query, "EXISTS (SELECT 1 FROM delivery d WHERE d.order_uid = o.order_uid AND d.email_bidx = $1 AND d.phone_bidx = $2)")
+		assert.Equal(t, []any{
+			keys.BlindIndex(encryption.IndexEmail, "test@gmail.com"),
+			keys.BlindIndex(encryption.IndexPhone, "+79720000000"),
+			5,
+		}, args)
+		assert.NotContains(t, args, "test@gmail.com")
+	})
+}
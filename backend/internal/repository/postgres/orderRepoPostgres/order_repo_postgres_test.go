@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -31,8 +32,6 @@ func TestSaveDataTx(t *testing.T) {
 	logger := slog.Default()
 	repo := orderRepoPostgres.NewPostgresRepository(nil, logger)
 
-	now := time.Now()
-
 	tests := []struct {
 		name       string
 		execArgs   int
@@ -42,42 +41,6 @@ func TestSaveDataTx(t *testing.T) {
 		mockErr    error
 		commandTag pgconn.CommandTag
 	}{
-		{
-			name:     "SaveOrderDataTx success",
-			execArgs: 13,
-			input: &models.Order{
-				OrderUID:    "u1",
-				TrackNumber: "t1",
-				Entry:       "e",
-				Locale:      "ru",
-				CustomerID:  "c1",
-				DateCreated: now,
-			},
-			saveFunc: func(ctx context.Context, tx orderRepoPostgres.PgxTx, v interface{}) error {
-				return repo.SaveOrderDataTx(ctx, tx, v.(*models.Order))
-			},
-			wantErr:    false,
-			mockErr:    nil,
-			commandTag: pgconn.NewCommandTag("INSERT 0 1"),
-		},
-		{
-			name:     "SaveOrderDataTx failure",
-			execArgs: 13,
-			input: &models.Order{
-				OrderUID:    "u1",
-				TrackNumber: "t1",
-				Entry:       "e",
-				Locale:      "ru",
-				CustomerID:  "c1",
-				DateCreated: now,
-			},
-			saveFunc: func(ctx context.Context, tx orderRepoPostgres.PgxTx, v interface{}) error {
-				return repo.SaveOrderDataTx(ctx, tx, v.(*models.Order))
-			},
-			wantErr:    true,
-			mockErr:    errors.New("db error"),
-			commandTag: pgconn.NewCommandTag(""),
-		},
 		{
 			name:     "SaveItemsDataTx success",
 			execArgs: 14,
@@ -144,3 +107,76 @@ func TestSaveDataTx(t *testing.T) {
 		})
 	}
 }
+
+type fakeRow struct {
+	inserted bool
+	err      error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*bool) = r.inserted
+	return nil
+}
+
+func TestSaveOrderDataTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := slog.Default()
+	repo := orderRepoPostgres.NewPostgresRepository(nil, logger)
+
+	order := &models.Order{
+		OrderUID:    "u1",
+		TrackNumber: "t1",
+		Entry:       "e",
+		Locale:      "ru",
+		CustomerID:  "c1",
+		DateCreated: time.Now(),
+	}
+
+	tests := []struct {
+		name        string
+		row         fakeRow
+		wantOutcome models.SaveOutcome
+		wantErr     bool
+	}{
+		{
+			name:        "new order inserted",
+			row:         fakeRow{inserted: true},
+			wantOutcome: models.OutcomeInserted,
+		},
+		{
+			name:        "existing order updated",
+			row:         fakeRow{inserted: false},
+			wantOutcome: models.OutcomeUpdated,
+		},
+		{
+			name:        "same checksum is unchanged",
+			row:         fakeRow{err: pgx.ErrNoRows},
+			wantOutcome: models.OutcomeUnchanged,
+		},
+		{
+			name:    "db error",
+			row:     fakeRow{err: errors.New("db error")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := mocks.NewPgxTx(t)
+			tx.On("QueryRow", anyArgs(14)...).Return(tt.row)
+
+			outcome, err := repo.SaveOrderDataTx(ctx, tx, order, "checksum")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOutcome, outcome)
+		})
+	}
+}
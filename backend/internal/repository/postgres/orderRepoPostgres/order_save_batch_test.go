@@ -0,0 +1,94 @@
+package orderRepoPostgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	orderRepoPostgres "wbL0/internal/repository/postgres/orderRepoPostgres"
+)
+
+type fakeBatchResults struct {
+	rows     []fakeRow
+	closeErr error
+}
+
+func (b *fakeBatchResults) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag{}, nil }
+
+func (b *fakeBatchResults) Query() (pgx.Rows, error) { return nil, errors.New("not implemented") }
+
+func (b *fakeBatchResults) QueryRow() pgx.Row {
+	row := b.rows[0]
+	b.rows = b.rows[1:]
+	return row
+}
+
+func (b *fakeBatchResults) Close() error { return b.closeErr }
+
+func batchOrder(uid string, items int) *models.FullOrder {
+	fo := &models.FullOrder{Order: models.Order{OrderUID: uid}}
+	for i := 0; i < items; i++ {
+		fo.Items = append(fo.Items, models.Item{OrderUID: uid})
+	}
+	return fo
+}
+
+func TestSaveOrdersBatchTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := orderRepoPostgres.NewPostgresRepository(nil, slog.Default())
+	orders := []*models.FullOrder{batchOrder("new", 2), batchOrder("changed", 1), batchOrder("same", 3)}
+	checksums := []string{"a", "b", "c"}
+
+	t.Run("children are written only for changed orders", func(t *testing.T) {
+		tx := mocks.NewPgxTx(t)
+		tx.On("SendBatch", ctx, mock.MatchedBy(func(b *pgx.Batch) bool { return b.Len() == 3 })).
+			Return(&fakeBatchResults{rows: []fakeRow{{inserted: true}, {inserted: false}, {err: pgx.ErrNoRows}}}).Once()
+		// new: delivery, payment, 2 items; changed: delete items, delivery, payment, 1 item.
+		tx.On("SendBatch", ctx, mock.MatchedBy(func(b *pgx.Batch) bool { return b.Len() == 8 })).
+			Return(&fakeBatchResults{}).Once()
+
+		outcomes, err := repo.SaveOrdersBatchTx(ctx, tx, orders, checksums)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SaveOutcome{models.OutcomeInserted, models.OutcomeUpdated, models.OutcomeUnchanged}, outcomes)
+	})
+
+	t.Run("all unchanged skips the second round trip", func(t *testing.T) {
+		tx := mocks.NewPgxTx(t)
+		tx.On("SendBatch", ctx, mock.Anything).
+			Return(&fakeBatchResults{rows: []fakeRow{{err: pgx.ErrNoRows}, {err: pgx.ErrNoRows}, {err: pgx.ErrNoRows}}}).Once()
+
+		outcomes, err := repo.SaveOrdersBatchTx(ctx, tx, orders, checksums)
+		assert.NoError(t, err)
+		assert.Len(t, outcomes, 3)
+	})
+
+	t.Run("order upsert error fails the batch", func(t *testing.T) {
+		tx := mocks.NewPgxTx(t)
+		tx.On("SendBatch", ctx, mock.Anything).
+			Return(&fakeBatchResults{rows: []fakeRow{{inserted: true}, {err: errors.New("db error")}}}).Once()
+
+		_, err := repo.SaveOrdersBatchTx(ctx, tx, orders, checksums)
+		assert.Error(t, err)
+	})
+
+	t.Run("children error fails the batch", func(t *testing.T) {
+		tx := mocks.NewPgxTx(t)
+		tx.On("SendBatch", ctx, mock.MatchedBy(func(b *pgx.Batch) bool { return b.Len() == 3 })).
+			Return(&fakeBatchResults{rows: []fakeRow{{inserted: true}, {inserted: true}, {inserted: true}}}).Once()
+		tx.On("SendBatch", ctx, mock.Anything).
+			Return(&fakeBatchResults{closeErr: errors.New("duplicate key")}).Once()
+
+		_, err := repo.SaveOrdersBatchTx(ctx, tx, orders, checksums)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,128 @@
+package orderRepoPostgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"time"
+	"wbL0/internal/models"
+)
+
+// outboxLockKey is the advisory lock that lets only one relay publish at a time, which keeps events
+// of an order in order when several instances are running.
+const outboxLockKey = 7_000_001
+
+//go:generate mockery --name=OutboxRepositoryInterface --dir=. --output=../../../mocks --outpkg=mocks --case=underscore
+type OutboxRepositoryInterface interface {
+	RelayOutbox(ctx context.Context, limit int, publish func([]models.OutboxEvent) error) (int, error)
+	OutboxBacklog(ctx context.Context) (int, time.Duration, error)
+}
+
+// SaveOutboxEventsTx stores the events in the caller's transaction, so they exist only if the order does.
+func (r *OrderPostgresRepository) SaveOutboxEventsTx(ctx context.Context, tx PgxTx, events []models.OutboxEvent) error {
+	const op = "OrderPostgresRepository.SaveOutboxEventsTx"
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO order_outbox (order_uid, event_type, payload) VALUES ($1, $2, $3)`
+	batch := &pgx.Batch{}
+	for _, e := range events {
+		batch.Queue(query, e.OrderUID, e.EventType, e.Payload)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		r.log.Error("failed to save outbox events", "op", op, "count", len(events), "err", err)
+		return err
+	}
+	return nil
+}
+
+// RelayOutbox passes up to limit unsent events, oldest first, to publish and marks them sent when it succeeds.
+// A failed publish only bumps the attempt counter, so the same events are retried first next time.
+// It returns 0 without calling publish when another relay holds the lock.
+func (r *OrderPostgresRepository) RelayOutbox(ctx context.Context, limit int, publish func([]models.OutboxEvent) error) (int, error) {
+	const op = "OrderPostgresRepository.RelayOutbox"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.log.Error("failed to begin transaction", "op", op, "err", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxLockKey).Scan(&locked); err != nil {
+		r.log.Error("failed to take outbox lock", "op", op, "err", err)
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id, order_uid, event_type, payload, created_at, attempts
+              FROM order_outbox WHERE sent_at IS NULL ORDER BY id LIMIT $1`, limit)
+	if err != nil {
+		r.log.Error("failed to query outbox", "op", op, "err", err)
+		return 0, err
+	}
+	var (
+		events []models.OutboxEvent
+		ids    []int64
+	)
+	for rows.Next() {
+		var e models.OutboxEvent
+		if err := rows.Scan(&e.ID, &e.OrderUID, &e.EventType, &e.Payload, &e.CreatedAt, &e.Attempts); err != nil {
+			rows.Close()
+			r.log.Error("failed to scan outbox event", "op", op, "err", err)
+			return 0, err
+		}
+		events = append(events, e)
+		ids = append(ids, e.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.log.Error("failed to iterate outbox", "op", op, "err", err)
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if pubErr := publish(events); pubErr != nil {
+		if _, err := tx.Exec(ctx, `UPDATE order_outbox SET attempts = attempts + 1, last_error = $2 WHERE id = ANY($1)`, ids, pubErr.Error()); err != nil {
+			r.log.Error("failed to record outbox failure", "op", op, "err", err)
+			return 0, pubErr
+		}
+		if err := tx.Commit(ctx); err != nil {
+			r.log.Error("failed to commit transaction", "op", op, "err", err)
+		}
+		return 0, pubErr
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE order_outbox SET sent_at = now(), attempts = attempts + 1, last_error = NULL WHERE id = ANY($1)`, ids); err != nil {
+		r.log.Error("failed to mark outbox events sent", "op", op, "err", err)
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		r.log.Error("failed to commit transaction", "op", op, "err", err)
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// OutboxBacklog returns the number of unsent events and the age of the oldest one.
+func (r *OrderPostgresRepository) OutboxBacklog(ctx context.Context) (int, time.Duration, error) {
+	const op = "OrderPostgresRepository.OutboxBacklog"
+
+	var (
+		pending int
+		lagSec  float64
+	)
+	err := r.pool.QueryRow(ctx, `SELECT count(*), COALESCE(EXTRACT(EPOCH FROM now() - min(created_at)), 0)::float8
+              FROM order_outbox WHERE sent_at IS NULL`).Scan(&pending, &lagSec)
+	if err != nil {
+		r.log.Error("failed to get outbox backlog", "op", op, "err", err)
+		return 0, 0, err
+	}
+	return pending, time.Duration(lagSec * float64(time.Second)), nil
+}
@@ -0,0 +1,42 @@
+package orderRepoPostgres_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+
+	"wbL0/internal/lib/breaker"
+	"wbL0/internal/models"
+	"wbL0/internal/repository/postgres/orderRepoPostgres"
+)
+
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, want: true},
+		{name: "wrapped connection refused", err: fmt.Errorf("begin: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}), want: true},
+		{name: "server shutting down", err: &pgconn.PgError{Code: "57P01"}, want: true},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, want: true},
+		{name: "deadline", err: context.DeadlineExceeded, want: true},
+		{name: "open breaker", err: breaker.ErrOpen, want: true},
+		{name: "canceled caller", err: context.Canceled, want: false},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: false},
+		{name: "no rows", err: pgx.ErrNoRows, want: false},
+		{name: "not found", err: models.ErrOrderNotFound, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, orderRepoPostgres.IsUnavailable(tt.err))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package orderRepoRedis
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wbL0/internal/lib/breaker"
+	"wbL0/internal/models"
+)
+
+// breakerRepo fails fast with breaker.ErrOpen while Redis keeps failing, so a Redis outage costs
+// callers neither the dial timeout nor a connection attempt per request.
+type breakerRepo struct {
+	next OrderRedisRepoInterface
+	cb   *breaker.Breaker
+}
+
+// WithBreaker wraps the repository with the circuit breaker. A nil breaker returns repo unchanged.
+func WithBreaker(repo OrderRedisRepoInterface, cb *breaker.Breaker) OrderRedisRepoInterface {
+	if cb == nil {
+		return repo
+	}
+	return &breakerRepo{next: repo, cb: cb}
+}
+
+// failed reports whether err means Redis is unhealthy. A cached not-found marker is a valid answer,
+// and a canceled caller says nothing about Redis.
+func failed(err error) bool {
+	return err != nil && !errors.Is(err, models.ErrOrderNotFound) && !errors.Is(err, context.Canceled)
+}
+
+func (r *breakerRepo) call(fn func() error) error {
+	if err := r.cb.Allow(); err != nil {
+		return err
+	}
+	err := fn()
+	r.cb.Done(failed(err))
+	return err
+}
+
+func (r *breakerRepo) GetOrder(ctx context.Context, orderUID string) (fo *models.FullOrder, err error) {
+	err = r.call(func() error {
+		fo, err = r.next.GetOrder(ctx, orderUID)
+		return err
+	})
+	return fo, err
+}
+
+func (r *breakerRepo) SetOrder(ctx context.Context, order *models.FullOrder, ttl time.Duration) error {
+	return r.call(func() error { return r.next.SetOrder(ctx, order, ttl) })
+}
+
+func (r *breakerRepo) SetOrderNotFound(ctx context.Context, orderUID string, ttl time.Duration) error {
+	return r.call(func() error { return r.next.SetOrderNotFound(ctx, orderUID, ttl) })
+}
+
+func (r *breakerRepo) SetOrders(ctx context.Context, orders []*models.FullOrder, ttl time.Duration) error {
+	return r.call(func() error { return r.next.SetOrders(ctx, orders, ttl) })
+}
+
+func (r *breakerRepo) RestoreOrders(ctx context.Context, orders []*models.FullOrder, ttl time.Duration) error {
+	return r.call(func() error { return r.next.RestoreOrders(ctx, orders, ttl) })
+}
+
+// DeleteOrder is not gated by the breaker: a skipped eviction would leave a stale or erased order
+// to be served once Redis is back, so evictions always reach Redis and their result is returned to the caller.
+func (r *breakerRepo) DeleteOrder(ctx context.Context, orderUID string) error {
+	return r.next.DeleteOrder(ctx, orderUID)
+}
+
+// DeleteOrders is not gated by the breaker, see DeleteOrder.
+func (r *breakerRepo) DeleteOrders(ctx context.Context, orderUIDs []string) error {
+	return r.next.DeleteOrders(ctx, orderUIDs)
+}
@@ -0,0 +1,68 @@
+package orderRepoRedis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"wbL0/internal/lib/breaker"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	"wbL0/internal/repository/redis/orderRepoRedis"
+)
+
+func TestWithBreaker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("opens after failures and stops calling redis", func(t *testing.T) {
+		next := &mocks.OrderRedisRepoInterface{}
+		next.On("GetOrder", mock.Anything, "a").Return(nil, errors.New("dial tcp: connection refused")).Times(2)
+		repo := orderRepoRedis.WithBreaker(next, breaker.New("redis", breaker.Settings{FailureThreshold: 2, OpenTimeout: time.Minute}))
+
+		for i := 0; i < 2; i++ {
+			_, err := repo.GetOrder(ctx, "a")
+			assert.Error(t, err)
+		}
+		_, err := repo.GetOrder(ctx, "a")
+		assert.ErrorIs(t, err, breaker.ErrOpen)
+		assert.ErrorIs(t, repo.SetOrder(ctx, &models.FullOrder{}, time.Hour), breaker.ErrOpen)
+		next.AssertExpectations(t)
+	})
+
+	t.Run("evictions reach redis while the breaker is open", func(t *testing.T) {
+		next := &mocks.OrderRedisRepoInterface{}
+		next.On("GetOrder", mock.Anything, "a").Return(nil, errors.New("dial tcp: connection refused")).Once()
+		next.On("DeleteOrder", mock.Anything, "a").Return(nil).Once()
+		next.On("DeleteOrders", mock.Anything, []string{"a", "b"}).Return(errors.New("dial tcp: connection refused")).Once()
+		repo := orderRepoRedis.WithBreaker(next, breaker.New("redis", breaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute}))
+
+		_, err := repo.GetOrder(ctx, "a")
+		assert.Error(t, err)
+		assert.NoError(t, repo.DeleteOrder(ctx, "a"))
+		err = repo.DeleteOrders(ctx, []string{"a", "b"})
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, breaker.ErrOpen)
+		next.AssertExpectations(t)
+	})
+
+	t.Run("cached not-found marker is not a failure", func(t *testing.T) {
+		next := &mocks.OrderRedisRepoInterface{}
+		next.On("GetOrder", mock.Anything, "missing").Return(nil, models.ErrOrderNotFound).Times(3)
+		repo := orderRepoRedis.WithBreaker(next, breaker.New("redis", breaker.Settings{FailureThreshold: 2}))
+
+		for i := 0; i < 3; i++ {
+			_, err := repo.GetOrder(ctx, "missing")
+			assert.ErrorIs(t, err, models.ErrOrderNotFound)
+		}
+		next.AssertExpectations(t)
+	})
+
+	t.Run("nil breaker returns the repository", func(t *testing.T) {
+		next := &mocks.OrderRedisRepoInterface{}
+		assert.Same(t, next, orderRepoRedis.WithBreaker(next, nil))
+	})
+}
@@ -5,74 +5,188 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"log/slog"
 	"time"
+	"wbL0/internal/encryption"
 	"wbL0/internal/models"
+	"wbL0/internal/tracing"
 )
 
 //go:generate mockery --name=OrderRedisRepoInterface --dir=. --srcpkg=./internal/repository/redis/orderRepoRedis --output=../../../mocks --outpkg=mocks --case=underscore
 type OrderRedisRepoInterface interface {
 	GetOrder(ctx context.Context, orderUID string) (*models.FullOrder, error)
 	SetOrder(ctx context.Context, order *models.FullOrder, ttl time.Duration) error
+	SetOrderNotFound(ctx context.Context, orderUID string, ttl time.Duration) error
+	SetOrders(ctx context.Context, orders []*models.FullOrder, ttl time.Duration) error
 	RestoreOrders(ctx context.Context, orders []*models.FullOrder, ttl time.Duration) error
+	DeleteOrder(ctx context.Context, orderUID string) error
+	DeleteOrders(ctx context.Context, orderUIDs []string) error
 }
 
+// notFoundMarker is stored under the order key for UIDs known to be missing in Postgres.
+// Caching the order later overwrites it, so no separate invalidation is needed.
+const notFoundMarker = "-"
+
 type OrderRedisRepo struct {
-	rdb *redis.Client
-	log *slog.Logger
+	rdb  *redis.Client
+	log  *slog.Logger
+	keys *encryption.Keyring
 }
 
 func NewRedisRepo(rdb *redis.Client, log *slog.Logger) *OrderRedisRepo {
 	return &OrderRedisRepo{rdb: rdb, log: log}
 }
 
-func (r *OrderRedisRepo) GetOrder(ctx context.Context, orderUID string) (*models.FullOrder, error) {
+// WithEncryption stores cached orders sealed with keys from the keyring. Plain JSON entries cached
+// before encryption was enabled are still read until they expire.
+func (r *OrderRedisRepo) WithEncryption(keys *encryption.Keyring) *OrderRedisRepo {
+	r.keys = keys
+	return r
+}
+
+// GetOrder returns (nil, nil) on a cache miss and models.ErrOrderNotFound if the UID is cached as missing.
+func (r *OrderRedisRepo) GetOrder(ctx context.Context, orderUID string) (_ *models.FullOrder, err error) {
 	const op = "OrderRedisRepo.GetOrder"
+	ctx, span := tracing.Start(ctx, op, trace.WithAttributes(tracing.OrderUID(orderUID)))
+	defer func() { tracing.End(span, err, models.ErrOrderNotFound) }()
 	key := fmt.Sprintf("order:%s", orderUID)
 
 	raw, err := r.rdb.Get(ctx, key).Bytes()
 	if err == redis.Nil {
+		span.SetAttributes(attribute.String("cache.result", "miss"))
 		return nil, nil
 	}
 	if err != nil {
-		r.log.Warn("failed to get order from redis", "op", op, "err", err)
+		r.log.WarnContext(ctx, "failed to get order from redis", "op", op, "err", err)
 		return nil, err
 	}
+	if string(raw) == notFoundMarker {
+		span.SetAttributes(attribute.String("cache.result", "negative_hit"))
+		return nil, models.ErrOrderNotFound
+	}
+
+	if r.keys != nil {
+		if raw, err = r.keys.Open(raw); err != nil {
+			r.log.WarnContext(ctx, "failed to decrypt order from redis", "op", op, "err", err)
+			return nil, err
+		}
+	}
 
 	var fo models.FullOrder
 	if err := json.Unmarshal(raw, &fo); err != nil {
-		r.log.Warn("failed to unmarshal order from redis", "op", op, "err", err)
+		r.log.WarnContext(ctx, "failed to unmarshal order from redis", "op", op, "err", err)
 		return nil, err
 	}
+	span.SetAttributes(attribute.String("cache.result", "hit"))
 	return &fo, nil
 }
 
-func (r *OrderRedisRepo) SetOrder(ctx context.Context, order *models.FullOrder, ttl time.Duration) error {
+func (r *OrderRedisRepo) SetOrder(ctx context.Context, order *models.FullOrder, ttl time.Duration) (err error) {
 	const op = "OrderRedisRepo.SetOrder"
+	ctx, span := tracing.Start(ctx, op, trace.WithAttributes(tracing.OrderUID(order.Order.OrderUID)))
+	defer func() { tracing.End(span, err) }()
 	key := fmt.Sprintf("order:%s", order.Order.OrderUID)
 
-	data, err := json.Marshal(order)
+	data, err := r.encode(order)
 	if err != nil {
-		r.log.Warn("failed to marshal order for redis", "op", op, "err", err)
+		r.log.WarnContext(ctx, "failed to marshal order for redis", "op", op, "err", err)
 		return err
 	}
 
 	if err := r.rdb.Set(ctx, key, data, ttl).Err(); err != nil {
-		r.log.Warn("failed to set order in redis", "op", op, "err", err)
+		r.log.WarnContext(ctx, "failed to set order in redis", "op", op, "err", err)
+		return err
+	}
+	return nil
+}
+
+// SetOrderNotFound remembers for ttl that the order does not exist.
+func (r *OrderRedisRepo) SetOrderNotFound(ctx context.Context, orderUID string, ttl time.Duration) error {
+	const op = "OrderRedisRepo.SetOrderNotFound"
+	key := fmt.Sprintf("order:%s", orderUID)
+
+	if err := r.rdb.Set(ctx, key, notFoundMarker, ttl).Err(); err != nil {
+		r.log.Warn("failed to cache missing order in redis", "op", op, "orderUID", orderUID, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (r *OrderRedisRepo) DeleteOrder(ctx context.Context, orderUID string) error {
+	const op = "OrderRedisRepo.DeleteOrder"
+	key := fmt.Sprintf("order:%s", orderUID)
+
+	if err := r.rdb.Del(ctx, key).Err(); err != nil {
+		r.log.Warn("failed to delete order from redis", "op", op, "err", err)
 		return err
 	}
 	return nil
 }
 
+// DeleteOrders removes the orders with a single DEL.
+func (r *OrderRedisRepo) DeleteOrders(ctx context.Context, orderUIDs []string) error {
+	const op = "OrderRedisRepo.DeleteOrders"
+
+	if len(orderUIDs) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(orderUIDs))
+	for i, uid := range orderUIDs {
+		keys[i] = fmt.Sprintf("order:%s", uid)
+	}
+	if err := r.rdb.Del(ctx, keys...).Err(); err != nil {
+		r.log.Warn("failed to delete orders from redis", "op", op, "count", len(keys), "err", err)
+		return err
+	}
+	return nil
+}
+
+// SetOrders writes the orders in a single pipeline round trip, replacing cached ones.
+func (r *OrderRedisRepo) SetOrders(ctx context.Context, orders []*models.FullOrder, ttl time.Duration) error {
+	const op = "OrderRedisRepo.SetOrders"
+	return r.writeOrders(ctx, op, orders, func(pipe redis.Pipeliner, key string, data []byte) {
+		pipe.Set(ctx, key, data, ttl)
+	})
+}
+
+// RestoreOrders writes the orders that are not cached yet in a single pipeline round trip. It runs while
+// the consumer and the API already cache orders, so an existing entry is newer than the snapshot and is kept.
 func (r *OrderRedisRepo) RestoreOrders(ctx context.Context, orders []*models.FullOrder, ttl time.Duration) error {
 	const op = "OrderRedisRepo.RestoreOrders"
+	return r.writeOrders(ctx, op, orders, func(pipe redis.Pipeliner, key string, data []byte) {
+		pipe.SetNX(ctx, key, data, ttl)
+	})
+}
+
+func (r *OrderRedisRepo) writeOrders(ctx context.Context, op string, orders []*models.FullOrder, write func(pipe redis.Pipeliner, key string, data []byte)) error {
+	if len(orders) == 0 {
+		return nil
+	}
 
+	pipe := r.rdb.Pipeline()
 	for _, fo := range orders {
-		if err := r.SetOrder(ctx, fo, ttl); err != nil {
-			r.log.Warn("failed to restore order to redis", "op", op, "err", err)
+		data, err := r.encode(fo)
+		if err != nil {
+			r.log.Warn("failed to marshal order for redis", "op", op, "orderUID", fo.Order.OrderUID, "err", err)
 			continue
 		}
+		write(pipe, fmt.Sprintf("order:%s", fo.Order.OrderUID), data)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.log.Warn("failed to write orders to redis", "op", op, "err", err)
+		return err
 	}
-	r.log.Info("cache restored", "op", op, "count", len(orders))
 	return nil
 }
+
+func (r *OrderRedisRepo) encode(order *models.FullOrder) ([]byte, error) {
+	data, err := json.Marshal(order)
+	if err != nil || r.keys == nil {
+		return data, err
+	}
+	return r.keys.Seal(data)
+}
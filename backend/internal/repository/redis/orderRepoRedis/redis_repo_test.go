@@ -2,7 +2,9 @@ package orderRepoRedis_test
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"log/slog"
 
+	"wbL0/internal/encryption"
 	"wbL0/internal/models"
 	orderRepoRedis "wbL0/internal/repository/redis/orderRepoRedis"
 )
@@ -82,3 +85,107 @@ func TestOrderRedisRepo_GetSet(t *testing.T) {
 	// cleanup client
 	_ = rdb.Close()
 }
+
+func TestOrderRedisRepo_NotFoundMarker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rdb, mock := redismock.NewClientMock()
+	repo := orderRepoRedis.NewRedisRepo(rdb, slog.Default())
+
+	ttl := time.Minute
+	mock.ExpectSet("order:missing", "-", ttl).SetVal("OK")
+	mock.ExpectGet("order:missing").SetVal("-")
+
+	assert.NoError(t, repo.SetOrderNotFound(ctx, "missing", ttl))
+
+	got, err := repo.GetOrder(ctx, "missing")
+	assert.ErrorIs(t, err, models.ErrOrderNotFound)
+	assert.Nil(t, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_ = rdb.Close()
+}
+
+func TestOrderRedisRepo_DeleteOrders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rdb, mock := redismock.NewClientMock()
+	repo := orderRepoRedis.NewRedisRepo(rdb, slog.Default())
+
+	mock.ExpectDel("order:a", "order:b").SetVal(2)
+
+	assert.NoError(t, repo.DeleteOrders(ctx, []string{"a", "b"}))
+	assert.NoError(t, repo.DeleteOrders(ctx, nil), "nothing to delete makes no round trip")
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_ = rdb.Close()
+}
+
+func TestOrderRedisRepo_WriteOrders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rdb, mock := redismock.NewClientMock()
+	repo := orderRepoRedis.NewRedisRepo(rdb, slog.Default())
+	ttl := time.Hour
+
+	orders := []*models.FullOrder{{Order: models.Order{OrderUID: "a"}}, {Order: models.Order{OrderUID: "b"}}}
+	a, err := json.Marshal(orders[0])
+	assert.NoError(t, err)
+	b, err := json.Marshal(orders[1])
+	assert.NoError(t, err)
+
+	// Warm-up keeps entries written by the consumer or the API in the meantime.
+	mock.ExpectSetNX("order:a", a, ttl).SetVal(true)
+	mock.ExpectSetNX("order:b", b, ttl).SetVal(false)
+	assert.NoError(t, repo.RestoreOrders(ctx, orders, ttl))
+
+	mock.ExpectSet("order:a", a, ttl).SetVal("OK")
+	mock.ExpectSet("order:b", b, ttl).SetVal("OK")
+	assert.NoError(t, repo.SetOrders(ctx, orders, ttl))
+
+	assert.NoError(t, repo.RestoreOrders(ctx, nil, ttl), "nothing to write makes no round trip")
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_ = rdb.Close()
+}
+
+func TestOrderRedisRepo_GetEncrypted(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rdb, mock := redismock.NewClientMock()
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	keys, err := encryption.ParseKeyring([]byte(`{"active":"k1","keys":{"k1":"` + key + `"},"index_key":"` + key + `"}`))
+	assert.NoError(t, err)
+	repo := orderRepoRedis.NewRedisRepo(rdb, slog.Default()).WithEncryption(keys)
+
+	fo := &models.FullOrder{
+		Order:    models.Order{OrderUID: "r1"},
+		Delivery: models.Delivery{OrderUID: "r1", Email: "e@mail"},
+	}
+	data, err := json.Marshal(fo)
+	assert.NoError(t, err)
+	sealed, err := keys.Seal(data)
+	assert.NoError(t, err)
+
+	// sealed entries are opened transparently
+	mock.ExpectGet("order:r1").SetVal(string(sealed))
+	got, err := repo.GetOrder(ctx, "r1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, got) {
+		assert.Equal(t, "e@mail", got.Delivery.Email)
+	}
+
+	// plain JSON cached before encryption was enabled is still readable
+	mock.ExpectGet("order:r1").SetVal(string(data))
+	got, err = repo.GetOrder(ctx, "r1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, got) {
+		assert.Equal(t, "e@mail", got.Delivery.Email)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
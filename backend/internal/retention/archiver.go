@@ -0,0 +1,105 @@
+package retention
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"wbL0/internal/config"
+	"wbL0/internal/metrics"
+	"wbL0/internal/repository/postgres/orderRepoPostgres"
+	"wbL0/internal/repository/redis/orderRepoRedis"
+)
+
+const (
+	defaultInterval   = time.Hour
+	defaultBatchSize  = 500
+	defaultMaxBatches = 20
+)
+
+// Archiver moves orders older than the retention period from the hot tables to the archive and evicts them
+// from Redis. Each batch is archived in its own statement, so a run holds row locks for one batch at a time
+// and an interrupted run loses nothing.
+type Archiver struct {
+	repo       orderRepoPostgres.ArchiveRepositoryInterface
+	redisRepo  orderRepoRedis.OrderRedisRepoInterface
+	log        *slog.Logger
+	retention  time.Duration
+	interval   time.Duration
+	batchSize  int
+	maxBatches int
+	now        func() time.Time
+}
+
+// NewArchiver returns nil when retention is disabled.
+func NewArchiver(cfg config.RetentionConfig, repo orderRepoPostgres.ArchiveRepositoryInterface, redisRepo orderRepoRedis.OrderRedisRepoInterface, log *slog.Logger) *Archiver {
+	if !cfg.Enabled || cfg.Days <= 0 {
+		return nil
+	}
+	return newArchiver(repo, redisRepo, log, time.Duration(cfg.Days)*24*time.Hour, cfg.Interval, cfg.BatchSize, cfg.MaxBatches)
+}
+
+func newArchiver(repo orderRepoPostgres.ArchiveRepositoryInterface, redisRepo orderRepoRedis.OrderRedisRepoInterface, log *slog.Logger, retention, interval time.Duration, batchSize, maxBatches int) *Archiver {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if maxBatches <= 0 {
+		maxBatches = defaultMaxBatches
+	}
+	return &Archiver{
+		repo:       repo,
+		redisRepo:  redisRepo,
+		log:        log,
+		retention:  retention,
+		interval:   interval,
+		batchSize:  batchSize,
+		maxBatches: maxBatches,
+		now:        time.Now,
+	}
+}
+
+// Run archives orders right away and then every interval until the context is canceled.
+func (a *Archiver) Run(ctx context.Context) {
+	const op = "retention.Run"
+
+	for {
+		if _, err := a.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			a.log.Warn("failed to archive orders", "op", op, "err", err)
+		}
+		select {
+		case <-time.After(a.interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunOnce archives batches of orders created before the cutoff until a batch is not full or maxBatches
+// batches were archived, and returns the number of archived orders. The cutoff is fixed for the whole run.
+func (a *Archiver) RunOnce(ctx context.Context) (int, error) {
+	const op = "retention.RunOnce"
+
+	cutoff := a.now().Add(-a.retention)
+	total := 0
+	for i := 0; i < a.maxBatches; i++ {
+		uids, err := a.repo.ArchiveOrders(ctx, cutoff, a.batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += len(uids)
+		metrics.OrdersArchived.Add(float64(len(uids)))
+
+		if err := a.redisRepo.DeleteOrders(ctx, uids); err != nil {
+			a.log.Warn("failed to evict archived orders from redis", "op", op, "count", len(uids), "err", err)
+		}
+		if len(uids) < a.batchSize {
+			break
+		}
+	}
+	if total > 0 {
+		a.log.Info("orders archived", "op", op, "count", total, "cutoff", cutoff)
+	}
+	return total, nil
+}
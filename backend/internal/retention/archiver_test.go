@@ -0,0 +1,99 @@
+package retention_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"wbL0/internal/config"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/retention"
+)
+
+func TestArchiver_RunOnce(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		batches    [][]string
+		archiveErr error
+		redisErr   error
+		maxBatches int
+		wantTotal  int
+		wantErr    bool
+	}{
+		{
+			name:       "stops after a partial batch",
+			batches:    [][]string{{"a", "b"}, {"c"}},
+			maxBatches: 10,
+			wantTotal:  3,
+		},
+		{
+			name:       "stops at max batches",
+			batches:    [][]string{{"a", "b"}, {"c", "d"}},
+			maxBatches: 2,
+			wantTotal:  4,
+		},
+		{
+			name:       "nothing to archive",
+			batches:    [][]string{nil},
+			maxBatches: 10,
+		},
+		{
+			name:       "redis failure does not stop archiving",
+			batches:    [][]string{{"a", "b"}, {"c"}},
+			redisErr:   errors.New("redis down"),
+			maxBatches: 10,
+			wantTotal:  3,
+		},
+		{
+			name:       "archive failure is returned",
+			batches:    [][]string{{"a", "b"}},
+			archiveErr: errors.New("db down"),
+			maxBatches: 10,
+			wantTotal:  2,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.ArchiveRepositoryInterface{}
+			redisRepo := &mocks.OrderRedisRepoInterface{}
+			for _, uids := range tt.batches {
+				repo.On("ArchiveOrders", mock.Anything, cutoff, 2).Return(uids, nil).Once()
+				redisRepo.On("DeleteOrders", mock.Anything, uids).Return(tt.redisErr).Once()
+			}
+			if tt.archiveErr != nil {
+				repo.On("ArchiveOrders", mock.Anything, cutoff, 2).Return(nil, tt.archiveErr).Once()
+			}
+
+			a := retention.NewTestArchiver(repo, redisRepo, 30*24*time.Hour, 2, tt.maxBatches, now)
+			total, err := a.RunOnce(ctx)
+
+			assert.Equal(t, tt.wantTotal, total)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			repo.AssertExpectations(t)
+			redisRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewArchiver(t *testing.T) {
+	repo := &mocks.ArchiveRepositoryInterface{}
+	redisRepo := &mocks.OrderRedisRepoInterface{}
+
+	assert.Nil(t, retention.NewArchiver(config.RetentionConfig{Days: 30}, repo, redisRepo, nil))
+	assert.Nil(t, retention.NewArchiver(config.RetentionConfig{Enabled: true}, repo, redisRepo, nil))
+	assert.NotNil(t, retention.NewArchiver(config.RetentionConfig{Enabled: true, Days: 30}, repo, redisRepo, nil))
+}
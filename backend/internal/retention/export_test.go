@@ -0,0 +1,14 @@
+package retention
+
+import (
+	"log/slog"
+	"time"
+	"wbL0/internal/repository/postgres/orderRepoPostgres"
+	"wbL0/internal/repository/redis/orderRepoRedis"
+)
+
+func NewTestArchiver(repo orderRepoPostgres.ArchiveRepositoryInterface, redisRepo orderRepoRedis.OrderRedisRepoInterface, retention time.Duration, batchSize, maxBatches int, now time.Time) *Archiver {
+	a := newArchiver(repo, redisRepo, slog.Default(), retention, time.Millisecond, batchSize, maxBatches)
+	a.now = func() time.Time { return now }
+	return a
+}
@@ -0,0 +1,126 @@
+package orderService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wbL0/internal/lib/backoff"
+	"wbL0/internal/models"
+)
+
+const (
+	defaultEvictionInterval = time.Second
+	evictionBatchSize       = 500
+	evictionRetryTimeout    = 3 * time.Second
+	evictionRetryBase       = 100 * time.Millisecond
+	evictionRetryMax        = time.Second
+)
+
+// RunEvictions retries the queued Redis evictions and drops recently erased orders from the local tier
+// right away and then every interval until the context is canceled. Every instance runs it, so an erased
+// order leaves the local tier of other instances within interval.
+func (s *OrderService) RunEvictions(ctx context.Context, interval time.Duration) {
+	const op = "OrderService.RunEvictions"
+
+	if interval <= 0 {
+		interval = defaultEvictionInterval
+	}
+	for {
+		if err := s.EvictOnce(ctx, interval); err != nil && ctx.Err() == nil {
+			s.log.Warn("failed to evict erased orders", "op", op, "err", err)
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// EvictOnce makes one attempt at the queued Redis evictions and drops the orders erased within the local tier
+// TTL plus interval from the local tier.
+func (s *OrderService) EvictOnce(ctx context.Context, interval time.Duration) error {
+	err := s.evictPending(ctx, 0)
+	if s.local != nil {
+		uids, localErr := s.repo.GetRecentlyErasedOrderUIDs(ctx, s.localTTL+interval)
+		if localErr != nil {
+			return errors.Join(err, localErr)
+		}
+		for _, uid := range uids {
+			s.local.Delete(uid)
+		}
+	}
+	return err
+}
+
+// evictPending evicts the queued orders from Redis, retrying each batch for up to timeout, and removes them
+// from the queue. It fails with models.ErrEvictionPending while the queue is not empty.
+func (s *OrderService) evictPending(ctx context.Context, timeout time.Duration) error {
+	const op = "OrderService.evictPending"
+
+	for {
+		uids, err := s.repo.GetPendingEvictions(ctx, evictionBatchSize)
+		if err != nil {
+			return fmt.Errorf("%w: %w", models.ErrEvictionPending, err)
+		}
+		if len(uids) == 0 {
+			return nil
+		}
+
+		err = backoff.Retry(ctx, timeout, evictionRetryBase, evictionRetryMax, func(ctx context.Context) error {
+			return s.redisRepo.DeleteOrders(ctx, uids)
+		}, nil)
+		if err != nil {
+			s.log.Warn("failed to evict orders from redis", "op", op, "count", len(uids), "err", err)
+			return fmt.Errorf("%w: %w", models.ErrEvictionPending, err)
+		}
+		if err := s.repo.DeletePendingEvictions(ctx, uids); err != nil {
+			return fmt.Errorf("%w: %w", models.ErrEvictionPending, err)
+		}
+		s.log.Info("orders evicted from redis", "op", op, "count", len(uids))
+
+		if len(uids) < evictionBatchSize {
+			return nil
+		}
+	}
+}
+
+// dropErased removes the just cached orders from both tiers if they were erased in the meantime. An erasure
+// evicts after its commit and a cache write checks after writing, so whichever of them touches the cache
+// last sees the other and erased data does not stay cached. Orders that are already anonymized are kept.
+func (s *OrderService) dropErased(ctx context.Context, fos []*models.FullOrder) {
+	const op = "OrderService.dropErased"
+
+	uids := make([]string, 0, len(fos))
+	for _, fo := range fos {
+		if fo.Order.CustomerID != models.ErasedValue {
+			uids = append(uids, fo.Order.OrderUID)
+		}
+	}
+	if len(uids) == 0 {
+		return
+	}
+
+	erased, err := s.repo.GetErasedOrderUIDs(ctx, uids)
+	if err != nil {
+		// A cache miss is cheaper than serving an order that might be erased.
+		s.log.WarnContext(ctx, "failed to check orders for erasure, evicting them", "op", op, "count", len(uids), "err", err)
+		erased = uids
+	}
+	if len(erased) == 0 {
+		return
+	}
+
+	if s.local != nil {
+		for _, uid := range erased {
+			s.local.Delete(uid)
+		}
+	}
+	if err := s.redisRepo.DeleteOrders(ctx, erased); err != nil {
+		s.log.WarnContext(ctx, "failed to evict erased orders from redis, queueing them", "op", op, "count", len(erased), "err", err)
+		if err := s.repo.SavePendingEvictions(ctx, erased); err != nil {
+			s.log.ErrorContext(ctx, "failed to queue erased orders for eviction", "op", op, "count", len(erased), "err", err)
+		}
+	}
+}
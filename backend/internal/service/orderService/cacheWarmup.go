@@ -0,0 +1,78 @@
+package orderService
+
+import (
+	"context"
+	"time"
+	"wbL0/internal/metrics"
+	"wbL0/internal/models"
+)
+
+const defaultWarmupBatchSize = 500
+
+// WarmupOptions limits which orders are loaded into Redis on startup.
+// MaxOrders keeps only the most recent N orders and MaxAge only orders created within that period; zero disables the limit.
+type WarmupOptions struct {
+	MaxOrders int
+	MaxAge    time.Duration
+	BatchSize int
+}
+
+// RestoreCacheFromDB streams orders from Postgres newest first and writes them to Redis batch by batch,
+// so memory use is bounded by the batch size rather than the table size. Orders cached in the meantime by the
+// consumer or the API are newer than the snapshot and are not replaced.
+func (s *OrderService) RestoreCacheFromDB(ctx context.Context, opts WarmupOptions) error {
+	const op = "OrderService.RestoreCacheFromDB"
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultWarmupBatchSize
+	}
+	var since *time.Time
+	if opts.MaxAge > 0 {
+		t := time.Now().Add(-opts.MaxAge)
+		since = &t
+	}
+
+	start := time.Now()
+	metrics.CacheWarmupOrders.Set(0)
+	s.log.Info("cache warm-up started", "op", op, "maxOrders", opts.MaxOrders, "maxAge", opts.MaxAge, "batchSize", batchSize)
+
+	var (
+		cursor  *models.OrderCursor
+		scanned int
+		cached  int
+	)
+	for {
+		limit := batchSize
+		if opts.MaxOrders > 0 && opts.MaxOrders-scanned < limit {
+			limit = opts.MaxOrders - scanned
+		}
+		if limit <= 0 {
+			break
+		}
+
+		orders, next, err := s.repo.GetFullOrdersBatch(ctx, cursor, since, limit)
+		if err != nil {
+			s.log.Error("failed to get orders batch from postgres", "op", op, "cached", cached, "err", err)
+			return err
+		}
+		scanned += limit
+
+		if err := s.redisRepo.RestoreOrders(ctx, orders, s.ttl); err != nil {
+			s.log.Warn("failed to restore orders batch to redis", "op", op, "err", err)
+		} else {
+			s.dropErased(ctx, orders)
+			cached += len(orders)
+			metrics.CacheWarmupOrders.Set(float64(cached))
+		}
+		s.log.Info("cache warm-up progress", "op", op, "cached", cached)
+
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+
+	s.log.Info("cache restored", "op", op, "count", cached, "duration", time.Since(start))
+	return nil
+}
@@ -0,0 +1,146 @@
+package orderService_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+// expectNotErased answers the erasure check that follows every cache write.
+func expectNotErased(pgMock *mocks.OrderPostgresRepositoryInterface) {
+	pgMock.On("GetErasedOrderUIDs", mock.Anything, mock.Anything).Return([]string(nil), nil).Maybe()
+}
+
+func TestOrderService_GetOrder_ErasedWhileLoading(t *testing.T) {
+	ctx := context.Background()
+	fo := &models.FullOrder{Order: models.Order{OrderUID: "order123", CustomerID: "c1"}}
+	down := errors.New("redis down")
+
+	tests := []struct {
+		name      string
+		erased    []string
+		checkErr  error
+		deleteErr error
+		queued    bool
+	}{
+		{name: "order erased after it was read is evicted", erased: []string{"order123"}},
+		{name: "failed eviction is queued", erased: []string{"order123"}, deleteErr: down, queued: true},
+		{name: "failed check evicts the order", checkErr: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pgMock := &mocks.OrderPostgresRepositoryInterface{}
+			rMock := &mocks.OrderRedisRepoInterface{}
+			rMock.On("GetOrder", lookupCtx, "order123").Return(nil, nil)
+			pgMock.On("GetFullOrderByUID", lookupCtx, "order123").Return(fo, nil)
+			rMock.On("SetOrder", lookupCtx, fo, time.Hour).Return(nil)
+			pgMock.On("GetErasedOrderUIDs", lookupCtx, []string{"order123"}).Return(tt.erased, tt.checkErr)
+			rMock.On("DeleteOrders", lookupCtx, []string{"order123"}).Return(tt.deleteErr)
+			if tt.queued {
+				pgMock.On("SavePendingEvictions", lookupCtx, []string{"order123"}).Return(nil)
+			}
+
+			service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour).WithLocalCache(10, time.Minute)
+			for i := 0; i < 2; i++ {
+				_, err := service.GetOrder(ctx, "order123")
+				assert.NoError(t, err)
+			}
+
+			// The local tier does not keep the order, so the second lookup goes to Redis again.
+			rMock.AssertNumberOfCalls(t, "GetOrder", 2)
+			pgMock.AssertExpectations(t)
+			rMock.AssertExpectations(t)
+		})
+	}
+
+	t.Run("anonymized order is cached without a check", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		erased := &models.FullOrder{Order: models.Order{OrderUID: "order123", CustomerID: models.ErasedValue}}
+		rMock.On("GetOrder", lookupCtx, "order123").Return(nil, nil).Once()
+		pgMock.On("GetFullOrderByUID", lookupCtx, "order123").Return(erased, nil).Once()
+		rMock.On("SetOrder", lookupCtx, erased, time.Hour).Return(nil).Once()
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour).WithLocalCache(10, time.Minute)
+		for i := 0; i < 2; i++ {
+			got, err := service.GetOrder(ctx, "order123")
+			assert.NoError(t, err)
+			assert.Equal(t, erased, got)
+		}
+		pgMock.AssertExpectations(t)
+		rMock.AssertExpectations(t)
+	})
+}
+
+func TestOrderService_RestoreCacheFromDB_DropsErased(t *testing.T) {
+	ctx := context.Background()
+	orders := []*models.FullOrder{validFullOrder("a"), validFullOrder("b")}
+	pgMock := &mocks.OrderPostgresRepositoryInterface{}
+	rMock := &mocks.OrderRedisRepoInterface{}
+	pgMock.On("GetFullOrdersBatch", ctx, (*models.OrderCursor)(nil), (*time.Time)(nil), 2).Return(orders, (*models.OrderCursor)(nil), nil).Once()
+	rMock.On("RestoreOrders", ctx, orders, time.Hour).Return(nil).Once()
+	pgMock.On("GetErasedOrderUIDs", ctx, []string{"a", "b"}).Return([]string{"b"}, nil).Once()
+	rMock.On("DeleteOrders", ctx, []string{"b"}).Return(nil).Once()
+
+	service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+	assert.NoError(t, service.RestoreCacheFromDB(ctx, svc.WarmupOptions{BatchSize: 2}))
+	pgMock.AssertExpectations(t)
+	rMock.AssertExpectations(t)
+}
+
+func TestOrderService_EvictOnce(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queued orders are evicted and dequeued", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		pgMock.On("GetPendingEvictions", ctx, mock.Anything).Return([]string{"o1", "o2"}, nil).Once()
+		rMock.On("DeleteOrders", mock.Anything, []string{"o1", "o2"}).Return(nil).Once()
+		pgMock.On("DeletePendingEvictions", ctx, []string{"o1", "o2"}).Return(nil).Once()
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+		assert.NoError(t, service.EvictOnce(ctx, time.Second))
+		pgMock.AssertExpectations(t)
+		rMock.AssertExpectations(t)
+	})
+
+	t.Run("failed eviction stays queued", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		pgMock.On("GetPendingEvictions", ctx, mock.Anything).Return([]string{"o1"}, nil).Once()
+		rMock.On("DeleteOrders", mock.Anything, []string{"o1"}).Return(errors.New("redis down")).Once()
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+		assert.ErrorIs(t, service.EvictOnce(ctx, time.Second), models.ErrEvictionPending)
+		pgMock.AssertNotCalled(t, "DeletePendingEvictions", mock.Anything, mock.Anything)
+	})
+
+	t.Run("recently erased orders leave the local tier", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		fo := &models.FullOrder{Order: models.Order{OrderUID: "o1"}}
+		rMock.On("GetOrder", lookupCtx, "o1").Return(fo, nil).Twice()
+		pgMock.On("GetPendingEvictions", ctx, mock.Anything).Return([]string(nil), nil).Once()
+		pgMock.On("GetRecentlyErasedOrderUIDs", ctx, time.Minute+time.Second).Return([]string{"o1"}, nil).Once()
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour).WithLocalCache(10, time.Minute)
+		_, err := service.GetOrder(ctx, "o1")
+		assert.NoError(t, err)
+		assert.NoError(t, service.EvictOnce(ctx, time.Second))
+		_, err = service.GetOrder(ctx, "o1")
+		assert.NoError(t, err)
+
+		rMock.AssertExpectations(t)
+		pgMock.AssertExpectations(t)
+	})
+}
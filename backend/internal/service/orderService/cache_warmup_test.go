@@ -0,0 +1,97 @@
+package orderService_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+func TestOrderService_RestoreCacheFromDB(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	first := []*models.FullOrder{validFullOrder("a"), validFullOrder("b")}
+	second := []*models.FullOrder{validFullOrder("c")}
+	cursor := &models.OrderCursor{DateCreated: now, OrderUID: "b"}
+
+	t.Run("streams batches until the cursor is exhausted", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		pgMock.On("GetFullOrdersBatch", ctx, (*models.OrderCursor)(nil), (*time.Time)(nil), 2).Return(first, cursor, nil).Once()
+		pgMock.On("GetFullOrdersBatch", ctx, cursor, (*time.Time)(nil), 2).Return(second, (*models.OrderCursor)(nil), nil).Once()
+		rMock.On("RestoreOrders", ctx, first, time.Hour).Return(nil).Once()
+		rMock.On("RestoreOrders", ctx, second, time.Hour).Return(nil).Once()
+		expectNotErased(pgMock)
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+		err := service.RestoreCacheFromDB(ctx, svc.WarmupOptions{BatchSize: 2})
+		assert.NoError(t, err)
+		pgMock.AssertExpectations(t)
+		rMock.AssertExpectations(t)
+	})
+
+	t.Run("max orders caps the last batch", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		pgMock.On("GetFullOrdersBatch", ctx, (*models.OrderCursor)(nil), (*time.Time)(nil), 2).Return(first, cursor, nil).Once()
+		pgMock.On("GetFullOrdersBatch", ctx, cursor, (*time.Time)(nil), 1).Return(second, &models.OrderCursor{OrderUID: "c"}, nil).Once()
+		rMock.On("RestoreOrders", ctx, mock.Anything, time.Hour).Return(nil)
+		expectNotErased(pgMock)
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+		err := service.RestoreCacheFromDB(ctx, svc.WarmupOptions{MaxOrders: 3, BatchSize: 2})
+		assert.NoError(t, err)
+		pgMock.AssertExpectations(t)
+	})
+
+	t.Run("max age limits the window", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		before := time.Now().Add(-24 * time.Hour)
+		pgMock.On("GetFullOrdersBatch", ctx, (*models.OrderCursor)(nil), mock.MatchedBy(func(since *time.Time) bool {
+			return since != nil && !since.Before(before)
+		}), 500).Return(first, (*models.OrderCursor)(nil), nil).Once()
+		rMock.On("RestoreOrders", ctx, first, time.Hour).Return(nil).Once()
+		expectNotErased(pgMock)
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+		err := service.RestoreCacheFromDB(ctx, svc.WarmupOptions{MaxAge: 24 * time.Hour})
+		assert.NoError(t, err)
+		pgMock.AssertExpectations(t)
+	})
+
+	t.Run("redis failure does not stop warm-up", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		pgMock.On("GetFullOrdersBatch", ctx, (*models.OrderCursor)(nil), (*time.Time)(nil), 2).Return(first, cursor, nil).Once()
+		pgMock.On("GetFullOrdersBatch", ctx, cursor, (*time.Time)(nil), 2).Return(second, (*models.OrderCursor)(nil), nil).Once()
+		rMock.On("RestoreOrders", ctx, first, time.Hour).Return(errors.New("redis down")).Once()
+		rMock.On("RestoreOrders", ctx, second, time.Hour).Return(nil).Once()
+		expectNotErased(pgMock)
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+		err := service.RestoreCacheFromDB(ctx, svc.WarmupOptions{BatchSize: 2})
+		assert.NoError(t, err)
+		pgMock.AssertExpectations(t)
+	})
+
+	t.Run("postgres error is returned", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		pgMock.On("GetFullOrdersBatch", ctx, (*models.OrderCursor)(nil), (*time.Time)(nil), 2).
+			Return(([]*models.FullOrder)(nil), (*models.OrderCursor)(nil), errors.New("postgres error")).Once()
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+		err := service.RestoreCacheFromDB(ctx, svc.WarmupOptions{BatchSize: 2})
+		assert.Error(t, err)
+		rMock.AssertNotCalled(t, "RestoreOrders", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
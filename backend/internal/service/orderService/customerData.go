@@ -0,0 +1,87 @@
+package orderService
+
+import (
+	"context"
+	"time"
+	"wbL0/internal/audit"
+	"wbL0/internal/models"
+)
+
+// ExportCustomerData returns every stored order of the customer with delivery, payment and items, followed by
+// the archived ones when the archive is enabled. Erased orders are no longer linked to the customer and are not exported.
+func (s *OrderService) ExportCustomerData(ctx context.Context, customerID string) (*models.CustomerExport, error) {
+	const op = "OrderService.ExportCustomerData"
+
+	uids, err := s.repo.GetOrderUIDsByCustomer(ctx, customerID)
+	if err != nil {
+		s.log.Error("failed to find customer orders", "op", op, "err", err)
+		return nil, err
+	}
+	orders, err := s.repo.GetFullOrdersByUIDs(ctx, uids)
+	if err != nil {
+		s.log.Error("failed to read customer orders", "op", op, "err", err)
+		return nil, err
+	}
+	if s.archive != nil {
+		archived, err := s.archive.GetArchivedOrdersByCustomer(ctx, customerID)
+		if err != nil {
+			s.log.Error("failed to read archived customer orders", "op", op, "err", err)
+			return nil, err
+		}
+		orders = append(orders, archived...)
+	}
+	if orders == nil {
+		orders = []*models.FullOrder{}
+	}
+
+	s.log.Info("customer data exported", "op", op, "source", audit.SourceFor(ctx, ""), "count", len(orders))
+	return &models.CustomerExport{CustomerID: customerID, ExportedAt: time.Now().UTC(), Orders: orders}, nil
+}
+
+// EraseCustomerData anonymizes the customer's orders in Postgres, records the erasure in the audit log and
+// an erased version of every order, and evicts the orders from the caches. Erasing a customer without
+// orders is recorded as well. When Redis cannot be reached the erasure stays committed, the eviction is
+// retried in the background and models.ErrEvictionPending is returned, so success means no cache holds the data.
+func (s *OrderService) EraseCustomerData(ctx context.Context, customerID, reason string) (*models.CustomerErasure, error) {
+	const op = "OrderService.EraseCustomerData"
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.log.Error("failed to begin transaction", "op", op, "err", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	erasure := &models.CustomerErasure{RequestedBy: audit.SourceFor(ctx, ""), Reason: reason}
+	if err := s.repo.EraseCustomerTx(ctx, tx, customerID, erasure); err != nil {
+		s.log.Error("failed to erase customer data", "op", op, "err", err)
+		return nil, err
+	}
+	versions := make([]models.OrderVersion, 0, len(erasure.OrderUIDs))
+	for _, uid := range erasure.OrderUIDs {
+		version, _ := models.NewOrderVersion(uid, models.VersionErased, erasure.RequestedBy, nil)
+		versions = append(versions, version)
+	}
+	if err := s.repo.SaveOrderVersionsTx(ctx, tx, versions); err != nil {
+		s.log.Error("failed to save order versions", "op", op, "err", err)
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		s.log.Error("failed to commit transaction", "op", op, "err", err)
+		return nil, err
+	}
+	s.log.Info("customer data erased", "op", op, "erasureID", erasure.ID, "requestedBy", erasure.RequestedBy, "count", len(erasure.OrderUIDs))
+
+	if s.local != nil {
+		for _, uid := range erasure.OrderUIDs {
+			s.local.Delete(uid)
+		}
+	}
+	// The erased orders were queued for eviction in the transaction. Evicting every queued order also
+	// completes earlier erasures that failed to reach Redis, so retrying a failed request is enough.
+	if err := s.evictPending(ctx, evictionRetryTimeout); err != nil {
+		s.log.Error("erased orders may still be cached in redis", "op", op, "erasureID", erasure.ID, "err", err)
+		return nil, err
+	}
+	return erasure, nil
+}
@@ -0,0 +1,141 @@
+package orderService_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+
+	"wbL0/internal/audit"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+func TestOrderService_ExportCustomerData(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("exports every order of the customer", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		orders := []*models.FullOrder{{Order: models.Order{OrderUID: "o1", CustomerID: "c1"}}}
+		pgMock.On("GetOrderUIDsByCustomer", mock.Anything, "c1").Return([]string{"o1"}, nil)
+		pgMock.On("GetFullOrdersByUIDs", mock.Anything, []string{"o1"}).Return(orders, nil)
+
+		service := svc.NewOrderService(pgMock, &mocks.OrderRedisRepoInterface{}, slog.Default(), time.Hour)
+		export, err := service.ExportCustomerData(ctx, "c1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "c1", export.CustomerID)
+		assert.Equal(t, orders, export.Orders)
+		assert.False(t, export.ExportedAt.IsZero())
+	})
+
+	t.Run("customer without orders gets an empty archive", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		pgMock.On("GetOrderUIDsByCustomer", mock.Anything, "c2").Return(nil, nil)
+		pgMock.On("GetFullOrdersByUIDs", mock.Anything, []string(nil)).Return(nil, nil)
+
+		service := svc.NewOrderService(pgMock, &mocks.OrderRedisRepoInterface{}, slog.Default(), time.Hour)
+		export, err := service.ExportCustomerData(ctx, "c2")
+
+		require.NoError(t, err)
+		assert.NotNil(t, export.Orders)
+		assert.Empty(t, export.Orders)
+	})
+
+	t.Run("archived orders follow the hot ones", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		archiveMock := &mocks.ArchiveRepositoryInterface{}
+		hot := []*models.FullOrder{{Order: models.Order{OrderUID: "o2", CustomerID: "c1"}}}
+		archived := []*models.FullOrder{{Order: models.Order{OrderUID: "o1", CustomerID: "c1"}}}
+		pgMock.On("GetOrderUIDsByCustomer", mock.Anything, "c1").Return([]string{"o2"}, nil)
+		pgMock.On("GetFullOrdersByUIDs", mock.Anything, []string{"o2"}).Return(hot, nil)
+		archiveMock.On("GetArchivedOrdersByCustomer", mock.Anything, "c1").Return(archived, nil)
+
+		service := svc.NewOrderService(pgMock, &mocks.OrderRedisRepoInterface{}, slog.Default(), time.Hour).WithArchive(archiveMock, true)
+		export, err := service.ExportCustomerData(ctx, "c1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []*models.FullOrder{hot[0], archived[0]}, export.Orders)
+	})
+}
+
+func TestOrderService_EraseCustomerData(t *testing.T) {
+	ctx := audit.WithSource(context.Background(), "http:admin")
+
+	tests := []struct {
+		name     string
+		uids     []string
+		pending  []string
+		evictErr error
+		eraseErr error
+		wantErr  error
+	}{
+		{name: "orders are erased, versioned and evicted", uids: []string{"o1", "o2"}, pending: []string{"o1", "o2"}},
+		{name: "customer without orders is still recorded"},
+		{name: "earlier pending evictions are completed", pending: []string{"o0"}},
+		{name: "redis down fails the erasure", uids: []string{"o1"}, pending: []string{"o1"}, evictErr: errors.New("redis down"), wantErr: models.ErrEvictionPending},
+		{name: "db error", eraseErr: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pgMock := &mocks.OrderPostgresRepositoryInterface{}
+			rMock := &mocks.OrderRedisRepoInterface{}
+			tx := &mocks.PgxTx{}
+			pgMock.On("BeginTx", mock.Anything).Return(tx, nil)
+			tx.On("Rollback", mock.Anything).Return(nil)
+			pgMock.On("EraseCustomerTx", mock.Anything, tx, "c1", mock.MatchedBy(func(e *models.CustomerErasure) bool {
+				return e.RequestedBy == "http:admin" && e.Reason == "request #1"
+			})).Run(func(args mock.Arguments) {
+				e := args.Get(3).(*models.CustomerErasure)
+				e.ID, e.OrderUIDs = 7, tt.uids
+			}).Return(tt.eraseErr)
+			if tt.eraseErr == nil {
+				pgMock.On("SaveOrderVersionsTx", mock.Anything, tx, mock.MatchedBy(func(v []models.OrderVersion) bool {
+					if len(v) != len(tt.uids) {
+						return false
+					}
+					for i := range v {
+						if v[i].OrderUID != tt.uids[i] || v[i].Operation != models.VersionErased || v[i].Current != nil {
+							return false
+						}
+					}
+					return true
+				})).Return(nil)
+				tx.On("Commit", mock.Anything).Return(nil)
+				pgMock.On("GetPendingEvictions", mock.Anything, mock.Anything).Return(tt.pending, nil).Once()
+				if len(tt.pending) > 0 {
+					rMock.On("DeleteOrders", mock.Anything, tt.pending).Return(tt.evictErr)
+				}
+				if len(tt.pending) > 0 && tt.evictErr == nil {
+					pgMock.On("DeletePendingEvictions", mock.Anything, tt.pending).Return(nil).Once()
+				}
+			}
+
+			// The eviction is retried until the request context is done.
+			reqCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+
+			service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+			erasure, err := service.EraseCustomerData(reqCtx, "c1", "request #1")
+
+			switch {
+			case tt.eraseErr != nil:
+				assert.ErrorIs(t, err, tt.eraseErr)
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, int64(7), erasure.ID)
+			}
+			pgMock.AssertExpectations(t)
+			rMock.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package orderService_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+func TestOrderService_GetOrder_Archive(t *testing.T) {
+	ctx := context.Background()
+	archived := &models.FullOrder{Order: models.Order{OrderUID: "old"}}
+
+	tests := []struct {
+		name         string
+		archiveOrder *models.FullOrder
+		archiveErr   error
+		want         *models.FullOrder
+		setup        func(rMock *mocks.OrderRedisRepoInterface)
+	}{
+		{
+			name:         "archived order is returned and cached",
+			archiveOrder: archived,
+			want:         archived,
+			setup: func(rMock *mocks.OrderRedisRepoInterface) {
+				rMock.On("SetOrder", lookupCtx, archived, time.Hour).Return(nil).Once()
+			},
+		},
+		{
+			name:       "order missing in the archive is remembered",
+			archiveErr: models.ErrOrderNotFound,
+			setup: func(rMock *mocks.OrderRedisRepoInterface) {
+				rMock.On("SetOrderNotFound", lookupCtx, "old", 30*time.Second).Return(nil).Once()
+			},
+		},
+		{
+			name:       "archive failure is not cached",
+			archiveErr: errors.New("db down"),
+			setup:      func(*mocks.OrderRedisRepoInterface) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pgMock := &mocks.OrderPostgresRepositoryInterface{}
+			expectNotErased(pgMock)
+			rMock := &mocks.OrderRedisRepoInterface{}
+			archiveMock := &mocks.ArchiveRepositoryInterface{}
+			rMock.On("GetOrder", lookupCtx, "old").Return(nil, nil).Once()
+			pgMock.On("GetFullOrderByUID", lookupCtx, "old").Return(nil, models.ErrOrderNotFound).Once()
+			archiveMock.On("GetArchivedOrder", lookupCtx, "old").Return(tt.archiveOrder, tt.archiveErr).Once()
+			tt.setup(rMock)
+
+			service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour).
+				WithNegativeCache(30*time.Second).
+				WithArchive(archiveMock, true)
+			got, err := service.GetOrder(ctx, "old")
+
+			assert.ErrorIs(t, err, tt.archiveErr)
+			assert.Equal(t, tt.want, got)
+			rMock.AssertExpectations(t)
+			archiveMock.AssertExpectations(t)
+		})
+	}
+
+	t.Run("archive is not read for orders in postgres", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		expectNotErased(pgMock)
+		rMock := &mocks.OrderRedisRepoInterface{}
+		archiveMock := &mocks.ArchiveRepositoryInterface{}
+		fo := &models.FullOrder{Order: models.Order{OrderUID: "hot"}}
+		rMock.On("GetOrder", lookupCtx, "hot").Return(nil, nil).Once()
+		pgMock.On("GetFullOrderByUID", lookupCtx, "hot").Return(fo, nil).Once()
+		rMock.On("SetOrder", lookupCtx, fo, time.Hour).Return(nil).Once()
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour).WithArchive(archiveMock, true)
+		got, err := service.GetOrder(ctx, "hot")
+
+		assert.NoError(t, err)
+		assert.Equal(t, fo, got)
+		archiveMock.AssertNotCalled(t, "GetArchivedOrder", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,135 @@
+package orderService_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+func TestOrderService_GetOrder_LocalTier(t *testing.T) {
+	ctx := context.Background()
+	pgMock := &mocks.OrderPostgresRepositoryInterface{}
+	rMock := &mocks.OrderRedisRepoInterface{}
+	fo := &models.FullOrder{Order: models.Order{OrderUID: "order123"}}
+	rMock.On("GetOrder", lookupCtx, "order123").Return(fo, nil).Once()
+
+	service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour).WithLocalCache(10, time.Minute)
+	for i := 0; i < 3; i++ {
+		got, err := service.GetOrder(ctx, "order123")
+		assert.NoError(t, err)
+		assert.Equal(t, fo, got)
+	}
+	rMock.AssertNumberOfCalls(t, "GetOrder", 1)
+}
+
+func TestOrderService_GetOrder_LocalTierInvalidatedOnDelete(t *testing.T) {
+	ctx := context.Background()
+	pgMock := &mocks.OrderPostgresRepositoryInterface{}
+	rMock := &mocks.OrderRedisRepoInterface{}
+	txMock := &mocks.PgxTx{}
+	fo := &models.FullOrder{Order: models.Order{OrderUID: "order123"}}
+
+	rMock.On("GetOrder", lookupCtx, "order123").Return(fo, nil).Once()
+	pgMock.On("BeginTx", ctx).Return(txMock, nil)
+	pgMock.On("DeleteOrderTx", ctx, txMock, "order123").Return(nil)
+	pgMock.On("SaveOrderVersionsTx", ctx, txMock, mock.Anything).Return(nil)
+	txMock.On("Commit", ctx).Return(nil)
+	txMock.On("Rollback", ctx).Return(nil)
+	rMock.On("DeleteOrder", ctx, "order123").Return(nil)
+
+	service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour).WithLocalCache(10, time.Minute)
+	_, err := service.GetOrder(ctx, "order123")
+	assert.NoError(t, err)
+	assert.NoError(t, service.DeleteOrder(ctx, "order123"))
+
+	rMock.On("GetOrder", lookupCtx, "order123").Return(nil, nil).Once()
+	pgMock.On("GetFullOrderByUID", lookupCtx, "order123").Return(nil, models.ErrOrderNotFound).Once()
+	_, err = service.GetOrder(ctx, "order123")
+	assert.ErrorIs(t, err, models.ErrOrderNotFound)
+}
+
+func TestOrderService_GetOrder_CoalescesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	pgMock := &mocks.OrderPostgresRepositoryInterface{}
+	expectNotErased(pgMock)
+	rMock := &mocks.OrderRedisRepoInterface{}
+	fo := &models.FullOrder{Order: models.Order{OrderUID: "order123"}}
+
+	const callers = 10
+	release := make(chan struct{})
+	rMock.On("GetOrder", lookupCtx, "order123").Return(nil, nil).Run(func(mock.Arguments) { <-release })
+	pgMock.On("GetFullOrderByUID", lookupCtx, "order123").Return(fo, nil)
+	rMock.On("SetOrder", lookupCtx, fo, mock.AnythingOfType("time.Duration")).Return(nil)
+
+	service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+
+	var wg sync.WaitGroup
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer wg.Done()
+			got, err := service.GetOrder(ctx, "order123")
+			assert.NoError(t, err)
+			assert.Equal(t, fo, got)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	rMock.AssertNumberOfCalls(t, "GetOrder", 1)
+	pgMock.AssertNumberOfCalls(t, "GetFullOrderByUID", 1)
+}
+
+func TestOrderService_GetOrder_SharedLookupOutlivesCaller(t *testing.T) {
+	pgMock := &mocks.OrderPostgresRepositoryInterface{}
+	expectNotErased(pgMock)
+	rMock := &mocks.OrderRedisRepoInterface{}
+	fo := &models.FullOrder{Order: models.Order{OrderUID: "order123"}}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	rMock.On("GetOrder", lookupCtx, "order123").Return(nil, nil).Run(func(a mock.Arguments) {
+		close(started)
+		<-release
+		assert.NoError(t, a.Get(0).(context.Context).Err())
+	}).Once()
+	pgMock.On("GetFullOrderByUID", lookupCtx, "order123").Return(fo, nil).Once()
+	rMock.On("SetOrder", lookupCtx, fo, time.Hour).Return(nil).Once()
+
+	service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := service.GetOrder(firstCtx, "order123")
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan *models.FullOrder, 1)
+	go func() {
+		got, err := service.GetOrder(context.Background(), "order123")
+		assert.NoError(t, err)
+		second <- got
+	}()
+
+	// The first caller gives up without waiting for the lookup.
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+
+	// Let the second caller join the lookup before it finishes.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	assert.Equal(t, fo, <-second)
+	rMock.AssertExpectations(t)
+	pgMock.AssertExpectations(t)
+}
@@ -0,0 +1,56 @@
+package orderService_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+func TestOrderService_GetOrder_NegativeCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("missing order is remembered in redis", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		rMock.On("GetOrder", lookupCtx, "unknown").Return(nil, nil).Once()
+		pgMock.On("GetFullOrderByUID", lookupCtx, "unknown").Return(nil, models.ErrOrderNotFound).Once()
+		rMock.On("SetOrderNotFound", lookupCtx, "unknown", 30*time.Second).Return(nil).Once()
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour).WithNegativeCache(30 * time.Second)
+		_, err := service.GetOrder(ctx, "unknown")
+		assert.ErrorIs(t, err, models.ErrOrderNotFound)
+		rMock.AssertExpectations(t)
+		pgMock.AssertExpectations(t)
+	})
+
+	t.Run("negative entry skips postgres", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		rMock.On("GetOrder", lookupCtx, "unknown").Return(nil, models.ErrOrderNotFound).Once()
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour).WithNegativeCache(30 * time.Second)
+		_, err := service.GetOrder(ctx, "unknown")
+		assert.ErrorIs(t, err, models.ErrOrderNotFound)
+		pgMock.AssertNotCalled(t, "GetFullOrderByUID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("disabled negative cache does not write markers", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		rMock.On("GetOrder", lookupCtx, "unknown").Return(nil, nil).Once()
+		pgMock.On("GetFullOrderByUID", lookupCtx, "unknown").Return(nil, models.ErrOrderNotFound).Once()
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+		_, err := service.GetOrder(ctx, "unknown")
+		assert.ErrorIs(t, err, models.ErrOrderNotFound)
+		rMock.AssertNotCalled(t, "SetOrderNotFound", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -10,11 +10,18 @@ import (
 	"github.com/stretchr/testify/mock"
 	"log/slog"
 
+	"wbL0/internal/lib/breaker"
 	mocks "wbL0/internal/mocks"
 	"wbL0/internal/models"
 	svc "wbL0/internal/service/orderService"
 )
 
+// lookupCtx matches the context of a shared lookup, which is detached from the caller and has its own deadline.
+var lookupCtx = mock.MatchedBy(func(ctx context.Context) bool {
+	_, ok := ctx.Deadline()
+	return ok
+})
+
 func TestOrderService_GetOrder(t *testing.T) {
 	ctx := context.Background()
 
@@ -29,7 +36,7 @@ func TestOrderService_GetOrder(t *testing.T) {
 			name:     "Order found in Redis",
 			orderUID: "order123",
 			setupMocks: func(pg *mocks.OrderPostgresRepositoryInterface, r *mocks.OrderRedisRepoInterface) {
-				r.On("GetOrder", ctx, "order123").Return(&models.FullOrder{
+				r.On("GetOrder", lookupCtx, "order123").Return(&models.FullOrder{
 					Order: models.Order{OrderUID: "order123"},
 				}, nil)
 			},
@@ -40,11 +47,11 @@ func TestOrderService_GetOrder(t *testing.T) {
 			name:     "Order not in Redis, found in Postgres",
 			orderUID: "order456",
 			setupMocks: func(pg *mocks.OrderPostgresRepositoryInterface, r *mocks.OrderRedisRepoInterface) {
-				r.On("GetOrder", ctx, "order456").Return(nil, nil)
-				pg.On("GetFullOrderByUID", ctx, "order456").Return(&models.FullOrder{
+				r.On("GetOrder", lookupCtx, "order456").Return(nil, nil)
+				pg.On("GetFullOrderByUID", lookupCtx, "order456").Return(&models.FullOrder{
 					Order: models.Order{OrderUID: "order456"},
 				}, nil)
-				r.On("SetOrder", ctx, mock.Anything, mock.AnythingOfType("time.Duration")).Return(nil)
+				r.On("SetOrder", lookupCtx, mock.Anything, mock.AnythingOfType("time.Duration")).Return(nil)
 			},
 			expectedOrder: &models.FullOrder{Order: models.Order{OrderUID: "order456"}},
 			expectedError: nil,
@@ -53,8 +60,8 @@ func TestOrderService_GetOrder(t *testing.T) {
 			name:     "Order not in Redis, Postgres error",
 			orderUID: "order789",
 			setupMocks: func(pg *mocks.OrderPostgresRepositoryInterface, r *mocks.OrderRedisRepoInterface) {
-				r.On("GetOrder", ctx, "order789").Return(nil, nil)
-				pg.On("GetFullOrderByUID", ctx, "order789").Return(nil, errors.New("postgres error"))
+				r.On("GetOrder", lookupCtx, "order789").Return(nil, nil)
+				pg.On("GetFullOrderByUID", lookupCtx, "order789").Return(nil, errors.New("postgres error"))
 			},
 			expectedOrder: nil,
 			expectedError: errors.New("postgres error"),
@@ -63,20 +70,34 @@ func TestOrderService_GetOrder(t *testing.T) {
 			name:     "Redis error, fallback to Postgres",
 			orderUID: "order101",
 			setupMocks: func(pg *mocks.OrderPostgresRepositoryInterface, r *mocks.OrderRedisRepoInterface) {
-				r.On("GetOrder", ctx, "order101").Return(nil, errors.New("redis error"))
-				pg.On("GetFullOrderByUID", ctx, "order101").Return(&models.FullOrder{
+				r.On("GetOrder", lookupCtx, "order101").Return(nil, errors.New("redis error"))
+				pg.On("GetFullOrderByUID", lookupCtx, "order101").Return(&models.FullOrder{
 					Order: models.Order{OrderUID: "order101"},
 				}, nil)
-				r.On("SetOrder", ctx, mock.Anything, mock.AnythingOfType("time.Duration")).Return(nil)
+				r.On("SetOrder", lookupCtx, mock.Anything, mock.AnythingOfType("time.Duration")).Return(nil)
 			},
 			expectedOrder: &models.FullOrder{Order: models.Order{OrderUID: "order101"}},
 			expectedError: nil,
 		},
+		{
+			name:     "Redis breaker open, served from Postgres",
+			orderUID: "order102",
+			setupMocks: func(pg *mocks.OrderPostgresRepositoryInterface, r *mocks.OrderRedisRepoInterface) {
+				r.On("GetOrder", lookupCtx, "order102").Return(nil, breaker.ErrOpen)
+				pg.On("GetFullOrderByUID", lookupCtx, "order102").Return(&models.FullOrder{
+					Order: models.Order{OrderUID: "order102"},
+				}, nil)
+				r.On("SetOrder", lookupCtx, mock.Anything, mock.AnythingOfType("time.Duration")).Return(breaker.ErrOpen)
+			},
+			expectedOrder: &models.FullOrder{Order: models.Order{OrderUID: "order102"}},
+			expectedError: nil,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pgMock := &mocks.OrderPostgresRepositoryInterface{}
+			expectNotErased(pgMock)
 			rMock := &mocks.OrderRedisRepoInterface{}
 			tt.setupMocks(pgMock, rMock)
 
@@ -0,0 +1,90 @@
+package orderService_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+func TestOrderService_ListOrders(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	summaries := []models.OrderSummary{
+		{OrderUID: "a", DateCreated: now},
+		{OrderUID: "b", DateCreated: now.Add(-time.Minute)},
+		{OrderUID: "c", DateCreated: now.Add(-2 * time.Minute)},
+	}
+
+	tests := []struct {
+		name       string
+		limit      int
+		repoResult []models.OrderSummary
+		repoErr    error
+		wantUIDs   []string
+		wantCursor *models.OrderCursor
+		wantErr    bool
+	}{
+		{
+			name:       "more rows than limit produce next cursor",
+			limit:      2,
+			repoResult: summaries,
+			wantUIDs:   []string{"a", "b"},
+			wantCursor: &models.OrderCursor{DateCreated: summaries[1].DateCreated, OrderUID: "b"},
+		},
+		{
+			name:       "last page has no cursor",
+			limit:      5,
+			repoResult: summaries,
+			wantUIDs:   []string{"a", "b", "c"},
+		},
+		{
+			name:    "repository error",
+			limit:   2,
+			repoErr: errors.New("postgres error"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pgMock := &mocks.OrderPostgresRepositoryInterface{}
+			rMock := &mocks.OrderRedisRepoInterface{}
+			pgMock.On("ListOrders", ctx, mock.MatchedBy(func(f models.OrderFilter) bool {
+				return f.Limit == tt.limit+1
+			})).Return(tt.repoResult, tt.repoErr)
+
+			service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+			page, err := service.ListOrders(ctx, models.OrderFilter{Limit: tt.limit})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			var uids []string
+			for _, o := range page.Orders {
+				uids = append(uids, o.OrderUID)
+			}
+			assert.Equal(t, tt.wantUIDs, uids)
+
+			if tt.wantCursor == nil {
+				assert.Empty(t, page.NextCursor)
+			} else {
+				cursor, err := models.DecodeOrderCursor(page.NextCursor)
+				assert.NoError(t, err)
+				assert.True(t, tt.wantCursor.DateCreated.Equal(cursor.DateCreated))
+				assert.Equal(t, tt.wantCursor.OrderUID, cursor.OrderUID)
+			}
+			pgMock.AssertExpectations(t)
+		})
+	}
+}
@@ -2,113 +2,223 @@ package orderService
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 	"log/slog"
 	"time"
+	"wbL0/internal/lib/breaker"
+	"wbL0/internal/lib/lru"
+	"wbL0/internal/metrics"
 	"wbL0/internal/models"
 	"wbL0/internal/repository/postgres/orderRepoPostgres"
 	"wbL0/internal/repository/redis/orderRepoRedis"
+	"wbL0/internal/tracing"
 )
 
+// lookupTimeout bounds a shared order lookup, which outlives the caller that started it.
+const lookupTimeout = 5 * time.Second
+
 //go:generate mockery --name=OrderServiceInterface --dir=. --output=../../mocks --outpkg=mocks --case=underscore
 type OrderServiceInterface interface {
 	GetOrder(ctx context.Context, orderUID string) (*models.FullOrder, error)
 	ProcessAndCache(ctx context.Context, fo *models.FullOrder) error
-	RestoreCacheFromDB(ctx context.Context) error
+	ProcessAndCacheBatch(ctx context.Context, fos []*models.FullOrder) error
+	RestoreCacheFromDB(ctx context.Context, opts WarmupOptions) error
+	ListOrders(ctx context.Context, filter models.OrderFilter) (*models.OrderPage, error)
+	CreateOrder(ctx context.Context, fo *models.FullOrder) error
+	UpdateOrder(ctx context.Context, fo *models.FullOrder) error
+	DeleteOrder(ctx context.Context, orderUID string) error
+	ChangeOrderStatus(ctx context.Context, orderUID string, to models.OrderStatus, reason, source string) (*models.StatusChange, error)
+	GetOrderStatusHistory(ctx context.Context, orderUID string) (*models.OrderStatusHistory, error)
+	ListOrderVersions(ctx context.Context, orderUID string) ([]models.OrderVersion, error)
+	GetOrderVersion(ctx context.Context, orderUID string, version int) (*models.OrderVersion, error)
+	DiffOrderVersions(ctx context.Context, orderUID string, from, to int) (*models.OrderDiff, error)
+	ExportCustomerData(ctx context.Context, customerID string) (*models.CustomerExport, error)
+	EraseCustomerData(ctx context.Context, customerID, reason string) (*models.CustomerErasure, error)
 }
 
 type OrderService struct {
-	repo      orderRepoPostgres.OrderPostgresRepositoryInterface
-	redisRepo orderRepoRedis.OrderRedisRepoInterface
-	log       *slog.Logger
-	ttl       time.Duration
+	repo        orderRepoPostgres.OrderPostgresRepositoryInterface
+	redisRepo   orderRepoRedis.OrderRedisRepoInterface
+	log         *slog.Logger
+	ttl         time.Duration
+	local       *lru.Cache[string, *models.FullOrder]
+	localTTL    time.Duration
+	notFoundTTL time.Duration
+	outbox      bool
+	archive     orderRepoPostgres.ArchiveRepositoryInterface
+	fallback    bool
+	lookups     singleflight.Group
 }
 
 func NewOrderService(repo orderRepoPostgres.OrderPostgresRepositoryInterface, redisRepo orderRepoRedis.OrderRedisRepoInterface, log *slog.Logger, ttl time.Duration) *OrderService {
 	return &OrderService{repo: repo, redisRepo: redisRepo, log: log, ttl: ttl}
 }
 
-func (s *OrderService) GetOrder(ctx context.Context, orderUID string) (*models.FullOrder, error) {
+// WithLocalCache enables an in-process LRU tier in front of Redis holding up to size orders for ttl.
+// Other instances do not invalidate it, so ttl bounds how stale an order updated elsewhere can be.
+// Erased orders are dropped from it by RunEvictions.
+func (s *OrderService) WithLocalCache(size int, ttl time.Duration) *OrderService {
+	if size > 0 {
+		s.local = lru.New[string, *models.FullOrder](size, ttl)
+		s.localTTL = ttl
+	}
+	return s
+}
+
+// WithNegativeCache makes GetOrder remember unknown UIDs in Redis for ttl, so repeated lookups of
+// missing orders do not reach Postgres. Storing the order replaces the entry.
+func (s *OrderService) WithNegativeCache(ttl time.Duration) *OrderService {
+	s.notFoundTTL = ttl
+	return s
+}
+
+// WithOutbox makes every stored order also write an order.stored event to the outbox in the same transaction.
+func (s *OrderService) WithOutbox() *OrderService {
+	s.outbox = true
+	return s
+}
+
+// WithArchive makes ExportCustomerData include orders moved to the archive by the retention job and,
+// with fallback, GetOrder read the archive for orders missing in the hot tables.
+func (s *OrderService) WithArchive(archive orderRepoPostgres.ArchiveRepositoryInterface, fallback bool) *OrderService {
+	s.archive = archive
+	s.fallback = fallback
+	return s
+}
+
+// GetOrder looks the order up in the local tier, Redis, Postgres and, with archive fallback, the archive in that order.
+// Concurrent misses for the same UID share a single Redis/Postgres lookup.
+func (s *OrderService) GetOrder(ctx context.Context, orderUID string) (_ *models.FullOrder, err error) {
+	const op = "OrderService.GetOrder"
+	ctx, span := tracing.Start(ctx, op, trace.WithAttributes(tracing.OrderUID(orderUID)))
+	defer func() { tracing.End(span, err, models.ErrOrderNotFound) }()
+
+	if s.local != nil {
+		if fo, ok := s.local.Get(orderUID); ok {
+			metrics.CacheRequests.WithLabelValues(metrics.TierLocal, metrics.ResultHit).Inc()
+			span.SetAttributes(attribute.String("cache.tier", metrics.TierLocal))
+			return fo, nil
+		}
+		metrics.CacheRequests.WithLabelValues(metrics.TierLocal, metrics.ResultMiss).Inc()
+	}
+
+	// The lookup is shared by every concurrent caller of the order, so it must not be canceled with the caller
+	// that started it; each caller stops waiting when its own context is done.
+	lookup := s.lookups.DoChan(orderUID, func() (interface{}, error) {
+		lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lookupTimeout)
+		defer cancel()
+		return s.loadOrder(lookupCtx, orderUID)
+	})
+	select {
+	case res := <-lookup:
+		// A shared lookup ran in the span of the first caller.
+		span.SetAttributes(attribute.Bool("lookup.shared", res.Shared))
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*models.FullOrder), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *OrderService) loadOrder(ctx context.Context, orderUID string) (*models.FullOrder, error) {
 	const op = "OrderService.GetOrder"
 
 	fo, err := s.redisRepo.GetOrder(ctx, orderUID)
-	if err != nil {
-		s.log.Warn("failed to get order from redis", "op", op, "orderUID", orderUID, "err", err)
+	if errors.Is(err, models.ErrOrderNotFound) {
+		metrics.CacheRequests.WithLabelValues(metrics.TierRedis, metrics.ResultNegativeHit).Inc()
+		return nil, err
+	}
+	if err != nil && !errors.Is(err, breaker.ErrOpen) {
+		s.log.WarnContext(ctx, "failed to get order from redis", "op", op, "orderUID", orderUID, "err", err)
 	}
 	if fo != nil {
+		metrics.CacheRequests.WithLabelValues(metrics.TierRedis, metrics.ResultHit).Inc()
+		if s.local != nil {
+			s.local.Set(orderUID, fo)
+		}
 		return fo, nil
 	}
+	metrics.CacheRequests.WithLabelValues(metrics.TierRedis, metrics.ResultMiss).Inc()
 
 	fo, err = s.repo.GetFullOrderByUID(ctx, orderUID)
+	if errors.Is(err, models.ErrOrderNotFound) && s.fallback {
+		fo, err = s.loadArchivedOrder(ctx, orderUID)
+	}
+	if errors.Is(err, models.ErrOrderNotFound) {
+		if s.notFoundTTL > 0 {
+			if err := s.redisRepo.SetOrderNotFound(ctx, orderUID, s.notFoundTTL); err != nil {
+				s.log.WarnContext(ctx, "failed to cache missing order in redis", "op", op, "orderUID", orderUID, "err", err)
+			}
+		}
+		return nil, err
+	}
 	if err != nil {
-		s.log.Error("failed to get order from postgres", "op", op, "orderUID", orderUID, "err", err)
+		s.log.ErrorContext(ctx, "failed to get order from postgres", "op", op, "orderUID", orderUID, "err", err)
 		return nil, err
 	}
 
-	if err := s.redisRepo.SetOrder(ctx, fo, s.ttl); err != nil {
-		s.log.Warn("failed to cache order in redis", "op", op, "orderUID", orderUID, "err", err)
+	if err := s.redisRepo.SetOrder(ctx, fo, s.ttl); err != nil && !errors.Is(err, breaker.ErrOpen) {
+		s.log.WarnContext(ctx, "failed to cache order in redis", "op", op, "orderUID", orderUID, "err", err)
 	}
+	if s.local != nil {
+		s.local.Set(orderUID, fo)
+	}
+	s.dropErased(ctx, []*models.FullOrder{fo})
 
 	return fo, nil
 }
 
-func (s *OrderService) ProcessAndCache(ctx context.Context, fo *models.FullOrder) error {
-	const op = "OrderService.ProcessAndCache"
-
-	if fo.Order.OrderUID == "" {
-		return fmt.Errorf("order_uid is empty")
-	}
-
-	tx, err := s.repo.BeginTx(ctx)
-	if err != nil {
-		s.log.Error("failed to begin transaction", "op", op, "err", err)
-		return err
-	}
-	defer tx.Rollback(ctx)
+func (s *OrderService) loadArchivedOrder(ctx context.Context, orderUID string) (*models.FullOrder, error) {
+	const op = "OrderService.GetOrder"
 
-	if err := s.repo.SaveOrderDataTx(ctx, tx, &fo.Order); err != nil {
-		s.log.Error("failed to save order data", "op", op, "err", err)
-		return err
-	}
-	if err := s.repo.SaveDeliveryDataTx(ctx, tx, &fo.Delivery); err != nil {
-		s.log.Error("failed to save delivery data", "op", op, "err", err)
-		return err
-	}
-	if err := s.repo.SavePaymentDataTx(ctx, tx, &fo.Payment); err != nil {
-		s.log.Error("failed to save payment data", "op", op, "err", err)
-		return err
-	}
-	for i := range fo.Items {
-		if err := s.repo.SaveItemsDataTx(ctx, tx, &fo.Items[i]); err != nil {
-			s.log.Error("failed to save item data", "op", op, "item_index", i, "err", err)
-			return err
-		}
-	}
-	if err := tx.Commit(ctx); err != nil {
-		s.log.Error("failed to commit transaction", "op", op, "err", err)
-		return err
+	fo, err := s.archive.GetArchivedOrder(ctx, orderUID)
+	switch {
+	case err == nil:
+		metrics.ArchiveReads.WithLabelValues(metrics.ResultHit).Inc()
+	case errors.Is(err, models.ErrOrderNotFound):
+		metrics.ArchiveReads.WithLabelValues(metrics.ResultMiss).Inc()
+	default:
+		s.log.ErrorContext(ctx, "failed to get order from archive", "op", op, "orderUID", orderUID, "err", err)
 	}
+	return fo, err
+}
 
-	if err := s.redisRepo.SetOrder(ctx, fo, s.ttl); err != nil {
-		s.log.Warn("failed to cache order in redis", "op", op, "err", err)
-	}
+func (s *OrderService) ProcessAndCache(ctx context.Context, fo *models.FullOrder) (err error) {
+	const op = "OrderService.ProcessAndCache"
+	ctx, span := tracing.Start(ctx, op, trace.WithAttributes(tracing.OrderUID(fo.Order.OrderUID)))
+	defer func() { tracing.End(span, err) }()
 
-	return nil
+	_, err = s.saveOrder(ctx, fo, nil)
+	return err
 }
 
-func (s *OrderService) RestoreCacheFromDB(ctx context.Context) error {
-	const op = "OrderService.RestoreCacheFromDB"
+// ListOrders returns one page of orders. One extra row is requested to find out whether a next page exists.
+func (s *OrderService) ListOrders(ctx context.Context, filter models.OrderFilter) (*models.OrderPage, error) {
+	const op = "OrderService.ListOrders"
 
-	orders, err := s.repo.GetAllFullOrders(ctx)
-	if err != nil {
-		s.log.Error("failed to get all orders from postgres", "op", op, "err", err)
-		return err
+	limit := filter.Limit
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: limit must be positive", models.ErrInvalidInput)
 	}
+	filter.Limit = limit + 1
 
-	if err := s.redisRepo.RestoreOrders(ctx, orders, s.ttl); err != nil {
-		s.log.Warn("failed to restore orders to redis", "op", op, "err", err)
+	orders, err := s.repo.ListOrders(ctx, filter)
+	if err != nil {
+		s.log.Error("failed to list orders from postgres", "op", op, "err", err)
+		return nil, err
 	}
 
-	s.log.Info("cache restored", "op", op, "count", len(orders))
-	return nil
+	page := &models.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = models.OrderCursor{DateCreated: last.DateCreated, OrderUID: last.OrderUID}.Encode()
+	}
+	return page, nil
 }
@@ -0,0 +1,94 @@
+package orderService
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+	"wbL0/internal/metrics"
+	"wbL0/internal/models"
+	"wbL0/internal/tracing"
+	"wbL0/internal/validation"
+)
+
+// ProcessAndCacheBatch stores several orders in one transaction. Any invalid order or database error fails
+// the whole batch and nothing is written, so the caller can fall back to ProcessAndCache per order.
+func (s *OrderService) ProcessAndCacheBatch(ctx context.Context, fos []*models.FullOrder) (err error) {
+	const op = "OrderService.ProcessAndCacheBatch"
+	ctx, span := tracing.Start(ctx, op, trace.WithAttributes(attribute.Int("orders.count", len(fos))))
+	defer func() { tracing.End(span, err) }()
+
+	if len(fos) == 0 {
+		return nil
+	}
+
+	checksums := make([]string, len(fos))
+	for i, fo := range fos {
+		fo.FillOrderUID()
+		validateStart := time.Now()
+		err := validation.ValidateFullOrder(fo)
+		metrics.ObserveStage(metrics.StageValidate, validateStart)
+		if err != nil {
+			s.log.WarnContext(ctx, "order validation failed", "op", op, "orderUID", fo.Order.OrderUID, "err", err)
+			return err
+		}
+		checksum, err := fo.Checksum()
+		if err != nil {
+			s.log.ErrorContext(ctx, "failed to calculate order checksum", "op", op, "err", err)
+			return err
+		}
+		checksums[i] = checksum
+	}
+
+	txStart := time.Now()
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to begin transaction", "op", op, "err", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	outcomes, err := s.repo.SaveOrdersBatchTx(ctx, tx, fos, checksums)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to save orders batch", "op", op, "err", err)
+		return err
+	}
+	if err := s.saveVersions(ctx, tx, fos, outcomes); err != nil {
+		s.log.ErrorContext(ctx, "failed to save order versions", "op", op, "err", err)
+		return err
+	}
+	if err := s.saveOutboxEvents(ctx, tx, fos, outcomes); err != nil {
+		s.log.ErrorContext(ctx, "failed to save outbox events", "op", op, "err", err)
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		s.log.ErrorContext(ctx, "failed to commit transaction", "op", op, "err", err)
+		return err
+	}
+	metrics.ObserveStage(metrics.StageDBTx, txStart)
+	stored := time.Now()
+
+	changed := make([]*models.FullOrder, 0, len(fos))
+	for i, outcome := range outcomes {
+		metrics.OrdersSaved.WithLabelValues(string(outcome)).Inc()
+		if outcome == models.OutcomeInserted {
+			metrics.ObserveIngest(fos[i].Order.DateCreated, stored)
+		}
+		if outcome == models.OutcomeUnchanged {
+			continue
+		}
+		changed = append(changed, fos[i])
+		if s.local != nil {
+			s.local.Delete(fos[i].Order.OrderUID)
+		}
+	}
+	s.log.InfoContext(ctx, "orders batch stored", "op", op, "count", len(fos), "changed", len(changed))
+
+	cacheStart := time.Now()
+	if err := s.redisRepo.SetOrders(ctx, changed, s.ttl); err != nil {
+		s.log.WarnContext(ctx, "failed to cache orders batch in redis", "op", op, "err", err)
+	}
+	s.dropErased(ctx, changed)
+	metrics.ObserveStage(metrics.StageCacheWrite, cacheStart)
+	return nil
+}
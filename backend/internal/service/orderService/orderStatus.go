@@ -0,0 +1,68 @@
+package orderService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wbL0/internal/models"
+)
+
+// ChangeOrderStatus moves the order to the given status and records the change in the status history and
+// as an order version.
+// Setting the current status again is a no-op, so redelivered events are harmless; a transition missing
+// from the transition table fails with models.ErrInvalidTransition.
+func (s *OrderService) ChangeOrderStatus(ctx context.Context, orderUID string, to models.OrderStatus, reason, source string) (*models.StatusChange, error) {
+	const op = "OrderService.ChangeOrderStatus"
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.log.Error("failed to begin transaction", "op", op, "err", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	from, err := s.repo.GetOrderStatusForUpdateTx(ctx, tx, orderUID)
+	if err != nil {
+		if !errors.Is(err, models.ErrOrderNotFound) {
+			s.log.Error("failed to get order status", "op", op, "orderUID", orderUID, "err", err)
+		}
+		return nil, err
+	}
+
+	change := &models.StatusChange{
+		OrderUID:  orderUID,
+		From:      from,
+		To:        to,
+		Reason:    reason,
+		Source:    source,
+		ChangedAt: time.Now().UTC(),
+	}
+	if from == to {
+		s.log.Info("order already has the status, skipping", "op", op, "orderUID", orderUID, "status", to)
+		return change, nil
+	}
+	if !from.CanTransitionTo(to) {
+		s.log.Warn("illegal status transition", "op", op, "orderUID", orderUID, "from", from, "to", to)
+		return nil, fmt.Errorf("%w: %s -> %s", models.ErrInvalidTransition, from, to)
+	}
+
+	if err := s.repo.SaveStatusChangeTx(ctx, tx, change); err != nil {
+		s.log.Error("failed to save status change", "op", op, "orderUID", orderUID, "err", err)
+		return nil, err
+	}
+	version := models.OrderVersion{OrderUID: orderUID, Operation: models.VersionStatusChanged, Source: source}
+	if err := s.repo.SaveOrderVersionsTx(ctx, tx, []models.OrderVersion{version}); err != nil {
+		s.log.Error("failed to save order version", "op", op, "orderUID", orderUID, "err", err)
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		s.log.Error("failed to commit transaction", "op", op, "err", err)
+		return nil, err
+	}
+	return change, nil
+}
+
+func (s *OrderService) GetOrderStatusHistory(ctx context.Context, orderUID string) (*models.OrderStatusHistory, error) {
+	return s.repo.GetOrderStatusHistory(ctx, orderUID)
+}
@@ -0,0 +1,60 @@
+package orderService
+
+import (
+	"context"
+	"errors"
+	"wbL0/internal/audit"
+	"wbL0/internal/models"
+)
+
+// ListOrderVersions returns the audit trail of the order without snapshots. The order must either exist
+// or have versions, so a deleted order still shows its history.
+func (s *OrderService) ListOrderVersions(ctx context.Context, orderUID string) ([]models.OrderVersion, error) {
+	versions, err := s.repo.ListOrderVersions(ctx, orderUID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		if _, err := s.repo.GetOrderInfoByUid(ctx, orderUID); err != nil {
+			return nil, err
+		}
+	}
+	return versions, nil
+}
+
+func (s *OrderService) GetOrderVersion(ctx context.Context, orderUID string, version int) (*models.OrderVersion, error) {
+	return s.repo.GetOrderVersion(ctx, orderUID, version)
+}
+
+// DiffOrderVersions compares the order as it was after version from with the order after version to.
+// The status is not part of the snapshots and is compared as the "status" path.
+func (s *OrderService) DiffOrderVersions(ctx context.Context, orderUID string, from, to int) (*models.OrderDiff, error) {
+	const op = "OrderService.DiffOrderVersions"
+
+	a, err := s.repo.GetOrderVersion(ctx, orderUID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.repo.GetOrderVersion(ctx, orderUID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := audit.Diff(a.Current, b.Current)
+	if err != nil {
+		s.log.Error("failed to diff order versions", "op", op, "orderUID", orderUID, "err", err)
+		return nil, errors.Join(models.ErrInternal, err)
+	}
+	if a.Status != b.Status {
+		changes = append([]models.FieldChange{{Path: "status", Old: statusValue(a.Status), New: statusValue(b.Status)}}, changes...)
+	}
+	return &models.OrderDiff{OrderUID: orderUID, From: from, To: to, Changes: changes}, nil
+}
+
+// statusValue reports a missing status as null rather than an empty string.
+func statusValue(status models.OrderStatus) any {
+	if status == "" {
+		return nil
+	}
+	return string(status)
+}
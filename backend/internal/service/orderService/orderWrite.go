@@ -0,0 +1,207 @@
+package orderService
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wbL0/internal/audit"
+	"wbL0/internal/metrics"
+	"wbL0/internal/models"
+	"wbL0/internal/repository/postgres/orderRepoPostgres"
+	"wbL0/internal/validation"
+)
+
+// saveOrder validates the order and stores it in one transaction through the upsert path used by Kafka ingestion.
+// accept, when set, is called with the save outcome and may reject it, which rolls the transaction back.
+func (s *OrderService) saveOrder(ctx context.Context, fo *models.FullOrder, accept func(models.SaveOutcome) error) (models.SaveOutcome, error) {
+	const op = "OrderService.saveOrder"
+
+	fo.FillOrderUID()
+	validateStart := time.Now()
+	err := validation.ValidateFullOrder(fo)
+	metrics.ObserveStage(metrics.StageValidate, validateStart)
+	if err != nil {
+		s.log.WarnContext(ctx, "order validation failed", "op", op, "orderUID", fo.Order.OrderUID, "err", err)
+		return "", err
+	}
+
+	checksum, err := fo.Checksum()
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to calculate order checksum", "op", op, "err", err)
+		return "", err
+	}
+
+	txStart := time.Now()
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to begin transaction", "op", op, "err", err)
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	outcome, err := s.repo.SaveOrderDataTx(ctx, tx, &fo.Order, checksum)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to save order data", "op", op, "err", err)
+		return "", err
+	}
+	if accept != nil {
+		if err := accept(outcome); err != nil {
+			return outcome, err
+		}
+	}
+	if outcome == models.OutcomeUnchanged {
+		metrics.ObserveStage(metrics.StageDBTx, txStart)
+		metrics.OrdersSaved.WithLabelValues(string(outcome)).Inc()
+		s.log.InfoContext(ctx, "order already stored, skipping", "op", op, "orderUID", fo.Order.OrderUID)
+		return outcome, nil
+	}
+	if outcome == models.OutcomeUpdated {
+		if err := s.repo.DeleteItemsTx(ctx, tx, fo.Order.OrderUID); err != nil {
+			s.log.ErrorContext(ctx, "failed to delete previous items", "op", op, "err", err)
+			return "", err
+		}
+	}
+
+	if err := s.repo.SaveDeliveryDataTx(ctx, tx, &fo.Delivery); err != nil {
+		s.log.ErrorContext(ctx, "failed to save delivery data", "op", op, "err", err)
+		return "", err
+	}
+	if err := s.repo.SavePaymentDataTx(ctx, tx, &fo.Payment); err != nil {
+		s.log.ErrorContext(ctx, "failed to save payment data", "op", op, "err", err)
+		return "", err
+	}
+	for i := range fo.Items {
+		if err := s.repo.SaveItemsDataTx(ctx, tx, &fo.Items[i]); err != nil {
+			s.log.ErrorContext(ctx, "failed to save item data", "op", op, "item_index", i, "err", err)
+			return "", err
+		}
+	}
+	if err := s.saveVersions(ctx, tx, []*models.FullOrder{fo}, []models.SaveOutcome{outcome}); err != nil {
+		s.log.ErrorContext(ctx, "failed to save order version", "op", op, "err", err)
+		return "", err
+	}
+	if err := s.saveOutboxEvents(ctx, tx, []*models.FullOrder{fo}, []models.SaveOutcome{outcome}); err != nil {
+		s.log.ErrorContext(ctx, "failed to save outbox event", "op", op, "err", err)
+		return "", err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		s.log.ErrorContext(ctx, "failed to commit transaction", "op", op, "err", err)
+		return "", err
+	}
+	metrics.ObserveStage(metrics.StageDBTx, txStart)
+	if outcome == models.OutcomeInserted {
+		metrics.ObserveIngest(fo.Order.DateCreated, time.Now())
+	}
+	metrics.OrdersSaved.WithLabelValues(string(outcome)).Inc()
+	s.log.InfoContext(ctx, "order stored", "op", op, "orderUID", fo.Order.OrderUID, "outcome", outcome)
+
+	if s.local != nil {
+		s.local.Delete(fo.Order.OrderUID)
+	}
+
+	cacheStart := time.Now()
+	if err := s.redisRepo.SetOrder(ctx, fo, s.ttl); err != nil {
+		s.log.WarnContext(ctx, "failed to cache order in redis", "op", op, "err", err)
+	}
+	s.dropErased(ctx, []*models.FullOrder{fo})
+	metrics.ObserveStage(metrics.StageCacheWrite, cacheStart)
+
+	return outcome, nil
+}
+
+// saveVersions appends a version with the new snapshot to the audit trail of every order that changed.
+func (s *OrderService) saveVersions(ctx context.Context, tx orderRepoPostgres.PgxTx, fos []*models.FullOrder, outcomes []models.SaveOutcome) error {
+	versions := make([]models.OrderVersion, 0, len(fos))
+	for i, fo := range fos {
+		if outcomes[i] == models.OutcomeUnchanged {
+			continue
+		}
+		version, err := models.NewOrderVersion(fo.Order.OrderUID, string(outcomes[i]), audit.SourceFor(ctx, fo.Order.OrderUID), fo)
+		if err != nil {
+			return err
+		}
+		versions = append(versions, version)
+	}
+	return s.repo.SaveOrderVersionsTx(ctx, tx, versions)
+}
+
+// saveOutboxEvents writes an order.stored event for every order that changed, when the outbox is enabled.
+func (s *OrderService) saveOutboxEvents(ctx context.Context, tx orderRepoPostgres.PgxTx, fos []*models.FullOrder, outcomes []models.SaveOutcome) error {
+	if !s.outbox {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	events := make([]models.OutboxEvent, 0, len(fos))
+	for i, fo := range fos {
+		if outcomes[i] == models.OutcomeUnchanged {
+			continue
+		}
+		event, err := models.NewOrderStoredEvent(fo, outcomes[i], now)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+	}
+	return s.repo.SaveOutboxEventsTx(ctx, tx, events)
+}
+
+// CreateOrder stores a new order. It fails with models.ErrOrderExists if the order UID is already taken.
+func (s *OrderService) CreateOrder(ctx context.Context, fo *models.FullOrder) error {
+	_, err := s.saveOrder(ctx, fo, func(outcome models.SaveOutcome) error {
+		if outcome != models.OutcomeInserted {
+			return models.ErrOrderExists
+		}
+		return nil
+	})
+	return err
+}
+
+// UpdateOrder replaces an existing order. It fails with models.ErrOrderNotFound if there is nothing to update.
+func (s *OrderService) UpdateOrder(ctx context.Context, fo *models.FullOrder) error {
+	_, err := s.saveOrder(ctx, fo, func(outcome models.SaveOutcome) error {
+		if outcome == models.OutcomeInserted {
+			return models.ErrOrderNotFound
+		}
+		return nil
+	})
+	return err
+}
+
+// DeleteOrder removes the order with its delivery, payment and items and evicts it from the cache.
+func (s *OrderService) DeleteOrder(ctx context.Context, orderUID string) error {
+	const op = "OrderService.DeleteOrder"
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.log.Error("failed to begin transaction", "op", op, "err", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.repo.DeleteOrderTx(ctx, tx, orderUID); err != nil {
+		if !errors.Is(err, models.ErrOrderNotFound) {
+			s.log.Error("failed to delete order", "op", op, "orderUID", orderUID, "err", err)
+		}
+		return err
+	}
+	version, _ := models.NewOrderVersion(orderUID, models.VersionDeleted, audit.SourceFor(ctx, orderUID), nil)
+	if err := s.repo.SaveOrderVersionsTx(ctx, tx, []models.OrderVersion{version}); err != nil {
+		s.log.Error("failed to save order version", "op", op, "orderUID", orderUID, "err", err)
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		s.log.Error("failed to commit transaction", "op", op, "err", err)
+		return err
+	}
+	s.log.Info("order deleted", "op", op, "orderUID", orderUID)
+
+	if s.local != nil {
+		s.local.Delete(orderUID)
+	}
+
+	if err := s.redisRepo.DeleteOrder(ctx, orderUID); err != nil {
+		s.log.Warn("failed to evict order from redis", "op", op, "orderUID", orderUID, "err", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package orderService_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+func TestOrderService_ProcessAndCacheBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores batch and caches changed orders", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		expectNotErased(pgMock)
+		rMock := &mocks.OrderRedisRepoInterface{}
+		txMock := &mocks.PgxTx{}
+		a, b := validFullOrder("a"), validFullOrder("b")
+
+		pgMock.On("BeginTx", ctx).Return(txMock, nil)
+		pgMock.On("SaveOrdersBatchTx", ctx, txMock, []*models.FullOrder{a, b}, mock.MatchedBy(func(c []string) bool { return len(c) == 2 })).
+			Return([]models.SaveOutcome{models.OutcomeInserted, models.OutcomeUnchanged}, nil)
+		pgMock.On("SaveOrderVersionsTx", ctx, txMock, mock.MatchedBy(func(v []models.OrderVersion) bool {
+			return len(v) == 1 && v[0].OrderUID == "a"
+		})).Return(nil)
+		txMock.On("Commit", ctx).Return(nil)
+		txMock.On("Rollback", ctx).Return(nil)
+		rMock.On("SetOrders", ctx, []*models.FullOrder{a}, time.Hour).Return(nil)
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+		assert.NoError(t, service.ProcessAndCacheBatch(ctx, []*models.FullOrder{a, b}))
+		pgMock.AssertExpectations(t)
+		rMock.AssertExpectations(t)
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("invalid order fails the batch before writing", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		bad := validFullOrder("bad")
+		bad.Delivery.Email = "not-an-email"
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+		err := service.ProcessAndCacheBatch(ctx, []*models.FullOrder{validFullOrder("a"), bad})
+		assert.ErrorIs(t, err, models.ErrInvalidInput)
+		pgMock.AssertNotCalled(t, "BeginTx", mock.Anything)
+	})
+
+	t.Run("repository error is returned without commit", func(t *testing.T) {
+		pgMock := &mocks.OrderPostgresRepositoryInterface{}
+		rMock := &mocks.OrderRedisRepoInterface{}
+		txMock := &mocks.PgxTx{}
+
+		pgMock.On("BeginTx", ctx).Return(txMock, nil)
+		pgMock.On("SaveOrdersBatchTx", ctx, txMock, mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+		txMock.On("Rollback", ctx).Return(nil)
+
+		service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+		err := service.ProcessAndCacheBatch(ctx, []*models.FullOrder{validFullOrder("a")})
+		assert.Error(t, err)
+		txMock.AssertNotCalled(t, "Commit", mock.Anything)
+		rMock.AssertNotCalled(t, "SetOrders", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
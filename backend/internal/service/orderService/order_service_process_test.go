@@ -15,6 +15,48 @@ import (
 	svc "wbL0/internal/service/orderService"
 )
 
+func validFullOrder(uid string) *models.FullOrder {
+	return &models.FullOrder{
+		Order: models.Order{
+			OrderUID:        uid,
+			TrackNumber:     "WBILMTESTTRACK",
+			Entry:           "WBIL",
+			Locale:          "en",
+			CustomerID:      "test",
+			DeliveryService: "meest",
+			DateCreated:     time.Now(),
+		},
+		Delivery: models.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     2639809,
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Email:   "test@gmail.com",
+		},
+		Payment: models.Payment{
+			Transaction:  uid,
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []models.Item{{
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			Rid:         "ab4219087a764ae0btest",
+			Name:        "Mascaras",
+			Sale:        30,
+			TotalPrice:  317,
+			NmID:        2389212,
+		}},
+	}
+}
+
 func TestOrderService_ProcessAndCache(t *testing.T) {
 	ctx := context.Background()
 	logger := slog.Default()
@@ -31,25 +73,33 @@ func TestOrderService_ProcessAndCache(t *testing.T) {
 			setupMocks: func(pg *mocks.OrderPostgresRepositoryInterface, r *mocks.OrderRedisRepoInterface) {
 				tx := &mocks.PgxTx{}
 				pg.On("BeginTx", mock.Anything).Return(tx, nil)
-				pg.On("SaveOrderDataTx", mock.Anything, tx, mock.Anything).Return(nil)
+				pg.On("SaveOrderDataTx", mock.Anything, tx, mock.Anything, mock.Anything).Return(models.OutcomeInserted, nil)
 				pg.On("SaveDeliveryDataTx", mock.Anything, tx, mock.Anything).Return(nil)
 				pg.On("SavePaymentDataTx", mock.Anything, tx, mock.Anything).Return(nil)
 				pg.On("SaveItemsDataTx", mock.Anything, tx, mock.Anything).Return(nil)
+				pg.On("SaveOrderVersionsTx", mock.Anything, tx, mock.Anything).Return(nil)
 				tx.On("Commit", mock.Anything).Return(nil)
 				tx.On("Rollback", mock.Anything).Return(nil)
 
 				r.On("SetOrder", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			},
-			input:           &models.FullOrder{Order: models.Order{OrderUID: "ok1"}, Items: []models.Item{{}}},
+			input:           validFullOrder("ok1"),
 			expectErr:       false,
 			expectRedisCall: true,
 		},
+		{
+			name:            "invalid order is rejected before touching the database",
+			setupMocks:      func(pg *mocks.OrderPostgresRepositoryInterface, r *mocks.OrderRedisRepoInterface) {},
+			input:           &models.FullOrder{Order: models.Order{OrderUID: "bad1"}},
+			expectErr:       true,
+			expectRedisCall: false,
+		},
 		{
 			name: "BeginTx error",
 			setupMocks: func(pg *mocks.OrderPostgresRepositoryInterface, r *mocks.OrderRedisRepoInterface) {
 				pg.On("BeginTx", mock.Anything).Return(nil, errors.New("begin fail"))
 			},
-			input:           &models.FullOrder{Order: models.Order{OrderUID: "ok2"}},
+			input:           validFullOrder("ok2"),
 			expectErr:       true,
 			expectRedisCall: false,
 		},
@@ -58,21 +108,54 @@ func TestOrderService_ProcessAndCache(t *testing.T) {
 			setupMocks: func(pg *mocks.OrderPostgresRepositoryInterface, r *mocks.OrderRedisRepoInterface) {
 				tx := &mocks.PgxTx{}
 				pg.On("BeginTx", mock.Anything).Return(tx, nil)
-				pg.On("SaveOrderDataTx", mock.Anything, tx, mock.Anything).Return(nil)
+				pg.On("SaveOrderDataTx", mock.Anything, tx, mock.Anything, mock.Anything).Return(models.OutcomeInserted, nil)
 				pg.On("SaveDeliveryDataTx", mock.Anything, tx, mock.Anything).Return(nil)
 				pg.On("SavePaymentDataTx", mock.Anything, tx, mock.Anything).Return(nil)
 				pg.On("SaveItemsDataTx", mock.Anything, tx, mock.Anything).Return(errors.New("item fail"))
 				tx.On("Rollback", mock.Anything).Return(nil)
 			},
-			input:           &models.FullOrder{Order: models.Order{OrderUID: "ok3"}, Items: []models.Item{{}}},
+			input:           validFullOrder("ok3"),
 			expectErr:       true,
 			expectRedisCall: false,
 		},
+		{
+			name: "redelivered identical order is a no-op",
+			setupMocks: func(pg *mocks.OrderPostgresRepositoryInterface, r *mocks.OrderRedisRepoInterface) {
+				tx := &mocks.PgxTx{}
+				pg.On("BeginTx", mock.Anything).Return(tx, nil)
+				pg.On("SaveOrderDataTx", mock.Anything, tx, mock.Anything, mock.Anything).Return(models.OutcomeUnchanged, nil)
+				tx.On("Rollback", mock.Anything).Return(nil)
+			},
+			input:           validFullOrder("ok4"),
+			expectErr:       false,
+			expectRedisCall: false,
+		},
+		{
+			name: "changed payload replaces items",
+			setupMocks: func(pg *mocks.OrderPostgresRepositoryInterface, r *mocks.OrderRedisRepoInterface) {
+				tx := &mocks.PgxTx{}
+				pg.On("BeginTx", mock.Anything).Return(tx, nil)
+				pg.On("SaveOrderDataTx", mock.Anything, tx, mock.Anything, mock.Anything).Return(models.OutcomeUpdated, nil)
+				pg.On("DeleteItemsTx", mock.Anything, tx, "ok5").Return(nil)
+				pg.On("SaveDeliveryDataTx", mock.Anything, tx, mock.Anything).Return(nil)
+				pg.On("SavePaymentDataTx", mock.Anything, tx, mock.Anything).Return(nil)
+				pg.On("SaveItemsDataTx", mock.Anything, tx, mock.Anything).Return(nil)
+				pg.On("SaveOrderVersionsTx", mock.Anything, tx, mock.Anything).Return(nil)
+				tx.On("Commit", mock.Anything).Return(nil)
+				tx.On("Rollback", mock.Anything).Return(nil)
+
+				r.On("SetOrder", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+			input:           validFullOrder("ok5"),
+			expectErr:       false,
+			expectRedisCall: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pgMock := &mocks.OrderPostgresRepositoryInterface{}
+			expectNotErased(pgMock)
 			rMock := &mocks.OrderRedisRepoInterface{}
 			tt.setupMocks(pgMock, rMock)
 
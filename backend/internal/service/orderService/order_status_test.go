@@ -0,0 +1,100 @@
+package orderService_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+func TestOrderService_ChangeOrderStatus(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		current    models.OrderStatus
+		currentErr error
+		to         models.OrderStatus
+		expectSave bool
+		expectErr  error
+	}{
+		{name: "allowed transition", current: models.StatusCreated, to: models.StatusPaid, expectSave: true},
+		{name: "same status is a no-op", current: models.StatusPaid, to: models.StatusPaid},
+		{name: "illegal transition", current: models.StatusCreated, to: models.StatusDelivered, expectErr: models.ErrInvalidTransition},
+		{name: "final status", current: models.StatusCancelled, to: models.StatusPaid, expectErr: models.ErrInvalidTransition},
+		{name: "order not found", currentErr: models.ErrOrderNotFound, to: models.StatusPaid, expectErr: models.ErrOrderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pg := &mocks.OrderPostgresRepositoryInterface{}
+			tx := &mocks.PgxTx{}
+			pg.On("BeginTx", ctx).Return(tx, nil)
+			pg.On("GetOrderStatusForUpdateTx", ctx, tx, "o1").Return(tt.current, tt.currentErr)
+			tx.On("Rollback", ctx).Return(nil)
+			if tt.expectSave {
+				pg.On("SaveStatusChangeTx", ctx, tx, mock.MatchedBy(func(c *models.StatusChange) bool {
+					return c.From == tt.current && c.To == tt.to && c.Source == "http" && c.Reason == "manual"
+				})).Return(nil)
+				pg.On("SaveOrderVersionsTx", ctx, tx, []models.OrderVersion{
+					{OrderUID: "o1", Operation: models.VersionStatusChanged, Source: "http"},
+				}).Return(nil)
+				tx.On("Commit", ctx).Return(nil)
+			}
+
+			service := svc.NewOrderService(pg, &mocks.OrderRedisRepoInterface{}, slog.Default(), time.Hour)
+			change, err := service.ChangeOrderStatus(ctx, "o1", tt.to, "manual", "http")
+
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				assert.Nil(t, change)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.to, change.To)
+			}
+			if !tt.expectSave {
+				pg.AssertNotCalled(t, "SaveStatusChangeTx", mock.Anything, mock.Anything, mock.Anything)
+				pg.AssertNotCalled(t, "SaveOrderVersionsTx", mock.Anything, mock.Anything, mock.Anything)
+				tx.AssertNotCalled(t, "Commit", mock.Anything)
+			}
+			pg.AssertExpectations(t)
+		})
+	}
+
+	t.Run("save error is returned", func(t *testing.T) {
+		pg := &mocks.OrderPostgresRepositoryInterface{}
+		tx := &mocks.PgxTx{}
+		pg.On("BeginTx", ctx).Return(tx, nil)
+		pg.On("GetOrderStatusForUpdateTx", ctx, tx, "o1").Return(models.StatusPaid, nil)
+		pg.On("SaveStatusChangeTx", ctx, tx, mock.Anything).Return(errors.New("db error"))
+		tx.On("Rollback", ctx).Return(nil)
+
+		service := svc.NewOrderService(pg, &mocks.OrderRedisRepoInterface{}, slog.Default(), time.Hour)
+		_, err := service.ChangeOrderStatus(ctx, "o1", models.StatusShipped, "", "http")
+		assert.Error(t, err)
+		tx.AssertNotCalled(t, "Commit", mock.Anything)
+	})
+
+	t.Run("version error is returned", func(t *testing.T) {
+		pg := &mocks.OrderPostgresRepositoryInterface{}
+		tx := &mocks.PgxTx{}
+		pg.On("BeginTx", ctx).Return(tx, nil)
+		pg.On("GetOrderStatusForUpdateTx", ctx, tx, "o1").Return(models.StatusPaid, nil)
+		pg.On("SaveStatusChangeTx", ctx, tx, mock.Anything).Return(nil)
+		pg.On("SaveOrderVersionsTx", ctx, tx, mock.Anything).Return(errors.New("db error"))
+		tx.On("Rollback", ctx).Return(nil)
+
+		service := svc.NewOrderService(pg, &mocks.OrderRedisRepoInterface{}, slog.Default(), time.Hour)
+		_, err := service.ChangeOrderStatus(ctx, "o1", models.StatusShipped, "", "http")
+		assert.Error(t, err)
+		tx.AssertNotCalled(t, "Commit", mock.Anything)
+	})
+}
@@ -0,0 +1,103 @@
+package orderService_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	"wbL0/internal/audit"
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+func TestOrderService_ProcessAndCache_RecordsVersionSource(t *testing.T) {
+	ctx := audit.WithSource(context.Background(), audit.KafkaSource("orders", 0, 42))
+	pg := &mocks.OrderPostgresRepositoryInterface{}
+	expectNotErased(pg)
+	r := &mocks.OrderRedisRepoInterface{}
+	tx := &mocks.PgxTx{}
+	pg.On("BeginTx", ctx).Return(tx, nil)
+	pg.On("SaveOrderDataTx", ctx, tx, mock.Anything, mock.Anything).Return(models.OutcomeUpdated, nil)
+	pg.On("DeleteItemsTx", ctx, tx, "v1").Return(nil)
+	pg.On("SaveDeliveryDataTx", ctx, tx, mock.Anything).Return(nil)
+	pg.On("SavePaymentDataTx", ctx, tx, mock.Anything).Return(nil)
+	pg.On("SaveItemsDataTx", ctx, tx, mock.Anything).Return(nil)
+	pg.On("SaveOrderVersionsTx", ctx, tx, mock.MatchedBy(func(v []models.OrderVersion) bool {
+		var snapshot models.FullOrder
+		return len(v) == 1 && v[0].Operation == models.VersionUpdated && v[0].Source == "kafka:orders/0@42" &&
+			json.Unmarshal(v[0].Current, &snapshot) == nil && snapshot.Order.OrderUID == "v1"
+	})).Return(nil).Once()
+	tx.On("Commit", ctx).Return(nil)
+	tx.On("Rollback", ctx).Return(nil)
+	r.On("SetOrder", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	service := svc.NewOrderService(pg, r, slog.Default(), time.Hour)
+	assert.NoError(t, service.ProcessAndCache(ctx, validFullOrder("v1")))
+	pg.AssertExpectations(t)
+}
+
+func TestOrderService_DiffOrderVersions(t *testing.T) {
+	ctx := context.Background()
+	pg := &mocks.OrderPostgresRepositoryInterface{}
+	pg.On("GetOrderVersion", ctx, "v1", 1).Return(&models.OrderVersion{Version: 1, Current: json.RawMessage(`{"delivery":{"phone":"+1"}}`)}, nil)
+	pg.On("GetOrderVersion", ctx, "v1", 2).Return(&models.OrderVersion{Version: 2, Current: json.RawMessage(`{"delivery":{"phone":"+2"}}`)}, nil)
+	pg.On("GetOrderVersion", ctx, "v1", 3).Return(nil, models.ErrVersionNotFound)
+
+	service := svc.NewOrderService(pg, &mocks.OrderRedisRepoInterface{}, slog.Default(), time.Hour)
+
+	diff, err := service.DiffOrderVersions(ctx, "v1", 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.FieldChange{{Path: "delivery.phone", Old: "+1", New: "+2"}}, diff.Changes)
+
+	_, err = service.DiffOrderVersions(ctx, "v1", 1, 3)
+	assert.ErrorIs(t, err, models.ErrVersionNotFound)
+
+	t.Run("status change", func(t *testing.T) {
+		pg := &mocks.OrderPostgresRepositoryInterface{}
+		snapshot := json.RawMessage(`{"delivery":{"phone":"+1"}}`)
+		pg.On("GetOrderVersion", ctx, "v2", 1).Return(&models.OrderVersion{Version: 1, Current: snapshot, Status: models.StatusCreated}, nil)
+		pg.On("GetOrderVersion", ctx, "v2", 2).Return(&models.OrderVersion{Version: 2, Operation: models.VersionStatusChanged, Previous: snapshot, Current: snapshot, Status: models.StatusPaid}, nil)
+		pg.On("GetOrderVersion", ctx, "v2", 3).Return(&models.OrderVersion{Version: 3, Operation: models.VersionDeleted, Previous: snapshot}, nil)
+
+		service := svc.NewOrderService(pg, &mocks.OrderRedisRepoInterface{}, slog.Default(), time.Hour)
+
+		diff, err := service.DiffOrderVersions(ctx, "v2", 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.FieldChange{{Path: "status", Old: "created", New: "paid"}}, diff.Changes)
+
+		diff, err = service.DiffOrderVersions(ctx, "v2", 2, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, models.FieldChange{Path: "status", Old: "paid", New: nil}, diff.Changes[0])
+	})
+}
+
+func TestOrderService_ListOrderVersions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unknown order", func(t *testing.T) {
+		pg := &mocks.OrderPostgresRepositoryInterface{}
+		pg.On("ListOrderVersions", ctx, "missing").Return([]models.OrderVersion{}, nil)
+		pg.On("GetOrderInfoByUid", ctx, "missing").Return(nil, models.ErrOrderNotFound)
+
+		service := svc.NewOrderService(pg, &mocks.OrderRedisRepoInterface{}, slog.Default(), time.Hour)
+		_, err := service.ListOrderVersions(ctx, "missing")
+		assert.ErrorIs(t, err, models.ErrOrderNotFound)
+	})
+
+	t.Run("order stored before the audit trail", func(t *testing.T) {
+		pg := &mocks.OrderPostgresRepositoryInterface{}
+		pg.On("ListOrderVersions", ctx, "old").Return([]models.OrderVersion{}, nil)
+		pg.On("GetOrderInfoByUid", ctx, "old").Return(&models.Order{OrderUID: "old"}, nil)
+
+		service := svc.NewOrderService(pg, &mocks.OrderRedisRepoInterface{}, slog.Default(), time.Hour)
+		versions, err := service.ListOrderVersions(ctx, "old")
+		assert.NoError(t, err)
+		assert.Empty(t, versions)
+	})
+}
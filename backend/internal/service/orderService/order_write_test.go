@@ -0,0 +1,134 @@
+package orderService_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+func TestOrderService_CreateUpdateOrder(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		outcome   models.SaveOutcome
+		call      func(s *svc.OrderService, ctx context.Context, fo *models.FullOrder) error
+		wantErr   error
+		wantSaved bool
+	}{
+		{
+			name:      "create new order",
+			outcome:   models.OutcomeInserted,
+			call:      (*svc.OrderService).CreateOrder,
+			wantSaved: true,
+		},
+		{
+			name:    "create existing order is rejected",
+			outcome: models.OutcomeUpdated,
+			call:    (*svc.OrderService).CreateOrder,
+			wantErr: models.ErrOrderExists,
+		},
+		{
+			name:      "update existing order",
+			outcome:   models.OutcomeUpdated,
+			call:      (*svc.OrderService).UpdateOrder,
+			wantSaved: true,
+		},
+		{
+			name:    "update missing order is rejected",
+			outcome: models.OutcomeInserted,
+			call:    (*svc.OrderService).UpdateOrder,
+			wantErr: models.ErrOrderNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pgMock := &mocks.OrderPostgresRepositoryInterface{}
+			expectNotErased(pgMock)
+			rMock := &mocks.OrderRedisRepoInterface{}
+			tx := &mocks.PgxTx{}
+			pgMock.On("BeginTx", mock.Anything).Return(tx, nil)
+			pgMock.On("SaveOrderDataTx", mock.Anything, tx, mock.Anything, mock.Anything).Return(tt.outcome, nil)
+			tx.On("Rollback", mock.Anything).Return(nil)
+			if tt.wantSaved {
+				if tt.outcome == models.OutcomeUpdated {
+					pgMock.On("DeleteItemsTx", mock.Anything, tx, mock.Anything).Return(nil)
+				}
+				pgMock.On("SaveDeliveryDataTx", mock.Anything, tx, mock.Anything).Return(nil)
+				pgMock.On("SavePaymentDataTx", mock.Anything, tx, mock.Anything).Return(nil)
+				pgMock.On("SaveItemsDataTx", mock.Anything, tx, mock.Anything).Return(nil)
+				pgMock.On("SaveOrderVersionsTx", mock.Anything, tx, mock.MatchedBy(func(v []models.OrderVersion) bool {
+					return len(v) == 1 && v[0].Operation == string(tt.outcome) && len(v[0].Current) > 0
+				})).Return(nil)
+				tx.On("Commit", mock.Anything).Return(nil)
+				rMock.On("SetOrder", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			}
+
+			service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+			err := tt.call(service, ctx, validFullOrder("w1"))
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				tx.AssertNotCalled(t, "Commit", mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+			pgMock.AssertExpectations(t)
+			rMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrderService_DeleteOrder(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		deleteErr error
+		wantErr   bool
+		evicted   bool
+	}{
+		{name: "deleted and evicted", evicted: true},
+		{name: "not found", deleteErr: models.ErrOrderNotFound, wantErr: true},
+		{name: "db error", deleteErr: errors.New("db down"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pgMock := &mocks.OrderPostgresRepositoryInterface{}
+			rMock := &mocks.OrderRedisRepoInterface{}
+			tx := &mocks.PgxTx{}
+			pgMock.On("BeginTx", mock.Anything).Return(tx, nil)
+			pgMock.On("DeleteOrderTx", mock.Anything, tx, "d1").Return(tt.deleteErr)
+			tx.On("Rollback", mock.Anything).Return(nil)
+			if tt.evicted {
+				pgMock.On("SaveOrderVersionsTx", mock.Anything, tx, mock.MatchedBy(func(v []models.OrderVersion) bool {
+					return len(v) == 1 && v[0].Operation == models.VersionDeleted && v[0].Current == nil
+				})).Return(nil)
+				tx.On("Commit", mock.Anything).Return(nil)
+				rMock.On("DeleteOrder", mock.Anything, "d1").Return(nil)
+			}
+
+			service := svc.NewOrderService(pgMock, rMock, slog.Default(), time.Hour)
+			err := service.DeleteOrder(ctx, "d1")
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, tt.deleteErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			pgMock.AssertExpectations(t)
+			rMock.AssertExpectations(t)
+		})
+	}
+}
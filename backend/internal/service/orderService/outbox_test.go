@@ -0,0 +1,77 @@
+package orderService_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+
+	mocks "wbL0/internal/mocks"
+	"wbL0/internal/models"
+	svc "wbL0/internal/service/orderService"
+)
+
+func TestOrderService_ProcessAndCache_Outbox(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(outcome models.SaveOutcome) (*mocks.OrderPostgresRepositoryInterface, *mocks.OrderRedisRepoInterface, *mocks.PgxTx) {
+		pg := &mocks.OrderPostgresRepositoryInterface{}
+		expectNotErased(pg)
+		r := &mocks.OrderRedisRepoInterface{}
+		tx := &mocks.PgxTx{}
+		pg.On("BeginTx", ctx).Return(tx, nil)
+		pg.On("SaveOrderDataTx", ctx, tx, mock.Anything, mock.Anything).Return(outcome, nil)
+		pg.On("SaveDeliveryDataTx", ctx, tx, mock.Anything).Return(nil)
+		pg.On("SavePaymentDataTx", ctx, tx, mock.Anything).Return(nil)
+		pg.On("SaveItemsDataTx", ctx, tx, mock.Anything).Return(nil)
+		pg.On("SaveOrderVersionsTx", ctx, tx, mock.Anything).Return(nil)
+		tx.On("Rollback", ctx).Return(nil)
+		r.On("SetOrder", ctx, mock.Anything, mock.Anything).Return(nil)
+		return pg, r, tx
+	}
+
+	t.Run("event is written before commit", func(t *testing.T) {
+		pg, r, tx := setup(models.OutcomeInserted)
+		var committed bool
+		pg.On("SaveOutboxEventsTx", ctx, tx, mock.MatchedBy(func(events []models.OutboxEvent) bool {
+			if committed || len(events) != 1 || events[0].EventType != models.EventOrderStored {
+				return false
+			}
+			var payload models.OrderStoredEvent
+			return json.Unmarshal(events[0].Payload, &payload) == nil &&
+				payload.OrderUID == "o1" && payload.Outcome == models.OutcomeInserted
+		})).Return(nil).Once()
+		tx.On("Commit", ctx).Run(func(mock.Arguments) { committed = true }).Return(nil)
+
+		service := svc.NewOrderService(pg, r, slog.Default(), time.Hour).WithOutbox()
+		assert.NoError(t, service.ProcessAndCache(ctx, validFullOrder("o1")))
+		pg.AssertExpectations(t)
+	})
+
+	t.Run("outbox error rolls the order back", func(t *testing.T) {
+		pg, r, tx := setup(models.OutcomeInserted)
+		pg.On("SaveOutboxEventsTx", ctx, tx, mock.Anything).Return(errors.New("db error"))
+
+		service := svc.NewOrderService(pg, r, slog.Default(), time.Hour).WithOutbox()
+		assert.Error(t, service.ProcessAndCache(ctx, validFullOrder("o1")))
+		tx.AssertNotCalled(t, "Commit", mock.Anything)
+	})
+
+	t.Run("unchanged order produces no event", func(t *testing.T) {
+		pg := &mocks.OrderPostgresRepositoryInterface{}
+		r := &mocks.OrderRedisRepoInterface{}
+		tx := &mocks.PgxTx{}
+		pg.On("BeginTx", ctx).Return(tx, nil)
+		pg.On("SaveOrderDataTx", ctx, tx, mock.Anything, mock.Anything).Return(models.OutcomeUnchanged, nil)
+		tx.On("Rollback", ctx).Return(nil)
+
+		service := svc.NewOrderService(pg, r, slog.Default(), time.Hour).WithOutbox()
+		assert.NoError(t, service.ProcessAndCache(ctx, validFullOrder("o1")))
+		pg.AssertNotCalled(t, "SaveOutboxEventsTx", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
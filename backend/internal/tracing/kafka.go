@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier exposes Kafka message headers to the propagator. Set replaces a header with the same key,
+// so a message forwarded to another topic carries only the latest context.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for i, h := range *c.headers {
+		if h.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, len(*c.headers))
+	for i, h := range *c.headers {
+		keys[i] = h.Key
+	}
+	return keys
+}
+
+// Inject writes the trace context of ctx into the message headers.
+func Inject(ctx context.Context, msg *kafka.Message) {
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{headers: &msg.Headers})
+}
+
+// Extract returns ctx with the trace context found in the message headers.
+func Extract(ctx context.Context, msg kafka.Message) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier{headers: &msg.Headers})
+}
+
+// StartConsume starts a consumer span for the message, continuing the trace of the producer.
+func StartConsume(ctx context.Context, name string, msg kafka.Message) (context.Context, trace.Span) {
+	return Start(Extract(ctx, msg), name,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(MessageAttributes(msg)...),
+	)
+}
+
+// StartConsumeBatch starts a consumer span for messages stored together. A batch has no single parent,
+// so the span links to the trace of every message instead.
+func StartConsumeBatch(ctx context.Context, name string, msgs []kafka.Message) (context.Context, trace.Span) {
+	links := make([]trace.Link, 0, len(msgs))
+	for _, msg := range msgs {
+		if link := trace.LinkFromContext(Extract(ctx, msg)); link.SpanContext.IsValid() {
+			links = append(links, link)
+		}
+	}
+	attrs := []attribute.KeyValue{attribute.String("messaging.system", "kafka"), attribute.Int("messaging.batch.message_count", len(msgs))}
+	if len(msgs) > 0 {
+		attrs = append(attrs, attribute.String("messaging.destination.name", msgs[0].Topic))
+	}
+	return Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(links...),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// MessageAttributes describes the message with the OpenTelemetry messaging conventions.
+func MessageAttributes(msg kafka.Message) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination.name", msg.Topic),
+		attribute.Int("messaging.destination.partition.id", msg.Partition),
+		attribute.Int64("messaging.kafka.message.offset", msg.Offset),
+	}
+}
@@ -0,0 +1,146 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"wbL0/internal/config"
+	"wbL0/internal/models"
+	"wbL0/internal/tracing"
+)
+
+func recorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	t.Cleanup(tracing.UseProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))))
+	return rec
+}
+
+func TestKafkaPropagation(t *testing.T) {
+	rec := recorder(t)
+
+	ctx, producer := tracing.Start(context.Background(), "producer.Send")
+	msg := kafka.Message{Topic: "orders", Partition: 2, Offset: 42, Headers: []kafka.Header{{Key: "x-source", Value: []byte("test")}}}
+	tracing.Inject(ctx, &msg)
+	tracing.Inject(ctx, &msg)
+	producer.End()
+	require.Len(t, msg.Headers, 2, "injecting twice replaces the header")
+
+	_, consumer := tracing.StartConsume(context.Background(), "kafka.consumeMessage", msg)
+	consumer.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	got := spans[1]
+	assert.Equal(t, producer.SpanContext().TraceID(), got.SpanContext().TraceID())
+	assert.Equal(t, producer.SpanContext().SpanID(), got.Parent().SpanID())
+	assert.Equal(t, trace.SpanKindConsumer, got.SpanKind())
+	assert.Contains(t, got.Attributes(), tracing.MessageAttributes(msg)[3])
+}
+
+func TestStartConsumeBatch(t *testing.T) {
+	rec := recorder(t)
+
+	var msgs []kafka.Message
+	for range 2 {
+		ctx, span := tracing.Start(context.Background(), "producer.Send")
+		msg := kafka.Message{Topic: "orders"}
+		tracing.Inject(ctx, &msg)
+		span.End()
+		msgs = append(msgs, msg)
+	}
+	msgs = append(msgs, kafka.Message{Topic: "orders"})
+
+	_, span := tracing.StartConsumeBatch(context.Background(), "kafka.consumeBatches", msgs)
+	span.End()
+
+	batch := rec.Ended()[2]
+	assert.False(t, batch.Parent().IsValid(), "a batch starts its own trace")
+	assert.Len(t, batch.Links(), 2, "messages without trace context are not linked")
+}
+
+func TestEnd(t *testing.T) {
+	rec := recorder(t)
+
+	_, span := tracing.Start(context.Background(), "expected")
+	tracing.End(span, models.ErrOrderNotFound, models.ErrOrderNotFound)
+	_, span = tracing.Start(context.Background(), "failed")
+	tracing.End(span, errors.New("boom"), models.ErrOrderNotFound)
+
+	spans := rec.Ended()
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Len(t, spans[1].Events(), 1)
+}
+
+func TestStartDisabled(t *testing.T) {
+	ctx := context.WithValue(context.Background(), struct{}{}, "v")
+	got, span := tracing.Start(ctx, "op")
+	assert.Equal(t, ctx, got)
+	assert.False(t, span.SpanContext().IsValid())
+	assert.False(t, span.IsRecording())
+}
+
+func TestLogger(t *testing.T) {
+	recorder(t)
+	var buf bytes.Buffer
+	log := tracing.Logger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	ctx, span := tracing.Start(context.Background(), "op")
+	log.InfoContext(ctx, "with span")
+	span.End()
+	log.Info("without span")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var withSpan, withoutSpan map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &withSpan))
+	require.NoError(t, json.Unmarshal(lines[1], &withoutSpan))
+	assert.Equal(t, span.SpanContext().TraceID().String(), withSpan["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), withSpan["span_id"])
+	assert.NotContains(t, withoutSpan, "trace_id")
+}
+
+func TestInit(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		shutdown, err := tracing.Init(context.Background(), config.TracingConfig{})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, err := tracing.Init(context.Background(), config.TracingConfig{Enabled: true, Exporter: "jaeger"})
+		assert.Error(t, err)
+	})
+
+	t.Run("file exporter", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traces.jsonl")
+		shutdown, err := tracing.Init(context.Background(), config.TracingConfig{
+			Enabled: true, Exporter: tracing.ExporterFile, File: path, ServiceName: "test", SampleRatio: 1,
+		})
+		require.NoError(t, err)
+
+		_, span := tracing.Start(context.Background(), "OrderService.GetOrder")
+		span.End()
+		require.NoError(t, shutdown(context.Background()))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "OrderService.GetOrder")
+		assert.Contains(t, string(data), span.SpanContext().TraceID().String())
+	})
+}
@@ -0,0 +1,41 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"wbL0/internal/models"
+)
+
+// FieldError describes a single invalid field. Field is a path like "items[0].track_number".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error holds all field errors found in a payload. It unwraps to models.ErrInvalidInput.
+type Error struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, fmt.Sprintf("%s: %s", f.Field, f.Message))
+	}
+	return fmt.Sprintf("%s: %s", models.ErrInvalidInput.Error(), strings.Join(parts, "; "))
+}
+
+func (e *Error) Unwrap() error {
+	return models.ErrInvalidInput
+}
+
+func (e *Error) add(field, format string, args ...any) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *Error) orNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,143 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"wbL0/internal/models"
+)
+
+// moneyEpsilon is the allowed difference when comparing money totals stored as floats.
+const moneyEpsilon = 0.01
+
+var (
+	phoneRe    = regexp.MustCompile(`^\+?[0-9]{10,15}$`)
+	currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+	localeRe   = regexp.MustCompile(`^[a-z]{2}$`)
+)
+
+// ValidateFullOrder checks required fields, formats and cross-field consistency of the order.
+// It returns *Error with every problem found or nil if the order is valid.
+func ValidateFullOrder(fo *models.FullOrder) error {
+	verr := &Error{}
+
+	validateOrder(verr, &fo.Order)
+	validateDelivery(verr, &fo.Delivery, fo.Order.OrderUID)
+	validatePayment(verr, &fo.Payment, fo.Order.OrderUID)
+	validateItems(verr, fo.Items, &fo.Order)
+	validateTotals(verr, fo)
+
+	return verr.orNil()
+}
+
+func validateOrder(verr *Error, o *models.Order) {
+	required(verr, "order.order_uid", o.OrderUID)
+	required(verr, "order.track_number", o.TrackNumber)
+	required(verr, "order.entry", o.Entry)
+	required(verr, "order.customer_id", o.CustomerID)
+	required(verr, "order.delivery_service", o.DeliveryService)
+	if o.Locale != "" && !localeRe.MatchString(o.Locale) {
+		verr.add("order.locale", "must be a two-letter language code")
+	}
+	if o.DateCreated.IsZero() {
+		verr.add("order.date_created", "is required")
+	}
+}
+
+func validateDelivery(verr *Error, d *models.Delivery, orderUID string) {
+	sameOrder(verr, "delivery.order_uid", d.OrderUID, orderUID)
+	required(verr, "delivery.name", d.Name)
+	required(verr, "delivery.city", d.City)
+	required(verr, "delivery.address", d.Address)
+	if d.Zip <= 0 {
+		verr.add("delivery.zip", "must be positive")
+	}
+	if !phoneRe.MatchString(d.Phone) {
+		verr.add("delivery.phone", "must contain 10 to 15 digits with an optional leading +")
+	}
+	if addr, err := mail.ParseAddress(d.Email); err != nil || addr.Address != d.Email {
+		verr.add("delivery.email", "must be a valid email address")
+	}
+}
+
+func validatePayment(verr *Error, p *models.Payment, orderUID string) {
+	sameOrder(verr, "payment.order_uid", p.OrderUID, orderUID)
+	required(verr, "payment.transaction", p.Transaction)
+	required(verr, "payment.provider", p.Provider)
+	required(verr, "payment.bank", p.Bank)
+	if !currencyRe.MatchString(p.Currency) {
+		verr.add("payment.currency", "must be an ISO 4217 code")
+	}
+	if p.Amount <= 0 {
+		verr.add("payment.amount", "must be positive")
+	}
+	if p.PaymentDt <= 0 {
+		verr.add("payment.payment_dt", "must be a unix timestamp")
+	}
+	nonNegative(verr, "payment.delivery_cost", p.DeliveryCost)
+	nonNegative(verr, "payment.goods_total", p.GoodsTotal)
+	nonNegative(verr, "payment.custom_fee", p.CustomFee)
+}
+
+func validateItems(verr *Error, items []models.Item, o *models.Order) {
+	if len(items) == 0 {
+		verr.add("items", "at least one item is required")
+		return
+	}
+	for i := range items {
+		it := &items[i]
+		prefix := fmt.Sprintf("items[%d]", i)
+
+		sameOrder(verr, prefix+".order_uid", it.OrderUID, o.OrderUID)
+		required(verr, prefix+".rid", it.Rid)
+		required(verr, prefix+".name", it.Name)
+		if it.TrackNumber != o.TrackNumber {
+			verr.add(prefix+".track_number", "must match order.track_number")
+		}
+		if it.ChrtID <= 0 {
+			verr.add(prefix+".chrt_id", "must be positive")
+		}
+		if it.NmID <= 0 {
+			verr.add(prefix+".nm_id", "must be positive")
+		}
+		if it.Sale < 0 || it.Sale > 100 {
+			verr.add(prefix+".sale", "must be between 0 and 100")
+		}
+		nonNegative(verr, prefix+".price", it.Price)
+		nonNegative(verr, prefix+".total_price", it.TotalPrice)
+	}
+}
+
+func validateTotals(verr *Error, fo *models.FullOrder) {
+	var itemsTotal float64
+	for _, it := range fo.Items {
+		itemsTotal += it.TotalPrice
+	}
+	if math.Abs(fo.Payment.GoodsTotal-itemsTotal) > moneyEpsilon {
+		verr.add("payment.goods_total", "must equal the sum of items total_price (%.2f)", itemsTotal)
+	}
+
+	expected := fo.Payment.GoodsTotal + fo.Payment.DeliveryCost + fo.Payment.CustomFee
+	if math.Abs(float64(fo.Payment.Amount)-expected) > moneyEpsilon {
+		verr.add("payment.amount", "must equal goods_total + delivery_cost + custom_fee (%.2f)", expected)
+	}
+}
+
+func required(verr *Error, field, value string) {
+	if value == "" {
+		verr.add(field, "is required")
+	}
+}
+
+func nonNegative(verr *Error, field string, value float64) {
+	if value < 0 {
+		verr.add(field, "must not be negative")
+	}
+}
+
+func sameOrder(verr *Error, field, value, orderUID string) {
+	if value != orderUID {
+		verr.add(field, "must match order.order_uid")
+	}
+}
@@ -0,0 +1,146 @@
+package validation_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wbL0/internal/models"
+	"wbL0/internal/validation"
+)
+
+func validOrder() *models.FullOrder {
+	return &models.FullOrder{
+		Order: models.Order{
+			OrderUID:        "b563feb7b2b84b6test",
+			TrackNumber:     "WBILMTESTTRACK",
+			Entry:           "WBIL",
+			Locale:          "en",
+			CustomerID:      "test",
+			DeliveryService: "meest",
+			DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		},
+		Delivery: models.Delivery{
+			OrderUID: "b563feb7b2b84b6test",
+			Name:     "Test Testov",
+			Phone:    "+9720000000",
+			Zip:      2639809,
+			City:     "Kiryat Mozkin",
+			Address:  "Ploshad Mira 15",
+			Region:   "Kraiot",
+			Email:    "test@gmail.com",
+		},
+		Payment: models.Payment{
+			OrderUID:     "b563feb7b2b84b6test",
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []models.Item{{
+			OrderUID:    "b563feb7b2b84b6test",
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			Rid:         "ab4219087a764ae0btest",
+			Name:        "Mascaras",
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  317,
+			NmID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		}},
+	}
+}
+
+func TestValidateFullOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		mutate     func(fo *models.FullOrder)
+		wantFields []string
+	}{
+		{
+			name:   "valid order",
+			mutate: func(fo *models.FullOrder) {},
+		},
+		{
+			name: "missing required fields",
+			mutate: func(fo *models.FullOrder) {
+				fo.Order.CustomerID = ""
+				fo.Delivery.Name = ""
+			},
+			wantFields: []string{"order.customer_id", "delivery.name"},
+		},
+		{
+			name: "bad formats",
+			mutate: func(fo *models.FullOrder) {
+				fo.Delivery.Email = "not-an-email"
+				fo.Delivery.Phone = "12-34"
+				fo.Payment.Currency = "usd"
+			},
+			wantFields: []string{"delivery.phone", "delivery.email", "payment.currency"},
+		},
+		{
+			name: "goods total does not match items",
+			mutate: func(fo *models.FullOrder) {
+				fo.Items[0].TotalPrice = 300
+			},
+			wantFields: []string{"payment.goods_total"},
+		},
+		{
+			name: "amount does not match totals",
+			mutate: func(fo *models.FullOrder) {
+				fo.Payment.Amount = 1000
+			},
+			wantFields: []string{"payment.amount"},
+		},
+		{
+			name: "item track number differs from order",
+			mutate: func(fo *models.FullOrder) {
+				fo.Items[0].TrackNumber = "OTHER"
+			},
+			wantFields: []string{"items[0].track_number"},
+		},
+		{
+			name: "negative price and no items",
+			mutate: func(fo *models.FullOrder) {
+				fo.Items = nil
+				fo.Payment.GoodsTotal = 0
+				fo.Payment.DeliveryCost = -1
+			},
+			wantFields: []string{"payment.delivery_cost", "items", "payment.amount"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fo := validOrder()
+			tt.mutate(fo)
+
+			err := validation.ValidateFullOrder(fo)
+			if len(tt.wantFields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, models.ErrInvalidInput))
+
+			var verr *validation.Error
+			require.True(t, errors.As(err, &verr))
+			var got []string
+			for _, f := range verr.Fields {
+				got = append(got, f.Field)
+			}
+			assert.ElementsMatch(t, tt.wantFields, got)
+		})
+	}
+}
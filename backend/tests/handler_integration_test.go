@@ -118,8 +118,8 @@ func startPgAndRedis(t *testing.T, pool *dockertest.Pool) (cfg *config.Config, p
 		t.Fatalf("failed to chdir to project root: %v", err)
 	}
 
-	pgPool = postgres.MustLoad(context.Background(), cfg)
-	rdb = redisPkg.NewRedisClient(context.Background(), cfg)
+	pgPool = postgres.MustLoad(context.Background(), cfg, slog.Default())
+	rdb = redisPkg.NewRedisClient(context.Background(), cfg, slog.Default())
 
 	return cfg, pgPool, rdb, cleanup
 }
@@ -142,17 +142,18 @@ func TestHandler_GetOrderInfo(t *testing.T) {
 
 	fullOrder := &models.FullOrder{
 		Order: models.Order{
-			OrderUID:    "handler-uid-1",
-			TrackNumber: "track-1",
-			Entry:       "entry",
-			Locale:      "ru",
-			CustomerID:  "cust",
-			DateCreated: time.Now(),
+			OrderUID:        "handler-uid-1",
+			TrackNumber:     "track-1",
+			Entry:           "entry",
+			Locale:          "ru",
+			CustomerID:      "cust",
+			DeliveryService: "meest",
+			DateCreated:     time.Now(),
 		},
 		Delivery: models.Delivery{
 			OrderUID: "handler-uid-1",
 			Name:     "John",
-			Phone:    "+700",
+			Phone:    "+79990000000",
 			Zip:      1,
 			City:     "City",
 			Address:  "Addr",
@@ -166,7 +167,7 @@ func TestHandler_GetOrderInfo(t *testing.T) {
 			Currency:     "RUB",
 			Provider:     "p",
 			Amount:       10,
-			PaymentDt:    time.Now(),
+			PaymentDt:    int(time.Now().Unix()),
 			Bank:         "bank",
 			DeliveryCost: 1,
 			GoodsTotal:   9,
@@ -182,7 +183,7 @@ func TestHandler_GetOrderInfo(t *testing.T) {
 				Name:        "it",
 				Sale:        0,
 				Size:        "L",
-				TotalPrice:  10,
+				TotalPrice:  9,
 				NmID:        1,
 				Brand:       "b",
 				Status:      1,
@@ -198,7 +199,7 @@ func TestHandler_GetOrderInfo(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	engine := gin.New()
 	handler := orderHandler.NewOrderHandler(service, logger)
-	routes.InitRoutes(engine, *handler)
+	routes.InitRoutes(engine, *handler, nil, logger)
 
 	ts := httptest.NewServer(engine)
 	defer ts.Close()
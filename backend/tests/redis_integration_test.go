@@ -3,6 +3,7 @@ package tests
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"testing"
 	"time"
 
@@ -54,7 +55,7 @@ func TestRedis_ConnectionSetGet(t *testing.T) {
 		cfg.Redis.Port = portInt
 	}
 
-	rdb := redisPkg.NewRedisClient(context.Background(), cfg)
+	rdb := redisPkg.NewRedisClient(context.Background(), cfg, slog.Default())
 	defer func() { _ = rdb.Close() }()
 
 	ctx := context.Background()
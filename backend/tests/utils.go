@@ -6,7 +6,7 @@ import (
 	"testing"
 )
 
-func EnsureRepoRoot(t *testing.T) func() {
+func EnsureRepoRoot(t testing.TB) func() {
 	t.Helper()
 
 	origWd, err := os.Getwd()
@@ -17,7 +17,9 @@ export default function OrderInfo() {
 
     setLoading(true);
     try {
-      const res = await fetch(`http://localhost:8081/order/${orderUID}`);
+      const res = await fetch(`http://localhost:8081/order/${orderUID}`, {
+        headers: { "X-API-Key": process.env.REACT_APP_API_KEY || "" },
+      });
 
       console.log("HTTP status:", res.status);
       const text = await res.text();